@@ -0,0 +1,201 @@
+package jsptr
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/lestrrat-go/blackmagic"
+)
+
+// Assigner assigns retrieved values to destinations. The default Assigner
+// uses blackmagic.AssignIfCompatible, which allows the value to be
+// assigned to a destination of a compatible type, such as an int64 to an
+// *int. A different Assigner can be used to make conversions stricter or
+// more lenient, using SetAssigner or WithAssigner.
+//
+// dst is the destination given by the caller, and value is never nil:
+// JSON nulls reset the destination to its zero value without calling the
+// Assigner. Composite destinations such as structs are populated by
+// encoding/json if the Assigner fails to assign the value, or if they
+// are retrieved from JSON documents, in which case the Assigner is not
+// called at all.
+type Assigner interface {
+	Assign(dst, value any) error
+}
+
+// AssignerFunc is a function that implements Assigner
+type AssignerFunc func(dst, value any) error
+
+// Assign calls f(dst, value)
+func (f AssignerFunc) Assign(dst, value any) error {
+	return f(dst, value)
+}
+
+// DefaultAssigner returns the Assigner that is used unless another one is
+// specified using SetAssigner or WithAssigner. It can be used by custom
+// Assigners to delegate the values they do not handle themselves.
+func DefaultAssigner() Assigner {
+	return blackmagicAssigner{}
+}
+
+type blackmagicAssigner struct{}
+
+func (blackmagicAssigner) Assign(dst, value any) error {
+	return blackmagic.AssignIfCompatible(dst, value)
+}
+
+// globalAssigner holds the Assigner set using SetAssigner, if any
+var globalAssigner atomic.Pointer[Assigner]
+
+// SetAssigner sets the Assigner used by the whole package, unless one is
+// specified for a retrieval using WithAssigner. Passing nil restores the
+// default Assigner.
+func SetAssigner(a Assigner) {
+	if a == nil {
+		globalAssigner.Store(nil)
+		return
+	}
+	globalAssigner.Store(&a)
+}
+
+// currentAssigner returns the Assigner to use for retrievals using cfg
+func (cfg *retrieveConfig) currentAssigner() Assigner {
+	if cfg.assigner != nil {
+		return cfg.assigner
+	}
+	if a := globalAssigner.Load(); a != nil {
+		return *a
+	}
+	return blackmagicAssigner{}
+}
+
+// Assign assigns v to dst using the same conversion rules as Retrieve,
+// as if v had been retrieved from a document. This is useful in Source
+// implementations, which must assign the values they find to the
+// destinations given to them. Unlike Retrieve, strings and byte slices
+// are assigned as they are, rather than being treated as JSON documents.
+func Assign(dst, v any, options ...RetrieveOption) error {
+	cfg := newRetrieveConfig(options)
+	return retrieveConverted(dst, cfg, func(dst any) error {
+		return valueSource{data: v}.retrieveTokens(dst, nil, cfg)
+	})
+}
+
+// assign assigns value to dst. If value cannot be assigned as is, and dst
+// is a composite type such as a struct or a typed slice, the value is
+// converted by round-tripping it through encoding/json, so that json tags
+// on the destination are honored.
+//
+// Strings assigned to destinations implementing encoding.TextUnmarshaler,
+// such as *time.Time or *netip.Addr, are parsed by the destination
+func (cfg *retrieveConfig) assign(dst, value any) error {
+	if value == nil || isNilPointer(value) {
+		return assignNull(dst)
+	}
+
+	if s, ok := value.(string); ok {
+		if u, ok := dst.(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(s)); err != nil {
+				return fmt.Errorf("failed to parse %q into %T: %w", s, dst, err)
+			}
+			return nil
+		}
+	}
+
+	err := cfg.currentAssigner().Assign(dst, value)
+	if err == nil || !isDecodeTarget(dst) {
+		return err
+	}
+
+	buf, merr := json.Marshal(value)
+	if merr != nil {
+		return fmt.Errorf("failed to convert %T to %T: %w", value, dst, merr)
+	}
+	return decodeInto(dst, buf)
+}
+
+// assignNull assigns JSON null (or a Go nil, including nil pointers) to
+// dst. Destinations that can hold nil, such as a *any, a **T or a *[]T,
+// are set to nil. Null cannot be represented by other types, so assigning
+// it to a *string or a *int is an error rather than a silent zero value
+func assignNull(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("destination must be a non-nil pointer: %T", dst)
+	}
+	switch elem := rv.Elem(); elem.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		elem.SetZero()
+		return nil
+	default:
+		return fmt.Errorf("cannot assign null to %T", dst)
+	}
+}
+
+// isNilPointer returns true if v is a typed nil pointer
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// decodeInto decodes the JSON encoded buf into dst
+func decodeInto(dst any, buf []byte) error {
+	if err := json.Unmarshal(buf, dst); err != nil {
+		return fmt.Errorf("failed to decode value into %T: %w", dst, err)
+	}
+	return nil
+}
+
+// isDecodeTarget returns true if dst is a pointer to a composite type that
+// cannot be populated by a plain assignment of the generic values produced
+// from JSON (map[string]any, []any), but can be decoded into by encoding/json
+func isDecodeTarget(dst any) bool {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false
+	}
+
+	t := rv.Type().Elem()
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Array:
+		// []any can be assigned directly
+		return t.Elem().Kind() != reflect.Interface
+	case reflect.Map:
+		// map[string]any can be assigned directly
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() != reflect.Interface
+	default:
+		return false
+	}
+}
+
+// isScalarTarget returns true if dst is a pointer to a number or a boolean
+func isScalarTarget(dst any) bool {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false
+	}
+
+	switch rv.Type().Elem().Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// assignQuoted assigns the number or boolean encoded in s to dst, the
+// same way encoding/json decodes fields tagged with the ",string" option
+func assignQuoted(dst any, s string) error {
+	if err := json.Unmarshal([]byte(s), dst); err != nil {
+		return fmt.Errorf("failed to decode quoted value %q into %T: %w", s, dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package jsptr_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+// lenientAssigner converts numbers to strings, and delegates everything
+// else to the default Assigner
+var lenientAssigner = jsptr.AssignerFunc(func(dst, value any) error {
+	if sp, ok := dst.(*string); ok {
+		if f, ok := value.(float64); ok {
+			*sp = strconv.FormatFloat(f, 'f', -1, 64)
+			return nil
+		}
+	}
+	return jsptr.DefaultAssigner().Assign(dst, value)
+})
+
+func TestAssigner(t *testing.T) {
+	const src = `{"id": 42, "name": "alice"}`
+
+	for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+		t.Run(name, func(t *testing.T) {
+			var s string
+			require.Error(t, jsptr.MustNew("/id").Retrieve(&s, target))
+
+			require.NoError(t, jsptr.MustNew("/id").Retrieve(&s, target, jsptr.WithAssigner(lenientAssigner)))
+			require.Equal(t, "42", s)
+
+			require.NoError(t, jsptr.MustNew("/name").Retrieve(&s, target, jsptr.WithAssigner(lenientAssigner)))
+			require.Equal(t, "alice", s)
+		})
+	}
+
+	t.Run("errors are returned", func(t *testing.T) {
+		errStrict := errors.New("strict")
+		strict := jsptr.AssignerFunc(func(dst, value any) error {
+			return fmt.Errorf("cannot assign %T: %w", value, errStrict)
+		})
+
+		var s string
+		err := jsptr.MustNew("/name").Retrieve(&s, map[string]any{"name": "alice"}, jsptr.WithAssigner(strict))
+		require.ErrorIs(t, err, errStrict)
+	})
+	t.Run("SetAssigner", func(t *testing.T) {
+		jsptr.SetAssigner(lenientAssigner)
+		defer jsptr.SetAssigner(nil)
+
+		var s string
+		require.NoError(t, jsptr.MustNew("/id").Retrieve(&s, []byte(src)))
+		require.Equal(t, "42", s)
+
+		// Per-call assigners take precedence
+		require.Error(t, jsptr.MustNew("/id").Retrieve(&s, []byte(src), jsptr.WithAssigner(jsptr.DefaultAssigner())))
+	})
+}
+
+func TestAssign(t *testing.T) {
+	t.Run("strings are not parsed", func(t *testing.T) {
+		var s string
+		require.NoError(t, jsptr.Assign(&s, `{"a": 1}`))
+		require.Equal(t, `{"a": 1}`, s)
+
+		var b []byte
+		require.NoError(t, jsptr.Assign(&b, []byte("[1]")))
+		require.Equal(t, []byte("[1]"), b)
+	})
+	t.Run("values are converted", func(t *testing.T) {
+		type point struct {
+			X int `json:"x"`
+		}
+		var p point
+		require.NoError(t, jsptr.Assign(&p, map[string]any{"x": 1.0}))
+		require.Equal(t, point{X: 1}, p)
+
+		var ts time.Time
+		require.NoError(t, jsptr.Assign(&ts, "2024-01-02T03:04:05Z"))
+		require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ts)
+	})
+	t.Run("options", func(t *testing.T) {
+		var n int
+		err := jsptr.Assign(&n, 1.0, jsptr.WithAssigner(jsptr.AssignerFunc(func(dst, v any) error {
+			return errors.New("rejected")
+		})))
+		require.ErrorContains(t, err, "rejected")
+	})
+	t.Run("incompatible values", func(t *testing.T) {
+		var n int
+		require.Error(t, jsptr.Assign(&n, "one"))
+	})
+}
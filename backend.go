@@ -0,0 +1,192 @@
+package jsptr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Backend decodes JSON documents on behalf of the package, so that
+// libraries such as goccy/go-json or bytedance/sonic can be used instead
+// of the built-in parser. See SetBackend and WithBackend.
+//
+// When a backend is used, documents given as []byte or string are decoded
+// into generic values, which the pointer is then evaluated against.
+// Values retrieved into structs and other composite types are decoded by
+// the backend directly from the bytes of the addressed subtree.
+//
+// Backends only replace the parser used to retrieve values from JSON
+// bytes. The built-in parser (github.com/valyala/fastjson) is still used
+// by other parts of the package, such as Document, Walk and the editing
+// functions, so it remains a dependency even when a backend is set.
+type Backend interface {
+	// Unmarshal decodes data into v, as json.Unmarshal does. Numbers
+	// that are decoded into interface values must be json.Number, as
+	// with (*json.Decoder).UseNumber, so that they can be converted
+	// according to the NumberMode of the retrieval
+	Unmarshal(data []byte, v any) error
+}
+
+// BackendFunc is a function that implements Backend
+type BackendFunc func(data []byte, v any) error
+
+// Unmarshal calls f(data, v)
+func (f BackendFunc) Unmarshal(data []byte, v any) error {
+	return f(data, v)
+}
+
+// StdlibBackend is a Backend that uses encoding/json
+var StdlibBackend Backend = BackendFunc(unmarshalStdlib)
+
+func unmarshalStdlib(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// backend holds the Backend set using SetBackend, if any
+var backend atomic.Pointer[Backend]
+
+// SetBackend sets the Backend that is used to decode JSON documents by the
+// whole package, unless one is given with WithBackend. Passing nil
+// restores the built-in parser, which is the default.
+func SetBackend(b Backend) {
+	if b == nil {
+		backend.Store(nil)
+		return
+	}
+	backend.Store(&b)
+}
+
+// jsonBackend returns the Backend used by the retrieval, or nil if the
+// built-in parser should be used. Backends cannot preserve the order of
+// object members, so the built-in parser is always used for retrievals
+// with ordered objects
+func (cfg *retrieveConfig) jsonBackend() Backend {
+	if cfg.orderedObjects {
+		return nil
+	}
+	if cfg.backend != nil {
+		return cfg.backend
+	}
+	if b := backend.Load(); b != nil {
+		return *b
+	}
+	return nil
+}
+
+// unmarshalJSON decodes data into v using b, and reports the parse to the
+// metrics in the same way as parseJSON
+func unmarshalJSON(b Backend, data []byte, v any) error {
+	m := currentMetrics()
+	var start time.Time
+	if m != nil {
+		start = time.Now()
+	}
+	var err error
+	if uerr := b.Unmarshal(data, v); uerr != nil {
+		err = &syntaxError{err: uerr}
+	}
+	if m != nil {
+		m.ParseDone(len(data), time.Since(start), err)
+	}
+	return err
+}
+
+// retrieveFromBackend decodes data using b, and retrieves the value
+// pointed by tokens
+func retrieveFromBackend(dst any, b Backend, data []byte, tokens []string, cfg *retrieveConfig) error {
+	raw, isRaw := dst.(*json.RawMessage)
+	if isRaw || isDecodeTarget(dst) {
+		// Only the addressed subtree is decoded, so the document is
+		// validated without being decoded. Invalid documents are passed
+		// to the backend, so that its error is reported
+		if !json.Valid(data) {
+			var doc any
+			if err := unmarshalJSON(b, data, &doc); err != nil {
+				return err
+			}
+			return &syntaxError{err: errors.New("invalid JSON")}
+		}
+		sub, err := locateRaw(data, tokens, cfg)
+		if err != nil {
+			return err
+		}
+		if isRaw {
+			*raw = append((*raw)[:0], sub...)
+			return nil
+		}
+		if err := b.Unmarshal(sub, dst); err != nil {
+			return fmt.Errorf("failed to decode value into %T: %w", dst, err)
+		}
+		return nil
+	}
+
+	var doc any
+	if err := unmarshalJSON(b, data, &doc); err != nil {
+		return err
+	}
+	if cfg.zeroCopyStrings {
+		if assignAliasedString(dst, data, tokens, cfg) {
+			return nil
+		}
+	}
+
+	node, _, err := navigate(doc, tokens, cfg)
+	if err != nil {
+		return err
+	}
+	if num, ok := node.(json.Number); ok {
+		// As with the built-in parser, integer destinations are
+		// populated from the original text of the number
+		if ok, err := assignInteger(dst, []byte(num)); ok || err != nil {
+			return err
+		}
+	}
+	v, err := convertNumbers(node, cfg.numberMode)
+	if err != nil {
+		return err
+	}
+	return cfg.assign(dst, v)
+}
+
+// convertNumbers replaces the json.Number values produced by a Backend
+// with values of the type selected by mode. Containers are modified in
+// place, as they are owned by the retrieval
+func convertNumbers(v any, mode NumberMode) (any, error) {
+	if mode == NumberJSONNumber {
+		return v, nil
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		return numberValue([]byte(v), mode)
+	case map[string]any:
+		for key, val := range v {
+			nv, err := convertNumbers(val, mode)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = nv
+		}
+	case []any:
+		for i, val := range v {
+			nv, err := convertNumbers(val, mode)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = nv
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,184 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackend(t *testing.T) {
+	const doc = `{"user": {"name": "alice", "id": 9007199254740993, "score": 1.5, "tags": ["a", "b"]}}`
+
+	testcases := []struct {
+		Name     string
+		Spec     string
+		Dst      func() any
+		Options  []jsptr.RetrieveOption
+		Expected any
+		Error    bool
+	}{
+		{
+			Name:     "String",
+			Spec:     "/user/name",
+			Dst:      func() any { return new(string) },
+			Expected: "alice",
+		},
+		{
+			Name:     "Integer",
+			Spec:     "/user/id",
+			Dst:      func() any { return new(int64) },
+			Expected: int64(9007199254740993),
+		},
+		{
+			Name:     "Large integer into any",
+			Spec:     "/user/id",
+			Dst:      func() any { return new(any) },
+			Expected: int64(9007199254740993),
+		},
+		{
+			Name:     "Float",
+			Spec:     "/user/score",
+			Dst:      func() any { return new(any) },
+			Expected: 1.5,
+		},
+		{
+			Name:     "JSON number",
+			Spec:     "/user/score",
+			Dst:      func() any { return new(any) },
+			Options:  []jsptr.RetrieveOption{jsptr.WithNumberMode(jsptr.NumberJSONNumber)},
+			Expected: json.Number("1.5"),
+		},
+		{
+			Name:     "Big float",
+			Spec:     "/user/score",
+			Dst:      func() any { return new(any) },
+			Options:  []jsptr.RetrieveOption{jsptr.WithNumberMode(jsptr.NumberBigFloat)},
+			Expected: big.NewFloat(1.5).SetPrec(64),
+		},
+		{
+			Name:     "Array element",
+			Spec:     "/user/tags/1",
+			Dst:      func() any { return new(string) },
+			Expected: "b",
+		},
+		{
+			Name: "Object",
+			Spec: "/user",
+			Dst:  func() any { return new(any) },
+			Expected: map[string]any{
+				"name":  "alice",
+				"id":    int64(9007199254740993),
+				"score": 1.5,
+				"tags":  []any{"a", "b"},
+			},
+		},
+		{
+			Name: "Struct",
+			Spec: "/user",
+			Dst: func() any {
+				return new(struct {
+					Name string   `json:"name"`
+					Tags []string `json:"tags"`
+				})
+			},
+			Expected: struct {
+				Name string   `json:"name"`
+				Tags []string `json:"tags"`
+			}{Name: "alice", Tags: []string{"a", "b"}},
+		},
+		{
+			Name:     "Raw message",
+			Spec:     "/user/tags",
+			Dst:      func() any { return new(json.RawMessage) },
+			Expected: json.RawMessage(`["a", "b"]`),
+		},
+		{
+			Name:  "Missing property",
+			Spec:  "/user/email",
+			Dst:   func() any { return new(any) },
+			Error: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Spec)
+			require.NoError(t, err)
+
+			dst := tc.Dst()
+			options := append([]jsptr.RetrieveOption{jsptr.WithBackend(jsptr.StdlibBackend)}, tc.Options...)
+			err = ptr.Retrieve(dst, doc, options...)
+			if tc.Error {
+				require.Error(t, err)
+				require.ErrorIs(t, err, jsptr.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+
+			// Compare big floats by value
+			if f, ok := tc.Expected.(*big.Float); ok {
+				require.Zero(t, f.Cmp(reflect.ValueOf(dst).Elem().Interface().(*big.Float)))
+				return
+			}
+			require.Equal(t, tc.Expected, reflect.ValueOf(dst).Elem().Interface())
+		})
+	}
+
+	t.Run("Syntax error", func(t *testing.T) {
+		var v any
+		err := jsptr.MustNew("/a").Retrieve(&v, `{"a": 1} {}`, jsptr.WithBackend(jsptr.StdlibBackend))
+		require.Error(t, err)
+		require.Equal(t, jsptr.ErrorClassSyntax, jsptr.ErrorClass(err))
+	})
+	t.Run("SetBackend", func(t *testing.T) {
+		var calls int
+		jsptr.SetBackend(jsptr.BackendFunc(func(data []byte, v any) error {
+			calls++
+			return jsptr.StdlibBackend.Unmarshal(data, v)
+		}))
+		defer jsptr.SetBackend(nil)
+
+		var name string
+		require.NoError(t, jsptr.MustNew("/user/name").Retrieve(&name, doc))
+		require.Equal(t, "alice", name)
+		require.Equal(t, 1, calls)
+
+		// Ordered objects are always decoded by the built-in parser
+		var v any
+		require.NoError(t, jsptr.MustNew("/user").Retrieve(&v, doc, jsptr.WithOrderedObjects(true)))
+		require.IsType(t, &jsptr.OrderedMap{}, v)
+		require.Equal(t, 1, calls)
+
+		jsptr.SetBackend(nil)
+		require.NoError(t, jsptr.MustNew("/user/name").Retrieve(&name, doc))
+		require.Equal(t, 1, calls)
+	})
+	t.Run("Decode targets", func(t *testing.T) {
+		var decoded []string
+		backend := jsptr.BackendFunc(func(data []byte, v any) error {
+			decoded = append(decoded, string(data))
+			return jsptr.StdlibBackend.Unmarshal(data, v)
+		})
+
+		// Only the addressed subtree is decoded
+		var user struct {
+			Name string   `json:"name"`
+			Tags []string `json:"tags"`
+		}
+		require.NoError(t, jsptr.MustNew("/user").Retrieve(&user, doc, jsptr.WithBackend(backend)))
+		require.Equal(t, "alice", user.Name)
+		require.Equal(t, []string{"a", "b"}, user.Tags)
+		require.Len(t, decoded, 1)
+		require.Contains(t, decoded[0], `"name": "alice"`)
+		require.NotContains(t, decoded[0], `"user"`)
+
+		// Invalid documents are still rejected
+		err := jsptr.MustNew("/user").Retrieve(&user, `{"user": {}} {}`, jsptr.WithBackend(backend))
+		require.Error(t, err)
+		require.Equal(t, jsptr.ErrorClassSyntax, jsptr.ErrorClass(err))
+	})
+}
@@ -0,0 +1,137 @@
+package jsptr
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultPointerCacheSize is the number of pointers that Cached keeps
+// by default
+const DefaultPointerCacheSize = 1024
+
+var pointerCache = newLRU(DefaultPointerCacheSize)
+
+// Cached works like New, but pointers are looked up in a global cache
+// before they are compiled. This is useful when the same specifications
+// are received over and over at runtime, such as from request parameters.
+//
+// The cache holds a bounded number of pointers (see SetPointerCacheSize),
+// evicting the least recently used ones, so that an unbounded set of
+// specifications cannot exhaust memory. Invalid specifications are not
+// cached. Pointers returned by Cached are shared, and must not be
+// modified. Cached is safe for concurrent use.
+//
+// Options are applied as they are by New. Pointers are cached separately
+// for each combination of options, so limits such as WithMaxDepth are
+// enforced even if the same specification was cached without them.
+func Cached(spec string, options ...NewOption) (*Pointer, error) {
+	key := pointerCacheKey{spec: spec, cfg: newPointerConfig(options)}
+	if ptr, ok := pointerCache.get(key); ok {
+		observeCache("pointer", true)
+		return ptr, nil
+	}
+	observeCache("pointer", false)
+
+	ptr, err := newPointer(spec, key.cfg)
+	if err != nil {
+		return nil, err
+	}
+	pointerCache.add(key, ptr)
+	return ptr, nil
+}
+
+// SetPointerCacheSize sets the maximum number of pointers kept by Cached.
+// If the cache holds more pointers than the new size, the least recently
+// used ones are evicted. A size of 0 or less disables caching.
+func SetPointerCacheSize(n int) {
+	pointerCache.resize(n)
+}
+
+// pointerCacheKey identifies the pointers held by the cache
+type pointerCacheKey struct {
+	spec string
+	cfg  pointerConfig
+}
+
+// lru is a cache that holds a bounded number of entries, and evicts the
+// least recently used entry when it is full
+type lru struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[pointerCacheKey]*list.Element
+	// order holds the entries from the most to the least recently used
+	order *list.List
+}
+
+type lruEntry struct {
+	key   pointerCacheKey
+	value *Pointer
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		entries:  make(map[pointerCacheKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *lru) get(key pointerCacheKey) (*Pointer, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+func (c *lru) add(key pointerCacheKey, value *Pointer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.capacity <= 0 {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	c.evict()
+}
+
+func (c *lru) resize(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = capacity
+	c.evict()
+}
+
+// evict removes the least recently used entries until the cache fits
+// within its capacity. c.mu must be held
+func (c *lru) evict() {
+	for c.order.Len() > max(c.capacity, 0) {
+		elem := c.order.Back()
+		c.order.Remove(elem)
+		delete(c.entries, elem.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lru) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.order.Init()
+}
+
+func (c *lru) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,189 @@
+package jsptr_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCached(t *testing.T) {
+	m := newRecordingMetrics()
+	jsptr.SetMetrics(m)
+	defer jsptr.SetMetrics(nil)
+	defer jsptr.SetPointerCacheSize(jsptr.DefaultPointerCacheSize)
+
+	t.Run("Shared pointers", func(t *testing.T) {
+		p1, err := jsptr.Cached("/cached/a")
+		require.NoError(t, err)
+		p2, err := jsptr.Cached("/cached/a")
+		require.NoError(t, err)
+		require.Same(t, p1, p2)
+		require.Equal(t, "/cached/a", p1.Pattern())
+
+		var v int
+		require.NoError(t, p1.Retrieve(&v, `{"cached": {"a": 1}}`))
+		require.Equal(t, 1, v)
+	})
+	t.Run("Options", func(t *testing.T) {
+		const spec = "/options/a/b"
+		p1, err := jsptr.Cached(spec)
+		require.NoError(t, err)
+
+		// Limits are enforced even though the specification is cached
+		_, err = jsptr.Cached(spec, jsptr.WithMaxDepth(2))
+		require.ErrorIs(t, err, jsptr.ErrLimitExceeded)
+
+		p2, err := jsptr.Cached(spec, jsptr.WithMaxDepth(3))
+		require.NoError(t, err)
+		require.NotSame(t, p1, p2)
+		p3, err := jsptr.Cached(spec, jsptr.WithMaxDepth(3))
+		require.NoError(t, err)
+		require.Same(t, p2, p3)
+	})
+	t.Run("Invalid specification", func(t *testing.T) {
+		_, err := jsptr.Cached("cached")
+		require.Error(t, err)
+		_, err = jsptr.Cached("cached")
+		require.Error(t, err)
+	})
+	t.Run("Eviction", func(t *testing.T) {
+		jsptr.SetPointerCacheSize(2)
+		p1, err := jsptr.Cached("/evict/1")
+		require.NoError(t, err)
+		_, err = jsptr.Cached("/evict/2")
+		require.NoError(t, err)
+
+		// Using /evict/1 makes /evict/2 the least recently used pointer
+		p, err := jsptr.Cached("/evict/1")
+		require.NoError(t, err)
+		require.Same(t, p1, p)
+		_, err = jsptr.Cached("/evict/3")
+		require.NoError(t, err)
+
+		p, err = jsptr.Cached("/evict/1")
+		require.NoError(t, err)
+		require.Same(t, p1, p)
+
+		before := m.cache["pointer:false"]
+		_, err = jsptr.Cached("/evict/2")
+		require.NoError(t, err)
+		require.Equal(t, before+1, m.cache["pointer:false"])
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		jsptr.SetPointerCacheSize(0)
+		p1, err := jsptr.Cached("/disabled")
+		require.NoError(t, err)
+		p2, err := jsptr.Cached("/disabled")
+		require.NoError(t, err)
+		require.NotSame(t, p1, p2)
+	})
+	t.Run("Concurrent use", func(t *testing.T) {
+		jsptr.SetPointerCacheSize(8)
+		var wg sync.WaitGroup
+		errs := make([]error, 16)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range 100 {
+					spec := fmt.Sprintf("/concurrent/%d", (i+j)%32)
+					ptr, err := jsptr.Cached(spec)
+					if err == nil && ptr.Pattern() != spec {
+						err = fmt.Errorf("expected %q, got %q", spec, ptr.Pattern())
+					}
+					if err != nil {
+						errs[i] = err
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestStructCache(t *testing.T) {
+	m := newRecordingMetrics()
+	jsptr.SetMetrics(m)
+	defer jsptr.SetMetrics(nil)
+	defer jsptr.SetStructCacheSize(jsptr.DefaultStructCacheSize)
+
+	type first struct {
+		Name string `json:"name"`
+	}
+	type second struct {
+		Name string `json:"name"`
+	}
+
+	// New pointers are used every time, as pointers keep the plans they
+	// compile, which would otherwise bypass the cache
+	retrieve := func(target any, options ...jsptr.RetrieveOption) {
+		t.Helper()
+		var v string
+		require.NoError(t, jsptr.MustNew("/name").Retrieve(&v, target, options...))
+		require.Equal(t, "x", v)
+	}
+	lookups := func() (int, int) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		hits, misses := m.cache["struct:true"], m.cache["struct:false"]
+		clear(m.cache)
+		return hits, misses
+	}
+
+	t.Run("ClearCache", func(t *testing.T) {
+		jsptr.ClearCache()
+		lookups()
+
+		retrieve(first{Name: "x"})
+		retrieve(first{Name: "x"})
+		hits, misses := lookups()
+		require.Equal(t, 1, hits)
+		require.Equal(t, 1, misses)
+
+		jsptr.ClearCache()
+		retrieve(first{Name: "x"})
+		hits, misses = lookups()
+		require.Equal(t, 0, hits)
+		require.Equal(t, 1, misses)
+	})
+	t.Run("WithStructCache", func(t *testing.T) {
+		ptr := jsptr.MustNew("/name")
+		for range 2 {
+			var v string
+			require.NoError(t, ptr.Retrieve(&v, second{Name: "x"}, jsptr.WithStructCache(false)))
+			require.Equal(t, "x", v)
+		}
+		hits, misses := lookups()
+		require.Equal(t, 0, hits)
+		require.Equal(t, 0, misses)
+	})
+	t.Run("bounded size", func(t *testing.T) {
+		jsptr.ClearCache()
+		jsptr.SetStructCacheSize(1)
+		lookups()
+
+		retrieve(first{Name: "x"})
+		retrieve(second{Name: "x"})
+		retrieve(first{Name: "x"})
+		hits, misses := lookups()
+		require.Equal(t, 0, hits)
+		require.Equal(t, 3, misses)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		jsptr.SetStructCacheSize(0)
+		lookups()
+
+		retrieve(first{Name: "x"})
+		retrieve(first{Name: "x"})
+		hits, misses := lookups()
+		require.Equal(t, 0, hits)
+		require.Equal(t, 2, misses)
+	})
+}
@@ -0,0 +1,116 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Children returns an iterator over the members of the container at the
+// JSON pointer location. For objects the iterator yields member names and
+// values, and for arrays it yields indices (as strings) and elements.
+// Values are converted in the same way as Retrieve would into an `any`.
+//
+// Objects in JSON targets are iterated in document order, Go maps in
+// sorted key order, and struct fields in the order they are declared.
+//
+// The sequence is empty if the pointer cannot be resolved, or if the value
+// at the location is not a container. JSON targets are parsed each time
+// the iteration is started. Other errors, such as malformed JSON, also
+// end the iteration; use ChildrenE to find out about them.
+func (p *Pointer) Children(target any) iter.Seq2[string, any] {
+	seq, _ := p.ChildrenE(target)
+	return seq
+}
+
+// ChildrenE is like Children, but also returns a function that reports
+// the error that ended the most recent iteration, if any. A location that
+// does not exist is not an error, and yields an empty sequence.
+func (p *Pointer) ChildrenE(target any) (iter.Seq2[string, any], func() error) {
+	var err error
+	seq := func(yield func(string, any) bool) {
+		err = resolveNode(target, p.tokens, func(node any) error {
+			seq, ok := members(node)
+			if !ok {
+				return nil
+			}
+			for token, child := range seq {
+				v, err := exportNode(child, defaultRetrieveConfig)
+				if err != nil {
+					return fmt.Errorf("failed to convert value at '%s': %w", token, err)
+				}
+				if !yield(token, v) {
+					return nil
+				}
+			}
+			return nil
+		})
+		if errors.Is(err, ErrNotFound) {
+			err = nil
+		}
+	}
+	return seq, func() error { return err }
+}
+
+// resolveNode finds the value at the location specified by tokens, and
+// calls fn with it without converting it. For JSON targets the value is
+// passed as a jsonNode, which is only valid for the duration of the call
+func resolveNode(target any, tokens []string, fn func(node any) error) error {
+	switch v := target.(type) {
+	case []byte:
+		return resolveJSONNode(v, tokens, fn)
+	case string:
+		return resolveJSONNode([]byte(v), tokens, fn)
+	case io.Reader:
+		data, err := io.ReadAll(v)
+		if err != nil {
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+		return resolveJSONNode(data, tokens, fn)
+	case *Document:
+		node, err := navigateJSON(v.src.parsed, tokens, defaultRetrieveConfig)
+		if err != nil {
+			return err
+		}
+		return fn(jsonNode{node})
+	}
+
+	source, err := createSource(target)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := source.(valueSource); ok {
+		node, rest, err := navigate(vs.data, tokens, defaultRetrieveConfig)
+		if err != nil {
+			return err
+		}
+		if len(rest) == 0 {
+			return fn(node)
+		}
+		source, tokens = node.(Source), rest
+	}
+
+	// Custom sources can only give us converted values
+	var node any
+	if err := retrieveFromSource(&node, source, tokens, defaultRetrieveConfig); err != nil {
+		return err
+	}
+	return fn(node)
+}
+
+func resolveJSONNode(data []byte, tokens []string, fn func(node any) error) error {
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+
+	parsed, err := parseJSON(p, data)
+	if err != nil {
+		return err
+	}
+	node, err := navigateJSON(parsed, tokens, defaultRetrieveConfig)
+	if err != nil {
+		return err
+	}
+	return fn(jsonNode{node})
+}
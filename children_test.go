@@ -0,0 +1,96 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerChildren(t *testing.T) {
+	const src = `{"obj": {"z": 1, "a": {"x": true}}, "arr": ["a", "b"], "str": "s"}`
+
+	collect := func(t *testing.T, spec string, target any) ([]string, []any) {
+		t.Helper()
+		ptr, err := jsptr.New(spec)
+		require.NoError(t, err)
+
+		var tokens []string
+		var values []any
+		for token, v := range ptr.Children(target) {
+			tokens = append(tokens, token)
+			values = append(values, v)
+		}
+		return tokens, values
+	}
+
+	t.Run("JSON object", func(t *testing.T) {
+		tokens, values := collect(t, "/obj", []byte(src))
+		require.Equal(t, []string{"z", "a"}, tokens)
+		require.Equal(t, []any{1.0, map[string]any{"x": true}}, values)
+	})
+	t.Run("JSON array", func(t *testing.T) {
+		tokens, values := collect(t, "/arr", src)
+		require.Equal(t, []string{"0", "1"}, tokens)
+		require.Equal(t, []any{"a", "b"}, values)
+	})
+	t.Run("map", func(t *testing.T) {
+		tokens, values := collect(t, "/obj", decodeJSON(t, src))
+		require.Equal(t, []string{"a", "z"}, tokens)
+		require.Equal(t, []any{map[string]any{"x": true}, 1.0}, values)
+	})
+	t.Run("struct", func(t *testing.T) {
+		type S struct {
+			B int    `json:"b"`
+			A string `json:"a"`
+		}
+		tokens, values := collect(t, "", S{B: 1, A: "x"})
+		require.Equal(t, []string{"b", "a"}, tokens)
+		require.Equal(t, []any{1, "x"}, values)
+	})
+	t.Run("scalar yields nothing", func(t *testing.T) {
+		tokens, _ := collect(t, "/str", []byte(src))
+		require.Empty(t, tokens)
+	})
+	t.Run("missing location yields nothing", func(t *testing.T) {
+		tokens, _ := collect(t, "/missing", []byte(src))
+		require.Empty(t, tokens)
+	})
+	t.Run("errors", func(t *testing.T) {
+		testcases := []struct {
+			Name   string
+			Spec   string
+			Target any
+			Error  bool
+		}{
+			{Name: "container", Spec: "/obj", Target: src},
+			{Name: "scalar", Spec: "/str", Target: src},
+			{Name: "missing location", Spec: "/missing", Target: src},
+			{Name: "malformed JSON", Spec: "/obj", Target: `{"obj": {`, Error: true},
+			{Name: "invalid index", Spec: "/arr/x", Target: src, Error: true},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.Name, func(t *testing.T) {
+				seq, errf := jsptr.MustNew(tc.Spec).ChildrenE(tc.Target)
+				for range seq {
+				}
+				if tc.Error {
+					require.Error(t, errf())
+					return
+				}
+				require.NoError(t, errf())
+			})
+		}
+	})
+	t.Run("early break", func(t *testing.T) {
+		ptr, err := jsptr.New("/arr")
+		require.NoError(t, err)
+
+		var count int
+		for range ptr.Children([]byte(src)) {
+			count++
+			break
+		}
+		require.Equal(t, 1, count)
+	})
+}
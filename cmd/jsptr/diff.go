@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+func runDiff(e *env, args []string) error {
+	fs := newFlagSet(e, "diff", "FILE1 FILE2")
+	text := fs.Bool("t", false, "print one line per change, instead of a JSON Patch")
+	compact := fs.Bool("c", false, "write compact JSON, instead of indenting it")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return usageError("expected two files")
+	}
+	if fs.Arg(0) == "-" && fs.Arg(1) == "-" {
+		return usageError("only one of the files can be read from the standard input")
+	}
+
+	a, err := readInput(e, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := readInput(e, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	options := []jsptr.RetrieveOption{jsptr.WithOrderedObjects(true), jsptr.WithNumberMode(jsptr.NumberJSONNumber)}
+	if *text {
+		changes, err := jsptr.Changes(a, b, options...)
+		if err != nil {
+			return err
+		}
+		w := bufio.NewWriter(e.stdout)
+		for _, c := range changes {
+			fmt.Fprintln(w, c)
+		}
+		return w.Flush()
+	}
+
+	patch, err := jsptr.Diff(a, b, options...)
+	if err != nil {
+		return err
+	}
+	if patch == nil {
+		patch = jsptr.Patch{}
+	}
+	var buf []byte
+	if *compact {
+		buf, err = json.Marshal(patch)
+	} else {
+		buf, err = json.MarshalIndent(patch, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+	_, err = fmt.Fprintf(e.stdout, "%s\n", buf)
+	return err
+}
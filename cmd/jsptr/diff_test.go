@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	require.NoError(t, os.WriteFile(a, []byte(`{"name": "a", "tags": ["x", "y"], "n": 1}`), 0o600))
+	require.NoError(t, os.WriteFile(b, []byte(`{"name": "b", "tags": ["x"], "n": 1.0, "m": {"z": 1, "a": 2}}`), 0o600))
+
+	testcases := []struct {
+		Name   string
+		Args   []string
+		Stdin  string
+		Code   int
+		Stdout string
+	}{
+		{
+			Name:   "patch",
+			Args:   []string{"-c", a, b},
+			Stdout: `[{"op":"replace","path":"/name","value":"b"},{"op":"remove","path":"/tags/1"},{"op":"add","path":"/m","value":{"z":1,"a":2}}]` + "\n",
+		},
+		{
+			Name:   "text",
+			Args:   []string{"-t", a, b},
+			Stdout: "changed /name: \"a\" -> \"b\"\nremoved /tags/1\nadded /m: {\"z\":1,\"a\":2}\n",
+		},
+		{Name: "no differences", Args: []string{"-c", a, a}, Stdout: "[]\n"},
+		{Name: "no differences as text", Args: []string{"-t", a, a}},
+		{Name: "standard input", Args: []string{"-c", "-", a}, Stdin: `{"name": "a", "tags": ["x", "y"]}`, Stdout: `[{"op":"add","path":"/n","value":1}]` + "\n"},
+		{Name: "both from standard input", Args: []string{"-", "-"}, Code: 2},
+		{Name: "one file", Args: []string{a}, Code: 2},
+		{Name: "missing file", Args: []string{a, filepath.Join(dir, "missing.json")}, Code: 1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			code, stdout, stderr := invoke(t, tc.Stdin, append([]string{"diff"}, tc.Args...)...)
+			require.Equal(t, tc.Code, code, stderr)
+			require.Equal(t, tc.Stdout, stdout)
+		})
+	}
+}
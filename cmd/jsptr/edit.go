@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// editFlags are the flags shared by the commands that modify documents
+type editFlags struct {
+	file    *string
+	write   *bool
+	compact *bool
+}
+
+func addEditFlags(fs *flag.FlagSet) *editFlags {
+	return &editFlags{
+		file:    fs.String("f", "", "read the document from `file` instead of the standard input"),
+		write:   fs.Bool("w", false, "write the result back to the file given with -f, instead of the standard output"),
+		compact: fs.Bool("c", false, "write compact JSON, instead of indenting it"),
+	}
+}
+
+// check validates the combination of flags
+func (f *editFlags) check() error {
+	if *f.write && (*f.file == "" || *f.file == "-") {
+		return usageError("-w requires a file given with -f")
+	}
+	return nil
+}
+
+// output writes the modified document to the standard output, or back
+// to the input file
+func (f *editFlags) output(e *env, doc []byte) error {
+	var buf bytes.Buffer
+	if *f.compact {
+		buf.Write(doc)
+	} else if err := json.Indent(&buf, doc, "", "  "); err != nil {
+		return fmt.Errorf("failed to format document: %w", err)
+	}
+	buf.WriteByte('\n')
+
+	if !*f.write {
+		_, err := e.stdout.Write(buf.Bytes())
+		return err
+	}
+	return writeFile(*f.file, buf.Bytes())
+}
+
+// writeFile replaces the contents of the named file atomically, by
+// writing to a temporary file in the same directory and renaming it
+func writeFile(name string, data []byte) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fmt.Errorf("failed to stat output: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), fi.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to replace output: %w", err)
+	}
+	return nil
+}
+
+// editDocument reads the input document, applies fn to it, and writes
+// the result
+func editDocument(e *env, flags *editFlags, fn func(doc []byte) (any, error)) error {
+	data, err := readInput(e, *flags.file)
+	if err != nil {
+		return err
+	}
+	result, err := fn(data)
+	if err != nil {
+		return err
+	}
+	return flags.output(e, result.([]byte))
+}
+
+func runSet(e *env, args []string) error {
+	fs := newFlagSet(e, "set", "POINTER VALUE")
+	flags := addEditFlags(fs)
+	str := fs.Bool("s", false, "treat VALUE as a string, instead of as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return usageError("expected a pointer and a value")
+	}
+	if err := flags.check(); err != nil {
+		return err
+	}
+	spec := fs.Arg(0)
+
+	var value any = fs.Arg(1)
+	if !*str {
+		if !json.Valid([]byte(fs.Arg(1))) {
+			return usageError(fmt.Sprintf("invalid JSON value %q (use -s for strings)", fs.Arg(1)))
+		}
+		value = json.RawMessage(fs.Arg(1))
+	}
+
+	return editDocument(e, flags, func(doc []byte) (any, error) {
+		return jsptr.Set(doc, spec, value)
+	})
+}
+
+func runDelete(e *env, args []string) error {
+	fs := newFlagSet(e, "delete", "POINTER")
+	flags := addEditFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError("expected exactly one pointer")
+	}
+	if err := flags.check(); err != nil {
+		return err
+	}
+	spec := fs.Arg(0)
+
+	return editDocument(e, flags, func(doc []byte) (any, error) {
+		return jsptr.Delete(doc, spec)
+	})
+}
+
+func runPatch(e *env, args []string) error {
+	fs := newFlagSet(e, "patch", "PATCHFILE")
+	flags := addEditFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError("expected exactly one patch file")
+	}
+	if err := flags.check(); err != nil {
+		return err
+	}
+	if name := fs.Arg(0); name == "-" && (*flags.file == "" || *flags.file == "-") {
+		return usageError("the patch and the document cannot both be read from the standard input")
+	}
+
+	data, err := readInput(e, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	// Values are decoded like the document, so that their member order
+	// and numbers are preserved
+	patch, err := jsptr.DecodePatch(data, jsptr.WithOrderedObjects(true), jsptr.WithNumberMode(jsptr.NumberJSONNumber))
+	if err != nil {
+		return err
+	}
+
+	return editDocument(e, flags, func(doc []byte) (any, error) {
+		return jsptr.ApplyPatch(doc, patch)
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdit(t *testing.T) {
+	const doc = `{"name": "jsptr", "tags": ["json"], "stars": 12345678901234567890}`
+
+	testcases := []struct {
+		Name   string
+		Args   []string
+		Code   int
+		Stdout string
+		Stderr string
+	}{
+		{Name: "set", Args: []string{"set", "-c", "/name", `"ptr"`}, Stdout: `{"name":"ptr","tags":["json"],"stars":12345678901234567890}` + "\n"},
+		{Name: "set string", Args: []string{"set", "-c", "-s", "/tags/-", "pointer"}, Stdout: `{"name":"jsptr","tags":["json","pointer"],"stars":12345678901234567890}` + "\n"},
+		{Name: "set object", Args: []string{"set", "-c", "/meta", `{"z": 1, "a": 2}`}, Stdout: `{"name":"jsptr","tags":["json"],"stars":12345678901234567890,"meta":{"z":1,"a":2}}` + "\n"},
+		{Name: "set indented", Args: []string{"set", "/tags", "[]"}, Stdout: "{\n  \"name\": \"jsptr\",\n  \"tags\": [],\n  \"stars\": 12345678901234567890\n}\n"},
+		{Name: "set invalid value", Args: []string{"set", "/name", "ptr"}, Code: 2, Stderr: "use -s for strings"},
+		{Name: "set missing parent", Args: []string{"set", "/a/b", "1"}, Code: 1, Stderr: "not found"},
+		{Name: "delete", Args: []string{"delete", "-c", "/tags/0"}, Stdout: `{"name":"jsptr","tags":[],"stars":12345678901234567890}` + "\n"},
+		{Name: "delete missing", Args: []string{"delete", "/missing"}, Code: 1, Stderr: "not found"},
+		{Name: "delete no pointer", Args: []string{"delete"}, Code: 2},
+		{Name: "write without file", Args: []string{"delete", "-w", "/name"}, Code: 2, Stderr: "-w requires a file"},
+		{Name: "patch from stdin twice", Args: []string{"patch", "-"}, Code: 2},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			code, stdout, stderr := invoke(t, doc, tc.Args...)
+			require.Equal(t, tc.Code, code, stderr)
+			require.Equal(t, tc.Stdout, stdout)
+			require.Contains(t, stderr, tc.Stderr)
+		})
+	}
+
+	t.Run("patch", func(t *testing.T) {
+		dir := t.TempDir()
+		patch := filepath.Join(dir, "patch.json")
+		require.NoError(t, os.WriteFile(patch, []byte(`[
+			{"op": "replace", "path": "/name", "value": "ptr"},
+			{"op": "add", "path": "/tags/0", "value": {"b": 1, "a": 2}},
+			{"op": "remove", "path": "/stars"}
+		]`), 0o600))
+
+		code, stdout, stderr := invoke(t, doc, "patch", "-c", patch)
+		require.Equal(t, 0, code, stderr)
+		require.Equal(t, `{"name":"ptr","tags":[{"b":1,"a":2},"json"]}`+"\n", stdout)
+
+		require.NoError(t, os.WriteFile(patch, []byte(`[{"op": "remove", "path": "/missing"}]`), 0o600))
+		code, _, stderr = invoke(t, doc, "patch", patch)
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "operation 0")
+	})
+	t.Run("write in place", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "doc.json")
+		require.NoError(t, os.WriteFile(path, []byte(doc), 0o640))
+
+		code, stdout, stderr := invoke(t, "", "set", "-w", "-c", "-f", path, "/name", `"ptr"`)
+		require.Equal(t, 0, code, stderr)
+		require.Empty(t, stdout)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, `{"name":"ptr","tags":["json"],"stars":12345678901234567890}`+"\n", string(data))
+
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o640), fi.Mode().Perm())
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "temporary files are removed")
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+func runFlatten(e *env, args []string) error {
+	fs := newFlagSet(e, "flatten", "")
+	file := fs.String("f", "", "read the document from `file` instead of the standard input")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return usageError("unexpected arguments")
+	}
+
+	doc, err := readDocument(e, *file)
+	if err != nil {
+		return err
+	}
+	flat, err := jsptr.Flatten(doc)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(e.stdout)
+	for _, ptr := range slices.SortedFunc(maps.Keys(flat), comparePointers) {
+		buf, err := json.Marshal(flat[ptr])
+		if err != nil {
+			return fmt.Errorf("failed to encode value at '%s': %w", ptr, err)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", ptr, buf)
+	}
+	return w.Flush()
+}
+
+// readDocument reads and decodes the input document. Member order and
+// numbers are preserved, so that values are printed as they were written
+func readDocument(e *env, name string) (any, error) {
+	data, err := readInput(e, name)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsptr.ParseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Get("", jsptr.WithOrderedObjects(true), jsptr.WithNumberMode(jsptr.NumberJSONNumber))
+}
+
+// comparePointers orders pointers token by token, comparing array
+// indices numerically so that "/a/2" comes before "/a/10"
+func comparePointers(a, b string) int {
+	ta := strings.Split(a, "/")
+	tb := strings.Split(b, "/")
+	for i := range min(len(ta), len(tb)) {
+		if c := compareTokens(ta[i], tb[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(ta), len(tb))
+}
+
+func compareTokens(a, b string) int {
+	if isIndex(a) && isIndex(b) {
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func isIndex(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
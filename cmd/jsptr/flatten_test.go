@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	const doc = `{"b": [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, {"x": 12345678901234567890}], "a/c": {}, "a": null}`
+
+	code, stdout, stderr := invoke(t, doc, "flatten")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, "/a\tnull\n"+
+		"/a~1c\t{}\n"+
+		"/b/0\t0\n/b/1\t1\n/b/2\t2\n/b/3\t3\n/b/4\t4\n/b/5\t5\n/b/6\t6\n/b/7\t7\n/b/8\t8\n/b/9\t9\n"+
+		"/b/10/x\t12345678901234567890\n", stdout)
+
+	code, _, _ = invoke(t, doc, "flatten", "extra")
+	require.Equal(t, 2, code)
+
+	code, _, _ = invoke(t, "{", "flatten")
+	require.Equal(t, 1, code)
+}
+
+func TestComparePointers(t *testing.T) {
+	testcases := []struct {
+		A, B     string
+		Expected int
+	}{
+		{A: "", B: "/a", Expected: -1},
+		{A: "/a", B: "/a", Expected: 0},
+		{A: "/a/2", B: "/a/10", Expected: -1},
+		{A: "/a/10", B: "/a/9/x", Expected: 1},
+		{A: "/a/b", B: "/a/10", Expected: 1},
+		{A: "/a", B: "/a/0", Expected: -1},
+	}
+	for _, tc := range testcases {
+		require.Equal(t, tc.Expected, comparePointers(tc.A, tc.B), "%s <=> %s", tc.A, tc.B)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+func runGet(e *env, args []string) error {
+	fs := newFlagSet(e, "get", "POINTER")
+	file := fs.String("f", "", "read the document from `file` instead of the standard input")
+	raw := fs.Bool("r", false, "print strings without quotes, instead of as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError("expected exactly one pointer")
+	}
+	spec := fs.Arg(0)
+
+	data, err := readInput(e, *file)
+	if err != nil {
+		return err
+	}
+	doc, err := jsptr.ParseJSON(data)
+	if err != nil {
+		return err
+	}
+
+	if *raw {
+		if kind, err := jsptr.TypeAt(doc, spec); err == nil && kind == jsptr.KindString {
+			var s string
+			if err := doc.Retrieve(&s, spec); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintln(e.stdout, s)
+			return err
+		}
+	}
+
+	// The value is printed exactly as it appears in the document
+	buf, err := doc.RetrieveRaw(spec)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.stdout, "%s\n", buf)
+	return err
+}
@@ -0,0 +1,152 @@
+// Command jsptr evaluates JSON pointers against JSON documents, and edits
+// documents using them.
+//
+// Usage:
+//
+//	jsptr <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	delete  remove the value at a pointer
+//	diff    print the differences between two documents
+//	flatten list the values in a document along with their pointers
+//	get     print the value at a pointer
+//	patch   apply a JSON Patch (RFC 6902) file
+//	set     set the value at a pointer
+//
+// Documents are read from the file given with -f, or from the standard
+// input if no file is given. The commands that modify documents write the
+// result to the standard output, or back to the file if -w is given.
+// Run "jsptr <command> -h" for the flags accepted by each command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a subcommand of the CLI
+type command struct {
+	summary string
+	run     func(env *env, args []string) error
+}
+
+var commands = map[string]command{
+	"delete":  {summary: "remove the value at a pointer", run: runDelete},
+	"diff":    {summary: "print the differences between two documents", run: runDiff},
+	"flatten": {summary: "list the values in a document along with their pointers", run: runFlatten},
+	"get":     {summary: "print the value at a pointer", run: runGet},
+	"patch":   {summary: "apply a JSON Patch (RFC 6902) file", run: runPatch},
+	"set":     {summary: "set the value at a pointer", run: runSet},
+}
+
+// env holds the standard streams of a CLI invocation
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the CLI with the given arguments, and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+			usage(stdout)
+			return 0
+		}
+		fmt.Fprintf(stderr, "jsptr: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(e, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "jsptr %s: %s\n", args[0], err)
+		var uerr usageError
+		if errors.As(err, &uerr) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: jsptr <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+}
+
+// usageError is returned for invalid command lines
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// newFlagSet creates a flag set for the named command, whose output goes
+// to the standard error of e
+func newFlagSet(e *env, name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: jsptr %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args using fs. Errors are reported as usage errors,
+// as the flag set has already printed them along with the usage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError("invalid flags")
+	}
+	return nil
+}
+
+// readInput reads the document from the named file, or from the standard
+// input if name is empty or "-"
+func readInput(e *env, name string) ([]byte, error) {
+	if name == "" || name == "-" {
+		data, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read standard input: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	return data, nil
+}
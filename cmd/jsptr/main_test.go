@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// invoke runs the CLI, and returns its exit code and outputs
+func invoke(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr strings.Builder
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestUsage(t *testing.T) {
+	code, _, stderr := invoke(t, "")
+	require.Equal(t, 2, code)
+	require.Contains(t, stderr, "usage: jsptr")
+
+	code, _, stderr = invoke(t, "", "frobnicate")
+	require.Equal(t, 2, code)
+	require.Contains(t, stderr, `unknown command "frobnicate"`)
+
+	code, stdout, _ := invoke(t, "", "help")
+	require.Equal(t, 0, code)
+	require.Contains(t, stdout, "get")
+}
+
+func TestGet(t *testing.T) {
+	const doc = `{"name": "jsptr", "tags": ["json", "pointer"], "meta": {"stars":  42, "a/b": null}}`
+
+	testcases := []struct {
+		Name   string
+		Args   []string
+		Code   int
+		Stdout string
+		Stderr string
+	}{
+		{Name: "string", Args: []string{"/name"}, Stdout: "\"jsptr\"\n"},
+		{Name: "raw string", Args: []string{"-r", "/name"}, Stdout: "jsptr\n"},
+		{Name: "raw non-string", Args: []string{"-r", "/meta/stars"}, Stdout: "42\n"},
+		{Name: "container as written", Args: []string{"/meta"}, Stdout: "{\"stars\":  42, \"a/b\": null}\n"},
+		{Name: "escaped token", Args: []string{"/meta/a~1b"}, Stdout: "null\n"},
+		{Name: "array element", Args: []string{"/tags/1"}, Stdout: "\"pointer\"\n"},
+		{Name: "missing", Args: []string{"/missing"}, Code: 1, Stderr: "not found"},
+		{Name: "invalid pointer", Args: []string{"name"}, Code: 1, Stderr: "must start with '/'"},
+		{Name: "no pointer", Args: []string{}, Code: 2, Stderr: "expected exactly one pointer"},
+		{Name: "unknown flag", Args: []string{"-x", "/name"}, Code: 2},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			code, stdout, stderr := invoke(t, doc, append([]string{"get"}, tc.Args...)...)
+			require.Equal(t, tc.Code, code, stderr)
+			require.Equal(t, tc.Stdout, stdout)
+			require.Contains(t, stderr, tc.Stderr)
+		})
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "doc.json")
+		require.NoError(t, os.WriteFile(path, []byte(doc), 0o600))
+
+		code, stdout, stderr := invoke(t, "", "get", "-f", path, "/tags/0")
+		require.Equal(t, 0, code, stderr)
+		require.Equal(t, "\"json\"\n", stdout)
+
+		code, _, _ = invoke(t, "", "get", "-f", filepath.Join(t.TempDir(), "missing.json"), "/tags/0")
+		require.Equal(t, 1, code)
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		code, _, _ := invoke(t, "{", "get", "/a")
+		require.Equal(t, 1, code)
+	})
+}
@@ -0,0 +1,43 @@
+// Package example shows the accessors generated by jsptrgen, and is used
+// to check that the generated code compiles and behaves as expected.
+package example
+
+import "time"
+
+//go:generate go run github.com/lestrrat-go/jsptr/cmd/jsptrgen -type Config GetName=/name GetPort=/server/port GetFirstTag=/tags/0 GetLabel=/labels/team GetBackendURL=/backends/primary/url GetCreated=/created GetServer=/server GetOwnerEmail=/owner/email
+
+// Config is an example configuration
+type Config struct {
+	Metadata
+	Server   *Server             `json:"server"`
+	Tags     []string            `json:"tags"`
+	Labels   map[Label]string    `json:"labels"`
+	Backends map[string]*Backend `json:"backends"`
+	Created  time.Time           `json:"created"`
+	Extra    any                 `json:"extra"`
+	*Owner   `json:"owner"`
+}
+
+// Label is the name of a label
+type Label string
+
+// Metadata is embedded in Config, and its fields are promoted
+type Metadata struct {
+	Name string `json:"name"`
+}
+
+// Server is the configuration of a server
+type Server struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// Backend is a backend service
+type Backend struct {
+	URL string `json:"url"`
+}
+
+// Owner is the owner of a configuration
+type Owner struct {
+	Email string `json:"email"`
+}
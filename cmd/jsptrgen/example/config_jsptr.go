@@ -0,0 +1,164 @@
+// Code generated by jsptrgen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// GetName returns the value at "/name" in doc.
+func GetName(doc *Config) (string, error) {
+	var zero string
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/name",
+			Token:   "name",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'name': %w", jsptr.ErrNotFound),
+		}
+	}
+	return doc.Metadata.Name, nil
+}
+
+// GetPort returns the value at "/server/port" in doc.
+func GetPort(doc *Config) (int, error) {
+	var zero int
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/server/port",
+			Token:   "server",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'server': %w", jsptr.ErrNotFound),
+		}
+	}
+	if doc.Server == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/server/port",
+			Prefix:  "/server",
+			Token:   "port",
+			Err:     fmt.Errorf("cannot index into nil *Server with 'port': %w", jsptr.ErrNotFound),
+		}
+	}
+	return doc.Server.Port, nil
+}
+
+// GetFirstTag returns the value at "/tags/0" in doc.
+func GetFirstTag(doc *Config) (string, error) {
+	var zero string
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/tags/0",
+			Token:   "tags",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'tags': %w", jsptr.ErrNotFound),
+		}
+	}
+	if len(doc.Tags) <= 0 {
+		return zero, &jsptr.Error{
+			Pattern: "/tags/0",
+			Prefix:  "/tags",
+			Token:   "0",
+			Err:     fmt.Errorf("array index 0 out of bounds: %w", jsptr.ErrNotFound),
+		}
+	}
+	return doc.Tags[0], nil
+}
+
+// GetLabel returns the value at "/labels/team" in doc.
+func GetLabel(doc *Config) (string, error) {
+	var zero string
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/labels/team",
+			Token:   "labels",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'labels': %w", jsptr.ErrNotFound),
+		}
+	}
+	v1, ok := doc.Labels[Label("team")]
+	if !ok {
+		return zero, &jsptr.Error{
+			Pattern: "/labels/team",
+			Prefix:  "/labels",
+			Token:   "team",
+			Err:     fmt.Errorf("property 'team' not found: %w", jsptr.ErrNotFound),
+		}
+	}
+	return v1, nil
+}
+
+// GetBackendURL returns the value at "/backends/primary/url" in doc.
+func GetBackendURL(doc *Config) (string, error) {
+	var zero string
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/backends/primary/url",
+			Token:   "backends",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'backends': %w", jsptr.ErrNotFound),
+		}
+	}
+	v1, ok := doc.Backends["primary"]
+	if !ok {
+		return zero, &jsptr.Error{
+			Pattern: "/backends/primary/url",
+			Prefix:  "/backends",
+			Token:   "primary",
+			Err:     fmt.Errorf("property 'primary' not found: %w", jsptr.ErrNotFound),
+		}
+	}
+	if v1 == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/backends/primary/url",
+			Prefix:  "/backends/primary",
+			Token:   "url",
+			Err:     fmt.Errorf("cannot index into nil *Backend with 'url': %w", jsptr.ErrNotFound),
+		}
+	}
+	return v1.URL, nil
+}
+
+// GetCreated returns the value at "/created" in doc.
+func GetCreated(doc *Config) (time.Time, error) {
+	var zero time.Time
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/created",
+			Token:   "created",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'created': %w", jsptr.ErrNotFound),
+		}
+	}
+	return doc.Created, nil
+}
+
+// GetServer returns the value at "/server" in doc.
+func GetServer(doc *Config) (*Server, error) {
+	var zero *Server
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/server",
+			Token:   "server",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'server': %w", jsptr.ErrNotFound),
+		}
+	}
+	return doc.Server, nil
+}
+
+// GetOwnerEmail returns the value at "/owner/email" in doc.
+func GetOwnerEmail(doc *Config) (string, error) {
+	var zero string
+	if doc == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/owner/email",
+			Token:   "owner",
+			Err:     fmt.Errorf("cannot index into nil *Config with 'owner': %w", jsptr.ErrNotFound),
+		}
+	}
+	if doc.Owner == nil {
+		return zero, &jsptr.Error{
+			Pattern: "/owner/email",
+			Prefix:  "/owner",
+			Token:   "email",
+			Err:     fmt.Errorf("cannot index into nil *Owner with 'email': %w", jsptr.ErrNotFound),
+		}
+	}
+	return doc.Owner.Email, nil
+}
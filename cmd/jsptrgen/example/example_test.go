@@ -0,0 +1,89 @@
+package example_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/lestrrat-go/jsptr/cmd/jsptrgen/example"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedAccessors(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cfg := &example.Config{
+		Metadata: example.Metadata{Name: "prod"},
+		Server:   &example.Server{Host: "localhost", Port: 8080},
+		Tags:     []string{"a", "b"},
+		Labels:   map[example.Label]string{"team": "core"},
+		Backends: map[string]*example.Backend{"primary": {URL: "http://primary"}, "secondary": nil},
+		Created:  created,
+		Owner:    &example.Owner{Email: "owner@example.com"},
+	}
+
+	t.Run("Values", func(t *testing.T) {
+		name, err := example.GetName(cfg)
+		require.NoError(t, err)
+		require.Equal(t, "prod", name)
+
+		port, err := example.GetPort(cfg)
+		require.NoError(t, err)
+		require.Equal(t, 8080, port)
+
+		tag, err := example.GetFirstTag(cfg)
+		require.NoError(t, err)
+		require.Equal(t, "a", tag)
+
+		label, err := example.GetLabel(cfg)
+		require.NoError(t, err)
+		require.Equal(t, "core", label)
+
+		url, err := example.GetBackendURL(cfg)
+		require.NoError(t, err)
+		require.Equal(t, "http://primary", url)
+
+		c, err := example.GetCreated(cfg)
+		require.NoError(t, err)
+		require.Equal(t, created, c)
+
+		server, err := example.GetServer(cfg)
+		require.NoError(t, err)
+		require.Same(t, cfg.Server, server)
+
+		email, err := example.GetOwnerEmail(cfg)
+		require.NoError(t, err)
+		require.Equal(t, "owner@example.com", email)
+	})
+
+	t.Run("Missing locations", func(t *testing.T) {
+		empty := &example.Config{Backends: map[string]*example.Backend{"primary": nil}}
+		testcases := []struct {
+			Name string
+			Get  func(*example.Config) error
+			Spec string
+		}{
+			{Name: "nil struct pointer", Get: func(c *example.Config) error { _, err := example.GetPort(c); return err }, Spec: "/server/port"},
+			{Name: "empty slice", Get: func(c *example.Config) error { _, err := example.GetFirstTag(c); return err }, Spec: "/tags/0"},
+			{Name: "nil map", Get: func(c *example.Config) error { _, err := example.GetLabel(c); return err }, Spec: "/labels/team"},
+			{Name: "nil map element", Get: func(c *example.Config) error { _, err := example.GetBackendURL(c); return err }, Spec: "/backends/primary/url"},
+			{Name: "nil embedded pointer", Get: func(c *example.Config) error { _, err := example.GetOwnerEmail(c); return err }, Spec: "/owner/email"},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.Name, func(t *testing.T) {
+				err := tc.Get(empty)
+				require.ErrorIs(t, err, jsptr.ErrNotFound)
+
+				// The error is the same as the one reported by jsptr
+				var expected, actual *jsptr.Error
+				require.ErrorAs(t, err, &actual)
+				var v any
+				require.ErrorAs(t, jsptr.MustNew(tc.Spec).Retrieve(&v, empty), &expected)
+				require.Equal(t, expected.Pattern, actual.Pattern)
+				require.Equal(t, expected.Prefix, actual.Prefix)
+				require.Equal(t, expected.Token, actual.Token)
+
+				require.ErrorIs(t, tc.Get(nil), jsptr.ErrNotFound)
+			})
+		}
+	})
+}
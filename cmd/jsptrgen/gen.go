@@ -0,0 +1,539 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// accessor is a function to generate
+type accessor struct {
+	name string
+	spec string
+}
+
+// typeDecl is a type declared in the package, along with the file that
+// declares it, which is needed to resolve the imports it refers to
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+// astPackage holds the type declarations of a package
+type astPackage struct {
+	name  string
+	fset  *token.FileSet
+	types map[string]typeDecl
+}
+
+// loadPackage parses the non-test Go files in dir, except for skip
+func loadPackage(dir, skip string) (*astPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package directory: %w", err)
+	}
+
+	pkg := &astPackage{fset: token.NewFileSet(), types: make(map[string]typeDecl)}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		filename := filepath.Join(dir, name)
+		if skip != "" && filepath.Clean(filename) == filepath.Clean(skip) {
+			continue
+		}
+
+		file, err := parser.ParseFile(pkg.fset, filename, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		if pkg.name == "" {
+			pkg.name = file.Name.Name
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				pkg.types[ts.Name.Name] = typeDecl{spec: ts, file: file}
+			}
+		}
+	}
+	if pkg.name == "" {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+	return pkg, nil
+}
+
+// generator generates the source of accessors
+type generator struct {
+	pkg *astPackage
+	tag string
+
+	buf bytes.Buffer
+	// imports holds the paths of the packages used by the generated code,
+	// keyed by their import path, with their names as values
+	imports map[string]string
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// generate returns the formatted source of the accessors for the named type
+func (g *generator) generate(typeName string, accessors []accessor) ([]byte, error) {
+	decl, ok := g.pkg.types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", typeName)
+	}
+	if decl.spec.TypeParams != nil {
+		return nil, fmt.Errorf("type %s is generic, which is not supported", typeName)
+	}
+
+	g.buf.Reset()
+	g.imports = make(map[string]string)
+	for _, acc := range accessors {
+		if err := g.accessor(typeName, decl, acc); err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", acc.name, err)
+		}
+	}
+	body := g.buf.String()
+
+	g.buf.Reset()
+	g.printf("// Code generated by jsptrgen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg.name)
+	if len(g.imports) > 0 {
+		// Standard library packages are listed first, in their own group
+		paths := slices.SortedFunc(maps.Keys(g.imports), func(a, b string) int {
+			if sa, sb := isStdlib(a), isStdlib(b); sa != sb {
+				if sa {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a, b)
+		})
+		g.printf("import (\n")
+		for i, p := range paths {
+			if i > 0 && isStdlib(paths[i-1]) && !isStdlib(p) {
+				g.printf("\n")
+			}
+			if name := g.imports[p]; name != path.Base(p) {
+				g.printf("%s %q\n", name, p)
+			} else {
+				g.printf("%q\n", p)
+			}
+		}
+		g.printf(")\n")
+	}
+	g.buf.WriteString(body)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// accessor generates the function for acc
+func (g *generator) accessor(typeName string, decl typeDecl, acc accessor) error {
+	if !token.IsIdentifier(acc.name) {
+		return fmt.Errorf("invalid function name %q", acc.name)
+	}
+	ptr, err := jsptr.New(acc.spec)
+	if err != nil {
+		return err
+	}
+	tokens := ptr.Tokens()
+
+	// Each step is generated before the signature, as the type of the
+	// result is only known once the path has been followed
+	var body bytes.Buffer
+	w := &stepWriter{g: g, buf: &body, spec: acc.spec, tokens: tokens}
+
+	cur := &ast.StarExpr{X: ast.NewIdent(typeName)}
+	loc := location{expr: cur, file: decl.file, ref: "doc"}
+	for i, tok := range tokens {
+		loc, err = w.step(loc, i, tok)
+		if err != nil {
+			return err
+		}
+	}
+
+	result := g.typeString(loc.expr, loc.file)
+	g.printf("\n// %s returns the value at %q in doc.\n", acc.name, acc.spec)
+	g.printf("func %s(doc *%s) (%s, error) {\n", acc.name, typeName, result)
+	if w.fallible {
+		g.printf("var zero %s\n", result)
+	}
+	g.buf.Write(body.Bytes())
+	g.printf("return %s, nil\n}\n", loc.ref)
+	return nil
+}
+
+// location describes the value reached after following some of the
+// tokens of a pointer
+type location struct {
+	// expr is the type of the value, as written in its declaration
+	expr ast.Expr
+	// file is the file where expr appears
+	file *ast.File
+	// ref is the Go expression that evaluates to the value
+	ref string
+}
+
+// stepWriter writes the code that follows the tokens of a pointer
+type stepWriter struct {
+	g      *generator
+	buf    *bytes.Buffer
+	spec   string
+	tokens []string
+	vars   int
+	// fallible is true if the generated code can fail
+	fallible bool
+}
+
+func (w *stepWriter) printf(format string, args ...any) {
+	fmt.Fprintf(w.buf, format, args...)
+}
+
+// step writes the code that applies tokens[i] to the value at loc, and
+// returns the location of the resulting value
+func (w *stepWriter) step(loc location, i int, tok string) (location, error) {
+	expr, file := loc.expr, loc.file
+	ref := loc.ref
+	// Pointers are only dereferenced explicitly where Go does not do it
+	// automatically, which it does for a single pointer to a struct
+	var derefs int
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.StarExpr:
+			if derefs > 0 {
+				ref = "(*" + ref + ")"
+			}
+			w.printf("if %s == nil {\n", ref)
+			w.notFound(i, fmt.Sprintf("cannot index into nil %s with '%s'", w.g.typeString(expr, file), tok))
+			w.printf("}\n")
+			expr = e.X
+			derefs = 1
+			continue
+		case *ast.Ident:
+			if decl, ok := w.g.pkg.types[e.Name]; ok && decl.spec.TypeParams == nil {
+				expr, file = decl.spec.Type, decl.file
+				continue
+			}
+		}
+		break
+	}
+
+	if _, ok := expr.(*ast.StructType); !ok && derefs > 0 {
+		ref = "(*" + ref + ")"
+	}
+
+	switch e := expr.(type) {
+	case *ast.StructType:
+		field, ok := w.g.lookupField(e, file, tok)
+		if !ok {
+			return location{}, fmt.Errorf("field '%s' not found in %s", tok, w.g.typeString(loc.expr, loc.file))
+		}
+		for _, hop := range field.embedded {
+			ref = ref + "." + hop.name
+			if hop.pointer {
+				w.printf("if %s == nil {\n", ref)
+				w.notFound(i, fmt.Sprintf("cannot access field '%s' through nil embedded %s", tok, hop.name))
+				w.printf("}\n")
+			}
+		}
+		return location{expr: field.typ, file: field.file, ref: ref + "." + field.name}, nil
+	case *ast.ArrayType:
+		index, err := strconv.Atoi(tok)
+		if err != nil || index < 0 || strconv.Itoa(index) != tok {
+			return location{}, fmt.Errorf("invalid array index '%s'", tok)
+		}
+		w.printf("if len(%s) <= %d {\n", ref, index)
+		w.notFound(i, fmt.Sprintf("array index %d out of bounds", index))
+		w.printf("}\n")
+		return location{expr: e.Elt, file: file, ref: fmt.Sprintf("%s[%d]", ref, index)}, nil
+	case *ast.MapType:
+		key, ok := w.g.mapKey(e.Key, file, tok)
+		if !ok {
+			return location{}, fmt.Errorf("cannot index into non-string-keyed map %s", w.g.typeString(expr, file))
+		}
+		w.vars++
+		v := "v" + strconv.Itoa(w.vars)
+		w.printf("%s, ok := %s[%s]\n", v, ref, key)
+		w.printf("if !ok {\n")
+		w.notFound(i, fmt.Sprintf("property '%s' not found", tok))
+		w.printf("}\n")
+		return location{expr: e.Value, file: file, ref: v}, nil
+	default:
+		return location{}, fmt.Errorf("cannot index into %s with '%s', as its contents are not known until run time", w.g.typeString(loc.expr, loc.file), tok)
+	}
+}
+
+// notFound writes a statement that returns an error matching
+// jsptr.ErrNotFound for the failure to apply tokens[i]
+func (w *stepWriter) notFound(i int, msg string) {
+	w.fallible = true
+	w.g.imports["fmt"] = "fmt"
+	w.g.imports["github.com/lestrrat-go/jsptr"] = "jsptr"
+
+	format := strings.ReplaceAll(msg, "%", "%%") + ": %w"
+	w.printf("return zero, &jsptr.Error{\n")
+	w.printf("Pattern: %q,\n", w.spec)
+	if i > 0 {
+		w.printf("Prefix: %q,\n", joinTokens(w.tokens[:i]))
+	}
+	w.printf("Token: %q,\n", w.tokens[i])
+	w.printf("Err: fmt.Errorf(%q, jsptr.ErrNotFound),\n", format)
+	w.printf("}\n")
+}
+
+// mapKey returns the Go expression for the key tok of a map whose keys
+// are of type key, if the keys are strings
+func (g *generator) mapKey(key ast.Expr, file *ast.File, tok string) (string, bool) {
+	ident, ok := key.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if ident.Name == "string" {
+		return strconv.Quote(tok), true
+	}
+	decl, ok := g.pkg.types[ident.Name]
+	if !ok {
+		return "", false
+	}
+	if under, ok := decl.spec.Type.(*ast.Ident); !ok || under.Name != "string" {
+		return "", false
+	}
+	return fmt.Sprintf("%s(%q)", ident.Name, tok), true
+}
+
+// typeString returns the Go source of the type expr, which appears in
+// file, and records the imports that it needs
+func (g *generator) typeString(expr ast.Expr, file *ast.File) string {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if p, ok := importPath(file, x.Name); ok {
+				g.imports[p] = x.Name
+			}
+		}
+		return false
+	})
+
+	var sb strings.Builder
+	_ = printer.Fprint(&sb, g.pkg.fset, expr)
+	return sb.String()
+}
+
+// importPath returns the path of the package imported by file as name
+func importPath(file *ast.File, name string) (string, bool) {
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return p, true
+			}
+			continue
+		}
+		if packageName(p) == name {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// isStdlib reports whether p is the import path of a standard library
+// package, which is the case if its first element has no dots
+func isStdlib(p string) bool {
+	first, _, _ := strings.Cut(p, "/")
+	return !strings.Contains(first, ".")
+}
+
+// packageName guesses the name of the package at the import path p,
+// which is usually the last element of the path, ignoring major versions
+func packageName(p string) string {
+	base := path.Base(p)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(p))
+	}
+	return strings.TrimPrefix(base, "go-")
+}
+
+// joinTokens creates a pointer specification from reference tokens
+func joinTokens(tokens []string) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		tok = strings.ReplaceAll(tok, "~", "~0")
+		sb.WriteString(strings.ReplaceAll(tok, "/", "~1"))
+	}
+	return sb.String()
+}
+
+// structField is a field of a struct, as seen by encoding/json
+type structField struct {
+	name     string
+	jsonName string
+	tagged   bool
+	typ      ast.Expr
+	file     *ast.File
+	// embedded lists the embedded fields that the field is promoted
+	// through, outermost first
+	embedded []embeddedHop
+}
+
+type embeddedHop struct {
+	name    string
+	pointer bool
+}
+
+// lookupField returns the field of st whose JSON name is name, using the
+// same rules as encoding/json to resolve promoted fields
+func (g *generator) lookupField(st *ast.StructType, file *ast.File, name string) (*structField, bool) {
+	type embedded struct {
+		st   *ast.StructType
+		file *ast.File
+		path []embeddedHop
+	}
+
+	visited := make(map[*ast.StructType]bool)
+	next := []embedded{{st: st, file: file}}
+	for len(next) > 0 {
+		current := next
+		next = nil
+
+		// Fields at the same depth conflict with each other, unless a
+		// single one of them is named by its tag
+		var candidates []*structField
+		var explored []*ast.StructType
+		for _, e := range current {
+			if visited[e.st] {
+				continue
+			}
+			explored = append(explored, e.st)
+
+			for _, field := range e.st.Fields.List {
+				tagName, _ := g.fieldTag(field)
+				if tagName == "-" {
+					continue
+				}
+				if len(field.Names) == 0 {
+					typeName, pointer := embeddedName(field.Type)
+					if tagName == "" {
+						if decl, ok := g.pkg.types[typeName]; ok {
+							if est, ok := decl.spec.Type.(*ast.StructType); ok {
+								hop := embeddedHop{name: typeName, pointer: pointer}
+								next = append(next, embedded{st: est, file: decl.file, path: append(slices.Clip(e.path), hop)})
+								continue
+							}
+						}
+					}
+					if !ast.IsExported(typeName) {
+						continue
+					}
+					candidates = appendCandidate(candidates, name, &structField{name: typeName, jsonName: typeName, typ: field.Type, file: e.file, embedded: e.path}, tagName)
+					continue
+				}
+				for _, ident := range field.Names {
+					if !ast.IsExported(ident.Name) {
+						continue
+					}
+					candidates = appendCandidate(candidates, name, &structField{name: ident.Name, jsonName: ident.Name, typ: field.Type, file: e.file, embedded: e.path}, tagName)
+				}
+			}
+		}
+		for _, st := range explored {
+			visited[st] = true
+		}
+
+		switch len(candidates) {
+		case 0:
+		case 1:
+			return candidates[0], true
+		default:
+			var tagged []*structField
+			for _, c := range candidates {
+				if c.tagged {
+					tagged = append(tagged, c)
+				}
+			}
+			if len(tagged) == 1 {
+				return tagged[0], true
+			}
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// appendCandidate appends field to candidates if its JSON name, which is
+// given by tagName if it is not empty, is name
+func appendCandidate(candidates []*structField, name string, field *structField, tagName string) []*structField {
+	if tagName != "" {
+		field.jsonName, field.tagged = tagName, true
+	}
+	if field.jsonName != name {
+		return candidates
+	}
+	return append(candidates, field)
+}
+
+// fieldTag returns the name and the options given by the struct tag of field
+func (g *generator) fieldTag(field *ast.Field) (string, string) {
+	if field.Tag == nil {
+		return "", ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", ""
+	}
+	name, opts, _ := strings.Cut(reflect.StructTag(tag).Get(g.tag), ",")
+	return name, opts
+}
+
+// embeddedName returns the name of the type of an embedded field, and
+// whether it is embedded through a pointer
+func embeddedName(expr ast.Expr) (string, bool) {
+	var pointer bool
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, pointer = star.X, true
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name, pointer
+	case *ast.SelectorExpr:
+		return e.Sel.Name, pointer
+	case *ast.IndexExpr:
+		name, _ := embeddedName(e.X)
+		return name, pointer
+	}
+	return "", pointer
+}
@@ -0,0 +1,116 @@
+// Command jsptrgen generates functions that retrieve the values at fixed
+// JSON pointer locations within Go structs, without using reflection.
+//
+// Usage:
+//
+//	jsptrgen -type <name> [flags] <func>=<pointer>...
+//
+// For each <func>=<pointer> argument, a function named <func> is generated
+// that takes a pointer to the type <name>, and returns the value at the
+// location specified by <pointer> along with an error. Struct fields are
+// found by their JSON names, as they are by jsptr. Locations that do not
+// exist, such as those reached through nil pointers, missing map keys or
+// out of range indices, are reported as a *jsptr.Error that matches
+// jsptr.ErrNotFound.
+//
+// The types along the path must be declared in the package, except for
+// the type of the value at the end of the path, which may be any type.
+// Interfaces can not be traversed, as their contents are only known at
+// run time.
+//
+// jsptrgen is meant to be run by go generate, from the directory of the
+// package that declares the type:
+//
+//	//go:generate go run github.com/lestrrat-go/jsptr/cmd/jsptrgen -type Config GetPort=/server/port
+//
+// The flags are:
+//
+//	-type    the name of the type that pointers are evaluated against
+//	-dir     the directory of the package (default ".")
+//	-output  the file to write, or "-" for the standard output
+//	         (default "<type>_jsptr.go" in the package directory)
+//	-tag     the name of the struct tag that names fields (default "json")
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command with the given arguments, and returns the
+// exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("jsptrgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsptrgen -type <name> [flags] <func>=<pointer>...")
+		fs.PrintDefaults()
+	}
+	typeName := fs.String("type", "", "the name of the type that pointers are evaluated against")
+	dir := fs.String("dir", ".", "the directory of the package")
+	output := fs.String("output", "", `the file to write, or "-" for the standard output`)
+	tag := fs.String("tag", "json", "the name of the struct tag that names fields")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *typeName == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	accessors := make([]accessor, fs.NArg())
+	for i, arg := range fs.Args() {
+		name, spec, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			fmt.Fprintf(stderr, "jsptrgen: invalid accessor %q: expected <func>=<pointer>\n", arg)
+			return 2
+		}
+		accessors[i] = accessor{name: name, spec: spec}
+	}
+
+	if *output == "" {
+		*output = filepath.Join(*dir, strings.ToLower(*typeName)+"_jsptr.go")
+	}
+	if err := generate(*dir, *typeName, *tag, accessors, *output, stdout); err != nil {
+		fmt.Fprintf(stderr, "jsptrgen: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// generate writes the accessors for the named type, declared in the
+// package in dir, to output
+func generate(dir, typeName, tag string, accessors []accessor, output string, stdout io.Writer) error {
+	// The output is skipped when loading the package, so that accessors
+	// can be regenerated even if the previous ones no longer compile
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+	g := &generator{pkg: pkg, tag: tag}
+	src, err := g.generate(typeName, accessors)
+	if err != nil {
+		return err
+	}
+
+	if output == "-" {
+		_, err := stdout.Write(src)
+		return err
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
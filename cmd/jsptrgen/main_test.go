@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// invoke runs the command, and returns its exit code and outputs
+func invoke(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr strings.Builder
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestExample checks that the accessors of the example package are up to
+// date. Run "go generate ./cmd/jsptrgen/example" to update them
+func TestExample(t *testing.T) {
+	args := []string{
+		"-type", "Config",
+		"-dir", "example",
+		"-output", "-",
+		"GetName=/name",
+		"GetPort=/server/port",
+		"GetFirstTag=/tags/0",
+		"GetLabel=/labels/team",
+		"GetBackendURL=/backends/primary/url",
+		"GetCreated=/created",
+		"GetServer=/server",
+		"GetOwnerEmail=/owner/email",
+	}
+	code, stdout, stderr := invoke(t, args...)
+	require.Equal(t, 0, code, stderr)
+
+	expected, err := os.ReadFile(filepath.Join("example", "config_jsptr.go"))
+	require.NoError(t, err)
+	require.Equal(t, string(expected), stdout)
+}
+
+func TestGenerate(t *testing.T) {
+	const src = `package model
+
+import (
+	"encoding/json"
+	xtime "time"
+)
+
+type Root struct {
+	Inner
+	Deep    **Leaf            ` + "`json:\"deep\"`" + `
+	Grid    [][2]int          ` + "`json:\"grid\"`" + `
+	Raw     json.RawMessage   ` + "`json:\"raw\"`" + `
+	When    map[string]xtime.Time ` + "`json:\"when\"`" + `
+	Any     any               ` + "`json:\"any\"`" + `
+	Skipped string            ` + "`json:\"-\"`" + `
+	Custom  string            ` + "`yaml:\"custom\"`" + `
+	hidden  string
+}
+
+type Inner struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Leaf struct {
+	Value string
+}
+`
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "model.go"), []byte(src), 0o644))
+
+	testcases := []struct {
+		Name     string
+		Args     []string
+		Code     int
+		Contains []string
+		Stderr   string
+	}{
+		{
+			Name:     "Promoted field",
+			Args:     []string{"GetName=/name"},
+			Contains: []string{"func GetName(doc *Root) (string, error)", "return doc.Inner.Name, nil"},
+		},
+		{
+			Name: "Pointer to pointer",
+			Args: []string{"GetValue=/deep/Value"},
+			Contains: []string{
+				"if doc.Deep == nil",
+				"if (*doc.Deep) == nil",
+				"return (*doc.Deep).Value, nil",
+			},
+		},
+		{
+			Name:     "Nested arrays",
+			Args:     []string{"GetCell=/grid/1/0"},
+			Contains: []string{"if len(doc.Grid[1]) <= 0", "return doc.Grid[1][0], nil"},
+		},
+		{
+			Name:     "Renamed import",
+			Args:     []string{"GetWhen=/when/start"},
+			Contains: []string{`xtime "time"`, "func GetWhen(doc *Root) (xtime.Time, error)"},
+		},
+		{
+			Name:     "Value at the end of the path",
+			Args:     []string{"GetRaw=/raw", "GetAny=/any"},
+			Contains: []string{"(json.RawMessage, error)", "(any, error)"},
+		},
+		{
+			Name:     "Root",
+			Args:     []string{"GetRoot="},
+			Contains: []string{"func GetRoot(doc *Root) (*Root, error) {\n\treturn doc, nil\n}"},
+		},
+		{
+			Name:     "Other tag",
+			Args:     []string{"-tag", "yaml", "GetCustom=/custom"},
+			Contains: []string{"return doc.Custom, nil"},
+		},
+		{Name: "Through an interface", Args: []string{"GetAny=/any/x"}, Code: 1, Stderr: "contents are not known until run time"},
+		{Name: "Through an external type", Args: []string{"GetRaw=/raw/0"}, Code: 1, Stderr: "cannot index into json.RawMessage"},
+		{Name: "Ignored field", Args: []string{"GetSkipped=/Skipped"}, Code: 1, Stderr: "field 'Skipped' not found"},
+		{Name: "Unexported field", Args: []string{"GetHidden=/hidden"}, Code: 1, Stderr: "field 'hidden' not found"},
+		{Name: "Invalid index", Args: []string{"GetCell=/grid/-"}, Code: 1, Stderr: "invalid array index '-'"},
+		{Name: "Invalid pointer", Args: []string{"GetName=name"}, Code: 1, Stderr: "must start with '/'"},
+		{Name: "Invalid function name", Args: []string{"Get-Name=/name"}, Code: 1, Stderr: "invalid function name"},
+		{Name: "Invalid accessor", Args: []string{"/name"}, Code: 2, Stderr: "expected <func>=<pointer>"},
+		{Name: "No accessors", Args: []string{}, Code: 2, Stderr: "usage: jsptrgen"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			args := append([]string{"-type", "Root", "-dir", dir, "-output", "-"}, tc.Args...)
+			code, stdout, stderr := invoke(t, args...)
+			require.Equal(t, tc.Code, code, stderr)
+			require.Contains(t, stderr, tc.Stderr)
+			for _, s := range tc.Contains {
+				require.Contains(t, stdout, s)
+			}
+		})
+	}
+
+	t.Run("Unknown type", func(t *testing.T) {
+		code, _, stderr := invoke(t, "-type", "Missing", "-dir", dir, "GetName=/name")
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "type Missing not found")
+	})
+	t.Run("Output file", func(t *testing.T) {
+		code, _, stderr := invoke(t, "-type", "Root", "-dir", dir, "GetName=/name")
+		require.Equal(t, 0, code, stderr)
+		src, err := os.ReadFile(filepath.Join(dir, "root_jsptr.go"))
+		require.NoError(t, err)
+		require.Contains(t, string(src), "// Code generated by jsptrgen. DO NOT EDIT.")
+
+		// The previous output is ignored when regenerating
+		code, _, stderr = invoke(t, "-type", "Root", "-dir", dir, "GetName=/name")
+		require.Equal(t, 0, code, stderr)
+	})
+}
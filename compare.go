@@ -0,0 +1,180 @@
+package jsptr
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Equal returns true if both pointers refer to the same location.
+// The comparison is done on the unescaped reference tokens, so
+// differently spelled but equivalent patterns are considered equal.
+func (p *Pointer) Equal(other *Pointer) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	if len(p.tokens) != len(other.tokens) {
+		return false
+	}
+	for i, token := range p.tokens {
+		if other.tokens[i] != token {
+			return false
+		}
+	}
+	return true
+}
+
+// HasPrefix returns true if the reference tokens of `prefix` are a
+// leading subsequence of the tokens in p. A pointer always has itself
+// as a prefix, and every pointer has the empty pointer as a prefix.
+//
+// Unlike strings.HasPrefix, this method compares whole tokens, so
+// "/foo" is not considered a prefix of "/foobar"
+func (p *Pointer) HasPrefix(prefix *Pointer) bool {
+	if p == nil || prefix == nil {
+		return false
+	}
+	if len(prefix.tokens) > len(p.tokens) {
+		return false
+	}
+	for i, token := range prefix.tokens {
+		if p.tokens[i] != token {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAncestorOf returns true if `other` points to a location strictly
+// under the location referred to by p (e.g. "/a" is an ancestor of "/a/b",
+// but not of "/a" itself)
+func (p *Pointer) IsAncestorOf(other *Pointer) bool {
+	if p == nil || other == nil {
+		return false
+	}
+	return len(p.tokens) < len(other.tokens) && other.HasPrefix(p)
+}
+
+// ComparePointers compares pointers in document order, and returns -1, 0
+// or +1 depending on whether a sorts before, at the same position as, or
+// after b. It can be used with slices.SortFunc.
+//
+// Pointers are compared token by token. Tokens that are array indices are
+// compared by their numeric value, so that "/a/2" sorts before "/a/10",
+// and sort before "-" and all other tokens, which are compared as
+// strings. Only canonical indices count as indices: "01" and "+1" are
+// compared as strings. A pointer sorts before the pointers to the values
+// within its location.
+func ComparePointers(a, b *Pointer) int {
+	return comparePaths(a.tokens, b.tokens)
+}
+
+// ComparePointersByDepth compares pointers by the number of their tokens,
+// so that shallower locations sort first, and pointers of the same depth
+// in document order, as ComparePointers does. It can be used with
+// slices.SortFunc.
+func ComparePointersByDepth(a, b *Pointer) int {
+	if c := cmp.Compare(len(a.tokens), len(b.tokens)); c != 0 {
+		return c
+	}
+	return comparePaths(a.tokens, b.tokens)
+}
+
+// NormalizePointers returns the given pointers in canonical form,
+// sorted in document order, and without duplicates. Pointers that refer
+// to the same location are only returned once.
+func NormalizePointers(pointers []string) ([]string, error) {
+	paths := make([][]string, len(pointers))
+	for i, spec := range pointers {
+		tokens, err := parseTokens(spec)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = tokens
+	}
+	slices.SortFunc(paths, comparePaths)
+	paths = slices.CompactFunc(paths, slices.Equal)
+
+	result := make([]string, len(paths))
+	for i, tokens := range paths {
+		result[i] = joinTokens(tokens)
+	}
+	return result, nil
+}
+
+// CoveringPointers returns the smallest set of pointers whose locations
+// contain the locations of all the given pointers, by leaving out the
+// pointers that are below another one. For example, "/a", "/a/b" and
+// "/c" are collapsed to "/a" and "/c". The result is normalized as
+// NormalizePointers does.
+func CoveringPointers(pointers []string) ([]string, error) {
+	normalized, err := NormalizePointers(pointers)
+	if err != nil {
+		return nil, err
+	}
+
+	// In document order, pointers come right after the pointers that
+	// contain their location, if any
+	var result []string
+	for _, spec := range normalized {
+		if n := len(result); n > 0 && (result[n-1] == "" || strings.HasPrefix(spec, result[n-1]+"/")) {
+			continue
+		}
+		result = append(result, spec)
+	}
+	return result, nil
+}
+
+// comparePaths orders paths token by token using compareTokens. A path
+// sorts before the paths of its descendants
+func comparePaths(a, b []string) int {
+	for i := range min(len(a), len(b)) {
+		if c := compareTokens(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// compareTokens orders array indices by their numeric value, followed by
+// "-" (the element after the last one), followed by all other tokens in
+// lexical order. Only canonical indices are treated as numbers, so that
+// distinct tokens such as "1" and "01" never compare as equal
+func compareTokens(a, b string) int {
+	ia, oka := indexRank(a)
+	ib, okb := indexRank(b)
+	switch {
+	case oka && okb:
+		if ia != ib {
+			return cmp.Compare(ia, ib)
+		}
+		// Canonical indices of the same length are ordered lexically
+		// as they are numerically, which also handles indices that are
+		// too large for an int
+		return cmp.Compare(a, b)
+	case oka:
+		return -1
+	case okb:
+		return 1
+	}
+	return cmp.Compare(a, b)
+}
+
+// indexRank returns the rank of token among array indices, if it is a
+// canonical array index (digits only, without leading zeros) or "-".
+// Indices with fewer digits are smaller, and "-" ranks after all indices
+func indexRank(token string) (int, bool) {
+	if token == "-" {
+		return math.MaxInt, true
+	}
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return 0, false
+	}
+	for i := range len(token) {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, false
+		}
+	}
+	return len(token), true
+}
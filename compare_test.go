@@ -0,0 +1,178 @@
+package jsptr_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerComparison(t *testing.T) {
+	tests := []struct {
+		name       string
+		a          string
+		b          string
+		equal      bool
+		hasPrefix  bool // a.HasPrefix(b)
+		isAncestor bool // b.IsAncestorOf(a)
+	}{
+		{
+			name:      "identical",
+			a:         "/foo/bar",
+			b:         "/foo/bar",
+			equal:     true,
+			hasPrefix: true,
+		},
+		{
+			name:       "parent",
+			a:          "/foo/bar",
+			b:          "/foo",
+			hasPrefix:  true,
+			isAncestor: true,
+		},
+		{
+			name:       "root is ancestor of everything",
+			a:          "/foo",
+			b:          "",
+			hasPrefix:  true,
+			isAncestor: true,
+		},
+		{
+			name: "partial token is not a prefix",
+			a:    "/foobar",
+			b:    "/foo",
+		},
+		{
+			name: "escaped slash is a single token",
+			a:    "/foo~1bar",
+			b:    "/foo",
+		},
+		{
+			name:       "escaped token as prefix",
+			a:          "/foo~1bar/baz",
+			b:          "/foo~1bar",
+			hasPrefix:  true,
+			isAncestor: true,
+		},
+		{
+			name: "empty token differs from root",
+			a:    "/",
+			b:    "",
+			// "/" refers to the member named "" under the root
+			hasPrefix:  true,
+			isAncestor: true,
+		},
+		{
+			name: "siblings",
+			a:    "/foo/bar",
+			b:    "/foo/baz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := jsptr.New(tt.a)
+			require.NoError(t, err)
+			b, err := jsptr.New(tt.b)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.equal, a.Equal(b), "a.Equal(b)")
+			require.Equal(t, tt.equal, b.Equal(a), "b.Equal(a)")
+			require.Equal(t, tt.hasPrefix, a.HasPrefix(b), "a.HasPrefix(b)")
+			require.Equal(t, tt.isAncestor, b.IsAncestorOf(a), "b.IsAncestorOf(a)")
+			require.False(t, a.IsAncestorOf(a), "a.IsAncestorOf(a)")
+		})
+	}
+}
+
+func TestSortPointers(t *testing.T) {
+	specs := []string{"/b", "/a/10", "/a/2", "/a", "", "/a/2/x", "/a/-", "/a/b"}
+	ptrs := make([]*jsptr.Pointer, len(specs))
+	for i, spec := range specs {
+		ptrs[i] = jsptr.MustNew(spec)
+	}
+	patterns := func() []string {
+		result := make([]string, len(ptrs))
+		for i, ptr := range ptrs {
+			result[i] = ptr.Pattern()
+		}
+		return result
+	}
+
+	slices.SortFunc(ptrs, jsptr.ComparePointers)
+	require.Equal(t, []string{"", "/a", "/a/2", "/a/2/x", "/a/10", "/a/-", "/a/b", "/b"}, patterns())
+
+	slices.SortFunc(ptrs, jsptr.ComparePointersByDepth)
+	require.Equal(t, []string{"", "/a", "/b", "/a/2", "/a/10", "/a/-", "/a/b", "/a/2/x"}, patterns())
+}
+
+func TestNormalizePointers(t *testing.T) {
+	testcases := []struct {
+		Name       string
+		Pointers   []string
+		Normalized []string
+		Covering   []string
+	}{
+		{
+			Name:       "duplicates",
+			Pointers:   []string{"/b", "/a", "/b", "/t~", "/t~0"},
+			Normalized: []string{"/a", "/b", "/t~0"},
+			Covering:   []string{"/a", "/b", "/t~0"},
+		},
+		{
+			Name:       "nested",
+			Pointers:   []string{"/a/b/c", "/c", "/a~1b", "/a/b", "/a/b/d", "/ab"},
+			Normalized: []string{"/a/b", "/a/b/c", "/a/b/d", "/a~1b", "/ab", "/c"},
+			Covering:   []string{"/a/b", "/a~1b", "/ab", "/c"},
+		},
+		{
+			Name:       "root",
+			Pointers:   []string{"/a", "", "/b"},
+			Normalized: []string{"", "/a", "/b"},
+			Covering:   []string{""},
+		},
+		{
+			Name:       "empty tokens",
+			Pointers:   []string{"//", "/"},
+			Normalized: []string{"/", "//"},
+			Covering:   []string{"/"},
+		},
+		{
+			Name:       "mixed indices and names",
+			Pointers:   []string{"/10", "/1a", "/2", "/-", "/01", "/1", "/+1", "/b", "/99999999999999999999"},
+			Normalized: []string{"/1", "/2", "/10", "/99999999999999999999", "/-", "/+1", "/01", "/1a", "/b"},
+			Covering:   []string{"/1", "/2", "/10", "/99999999999999999999", "/-", "/+1", "/01", "/1a", "/b"},
+		},
+		{
+			Name:       "none",
+			Normalized: []string{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			normalized, err := jsptr.NormalizePointers(tc.Pointers)
+			require.NoError(t, err)
+			require.Equal(t, tc.Normalized, normalized)
+
+			// The result does not depend on the order of the input
+			shuffled := slices.Clone(tc.Pointers)
+			for range 20 {
+				rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+				normalized, err := jsptr.NormalizePointers(shuffled)
+				require.NoError(t, err)
+				require.Equal(t, tc.Normalized, normalized, shuffled)
+			}
+
+			covering, err := jsptr.CoveringPointers(tc.Pointers)
+			require.NoError(t, err)
+			require.Equal(t, tc.Covering, covering)
+		})
+	}
+
+	_, err := jsptr.NormalizePointers([]string{"/a", "b"})
+	require.Error(t, err)
+	_, err = jsptr.CoveringPointers([]string{"b"})
+	require.Error(t, err)
+}
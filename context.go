@@ -0,0 +1,60 @@
+package jsptr
+
+import (
+	"context"
+	"io"
+)
+
+// SourceContext is an optional interface for sources that perform I/O,
+// such as sources backed by a database or a remote service. When values
+// are retrieved using RetrieveContext, the context is passed to such
+// sources so that they can honor cancellation and deadlines.
+type SourceContext interface {
+	Source
+	RetrieveJSONPointerContext(ctx context.Context, dst any, ptrspec string) error
+}
+
+// RetrieveContext works like Retrieve, but takes a context that is passed
+// to sources implementing SourceContext, including sources found within
+// the target. If target is an io.Reader, reading stops with the error of
+// the context once it is done.
+//
+// The context is checked before the retrieval starts, and before each
+// source is consulted, but the traversal of values that are already in
+// memory is not interrupted.
+func (p *Pointer) RetrieveContext(ctx context.Context, dst any, target any, options ...RetrieveOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	cfg := *newRetrieveConfig(options)
+	cfg.ctx = ctx
+	return p.retrieveWithConfig(dst, target, &cfg)
+}
+
+// callSource retrieves the value at ptrspec from source, passing along
+// the context of cfg if there is one
+func callSource(dst any, source Source, ptrspec string, cfg *retrieveConfig) error {
+	if cfg.ctx == nil {
+		return source.RetrieveJSONPointer(dst, ptrspec)
+	}
+	if err := cfg.ctx.Err(); err != nil {
+		return err
+	}
+	if sc, ok := source.(SourceContext); ok {
+		return sc.RetrieveJSONPointerContext(cfg.ctx, dst, ptrspec)
+	}
+	return source.RetrieveJSONPointer(dst, ptrspec)
+}
+
+// contextReader stops reading once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
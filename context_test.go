@@ -0,0 +1,97 @@
+package jsptr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/blackmagic"
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteSource simulates a source that fetches values over the network
+type remoteSource struct {
+	latency time.Duration
+	calls   int
+}
+
+func (s *remoteSource) RetrieveJSONPointer(dst any, ptrspec string) error {
+	return s.RetrieveJSONPointerContext(context.Background(), dst, ptrspec)
+}
+
+func (s *remoteSource) RetrieveJSONPointerContext(ctx context.Context, dst any, ptrspec string) error {
+	s.calls++
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(s.latency):
+	}
+	if ptrspec != "/value" {
+		return errors.New("not found")
+	}
+	return blackmagic.AssignIfCompatible(dst, "remote")
+}
+
+func TestPointerRetrieveContext(t *testing.T) {
+	ptr, err := jsptr.New("/remote/value")
+	require.NoError(t, err)
+
+	t.Run("nested sources receive the context", func(t *testing.T) {
+		src := &remoteSource{latency: time.Hour}
+		target := map[string]any{"remote": src}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var v string
+		require.ErrorIs(t, ptr.RetrieveContext(ctx, &v, target), context.DeadlineExceeded)
+		require.Equal(t, 1, src.calls)
+	})
+	t.Run("successful retrieval", func(t *testing.T) {
+		var v string
+		require.NoError(t, ptr.RetrieveContext(context.Background(), &v, map[string]any{"remote": &remoteSource{}}))
+		require.Equal(t, "remote", v)
+	})
+	t.Run("canceled before the retrieval", func(t *testing.T) {
+		src := &remoteSource{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var v string
+		require.ErrorIs(t, ptr.RetrieveContext(ctx, &v, map[string]any{"remote": src}), context.Canceled)
+		require.Zero(t, src.calls, "sources are not consulted")
+	})
+	t.Run("readers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		// The context is canceled while the document is being read
+		r := io.MultiReader(
+			strings.NewReader(`{"remote": {"other": 1,`),
+			readerFunc(func([]byte) (int, error) {
+				cancel()
+				return 0, nil
+			}),
+			strings.NewReader(`"value": "local"}}`),
+		)
+
+		var v string
+		require.ErrorIs(t, ptr.RetrieveContext(ctx, &v, r), context.Canceled)
+	})
+	t.Run("options", func(t *testing.T) {
+		ptr, err := jsptr.New("/n")
+		require.NoError(t, err)
+
+		var v any
+		require.NoError(t, ptr.RetrieveContext(context.Background(), &v, `{"n": 1}`, jsptr.WithNumberMode(jsptr.NumberInt64)))
+		require.Equal(t, int64(1), v)
+	})
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
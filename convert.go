@@ -0,0 +1,76 @@
+package jsptr
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Converters is a registry of functions that convert retrieved values
+// into Go types that cannot be populated by a plain assignment, such as
+// a geo.Point built from a {"lat": ..., "lng": ...} object. Use
+// WithConverters to consult the registry when retrieving values.
+//
+// The zero value is ready to use, and a Converters can be used from
+// multiple goroutines.
+type Converters struct {
+	mu    sync.RWMutex
+	funcs map[reflect.Type]func(dst, v any) error
+}
+
+// RegisterConverter registers fn as the converter for values retrieved
+// into a *T. fn receives the value at the location, in the same form as
+// it would be retrieved into an *any: JSON objects are passed as
+// map[string]any, arrays as []any, and so on. JSON null is passed as nil.
+//
+// Registering a converter for a type that already has one replaces it.
+func RegisterConverter[T any](c *Converters, fn func(v any) (T, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.funcs == nil {
+		c.funcs = make(map[reflect.Type]func(dst, v any) error)
+	}
+	c.funcs[reflect.TypeFor[T]()] = func(dst, v any) error {
+		result, err := fn(v)
+		if err != nil {
+			return err
+		}
+		*(dst.(*T)) = result
+		return nil
+	}
+}
+
+// lookup returns the converter for dst, which must be a pointer to
+// the type the converter was registered for
+func (c *Converters) lookup(dst any) func(dst, v any) error {
+	if c == nil {
+		return nil
+	}
+	t := reflect.TypeOf(dst)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.funcs[t.Elem()]
+}
+
+// retrieveConverted calls fn to retrieve a value into dst. If a converter
+// is registered for dst, the value is retrieved as a generic value first,
+// and then handed to the converter
+func retrieveConverted(dst any, cfg *retrieveConfig, fn func(dst any) error) error {
+	convert := cfg.converters.lookup(dst)
+	if convert == nil {
+		return fn(dst)
+	}
+
+	var v any
+	if err := fn(&v); err != nil {
+		return err
+	}
+	if err := convert(dst, v); err != nil {
+		return fmt.Errorf("failed to convert value into %T: %w", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package jsptr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+type geoPoint struct {
+	Lat, Lng float64
+}
+
+func TestConverters(t *testing.T) {
+	var converters jsptr.Converters
+	jsptr.RegisterConverter(&converters, func(v any) (geoPoint, error) {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return geoPoint{}, errors.New("expected an object")
+		}
+		lat, _ := m["lat"].(float64)
+		lng, _ := m["lng"].(float64)
+		return geoPoint{Lat: lat, Lng: lng}, nil
+	})
+
+	const src = `{"office": {"location": {"lat": 35.68, "lng": 139.76}}, "name": "tokyo"}`
+
+	for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+		t.Run(name, func(t *testing.T) {
+			var p geoPoint
+			require.NoError(t, jsptr.MustNew("/office/location").Retrieve(&p, target, jsptr.WithConverters(&converters)))
+			require.Equal(t, geoPoint{Lat: 35.68, Lng: 139.76}, p)
+
+			// Converter errors are reported
+			require.Error(t, jsptr.MustNew("/name").Retrieve(&p, target, jsptr.WithConverters(&converters)))
+
+			// Missing locations are reported without calling the converter
+			require.ErrorIs(t, jsptr.MustNew("/nope").Retrieve(&p, target, jsptr.WithConverters(&converters)), jsptr.ErrNotFound)
+
+			// Types without converters are assigned as usual
+			var s string
+			require.NoError(t, jsptr.MustNew("/name").Retrieve(&s, target, jsptr.WithConverters(&converters)))
+			require.Equal(t, "tokyo", s)
+		})
+	}
+
+	t.Run("Document", func(t *testing.T) {
+		doc, err := jsptr.ParseJSON([]byte(src))
+		require.NoError(t, err)
+
+		var p geoPoint
+		require.NoError(t, doc.Retrieve(&p, "/office/location", jsptr.WithConverters(&converters)))
+		require.Equal(t, geoPoint{Lat: 35.68, Lng: 139.76}, p)
+	})
+}
@@ -0,0 +1,343 @@
+package jsptr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strconv"
+)
+
+// Diff compares two documents, and returns a JSON Patch (RFC 6902) that
+// transforms a into b when applied to it.
+//
+// a and b may be JSON text as []byte or string, a *Document, or Go values,
+// which are compared by their JSON representation. Numbers are compared
+// by value, and the order of object members is not significant. Values in
+// the patch are materialized as if they were retrieved with the given
+// options.
+//
+// Arrays are compared element by element: elements are replaced in place,
+// and elements are added or removed at the end of the shorter array.
+func Diff(a, b any, options ...RetrieveOption) (Patch, error) {
+	changes, err := Changes(a, b, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch Patch
+	for _, c := range changes {
+		switch c.Kind {
+		case ChangeAdded:
+			patch = append(patch, Operation{Op: "add", Path: c.Path, Value: c.New})
+		case ChangeRemoved:
+			patch = append(patch, Operation{Op: "remove", Path: c.Path})
+		case ChangeModified:
+			patch = append(patch, Operation{Op: "replace", Path: c.Path, Value: c.New})
+		}
+	}
+	return patch, nil
+}
+
+// ChangeKind is the kind of a Change
+type ChangeKind int
+
+const (
+	// ChangeAdded means that a value was added
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved means that a value was removed
+	ChangeRemoved
+	// ChangeModified means that a value was replaced with another one
+	ChangeModified
+)
+
+// String returns "added", "removed" or "changed"
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "changed"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// Change is a difference between two documents, at a single location
+type Change struct {
+	Kind ChangeKind
+	// Path is the JSON pointer to the location of the change
+	Path string
+	// Old is the value in the first document, if Kind is ChangeRemoved
+	// or ChangeModified
+	Old any
+	// New is the value in the second document, if Kind is ChangeAdded
+	// or ChangeModified
+	New any
+}
+
+// String describes the change on a single line, such as
+// `changed /user/name: "a" -> "b"`, `added /tags/2: "x"` or
+// `removed /items/3`. Values are written as JSON.
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("%s %s: %s", c.Kind, c.Path, changeValue(c.New))
+	case ChangeModified:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Kind, c.Path, changeValue(c.Old), changeValue(c.New))
+	default:
+		return fmt.Sprintf("%s %s", c.Kind, c.Path)
+	}
+}
+
+func changeValue(v any) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(buf)
+}
+
+// Changes compares two documents in the same way as Diff does, and
+// returns the differences between them as a list of changes, which,
+// unlike a patch, records the values that were removed or replaced. This
+// is suitable for reports meant to be read by people, such as audit logs.
+//
+// Changes are listed in the same order as the operations of the patch
+// returned by Diff.
+func Changes(a, b any, options ...RetrieveOption) ([]Change, error) {
+	cfg := newRetrieveConfig(options)
+	va, err := genericValue(a, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert first document: %w", err)
+	}
+	vb, err := genericValue(b, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert second document: %w", err)
+	}
+
+	var changes []Change
+	diffValues(&changes, "", va, vb)
+	return changes, nil
+}
+
+// genericValue converts target into generic Go values
+func genericValue(target any, cfg *retrieveConfig) (any, error) {
+	switch v := target.(type) {
+	case []byte:
+		return materializeJSON(v, cfg)
+	case string:
+		return materializeJSON([]byte(v), cfg)
+	case *Document:
+		return v.src.materialize(cfg)
+	}
+
+	buf, err := json.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T: %w", target, err)
+	}
+	return materializeJSON(buf, cfg)
+}
+
+func diffValues(changes *[]Change, ptr string, a, b any) {
+	if ma, ok := objectMembers(a); ok {
+		if mb, ok := objectMembers(b); ok {
+			diffObjects(changes, ptr, ma, mb)
+			return
+		}
+	}
+	if aa, ok := a.([]any); ok {
+		if ab, ok := b.([]any); ok {
+			diffArrays(changes, ptr, aa, ab)
+			return
+		}
+	}
+	if !equalValues(a, b) {
+		*changes = append(*changes, Change{Kind: ChangeModified, Path: ptr, Old: a, New: b})
+	}
+}
+
+func diffObjects(changes *[]Change, ptr string, a, b *objectView) {
+	for _, key := range a.keys {
+		if _, ok := b.get(key); !ok {
+			va, _ := a.get(key)
+			*changes = append(*changes, Change{Kind: ChangeRemoved, Path: ptr + "/" + escapeToken(key), Old: va})
+		}
+	}
+	for _, key := range b.keys {
+		vb, _ := b.get(key)
+		va, ok := a.get(key)
+		if !ok {
+			*changes = append(*changes, Change{Kind: ChangeAdded, Path: ptr + "/" + escapeToken(key), New: vb})
+			continue
+		}
+		diffValues(changes, ptr+"/"+escapeToken(key), va, vb)
+	}
+}
+
+func diffArrays(changes *[]Change, ptr string, a, b []any) {
+	for i := range min(len(a), len(b)) {
+		diffValues(changes, ptr+"/"+strconv.Itoa(i), a[i], b[i])
+	}
+	// Elements are removed from the end, so that the indices of the
+	// remaining ones do not change
+	for i := len(a) - 1; i >= len(b); i-- {
+		*changes = append(*changes, Change{Kind: ChangeRemoved, Path: ptr + "/" + strconv.Itoa(i), Old: a[i]})
+	}
+	for i := len(a); i < len(b); i++ {
+		*changes = append(*changes, Change{Kind: ChangeAdded, Path: ptr + "/" + strconv.Itoa(i), New: b[i]})
+	}
+}
+
+// objectView gives uniform access to map[string]any and *OrderedMap
+type objectView struct {
+	keys []string
+	get  func(string) (any, bool)
+}
+
+// objectMembers returns a view of v, if v is a generic object. The keys
+// of maps are sorted, so that the output is deterministic
+func objectMembers(v any) (*objectView, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		return &objectView{
+			keys: slices.Sorted(maps.Keys(v)),
+			get: func(key string) (any, bool) {
+				val, ok := v[key]
+				return val, ok
+			},
+		}, true
+	case *OrderedMap:
+		return &objectView{keys: v.Keys(), get: v.Get}, true
+	}
+	return nil, false
+}
+
+// equalValues compares two generic values using JSON equality semantics:
+// numbers are compared by value, and object member order is ignored
+func equalValues(a, b any) bool {
+	if ma, ok := objectMembers(a); ok {
+		mb, ok := objectMembers(b)
+		if !ok || len(ma.keys) != len(mb.keys) {
+			return false
+		}
+		for _, key := range ma.keys {
+			va, _ := ma.get(key)
+			vb, ok := mb.get(key)
+			if !ok || !equalValues(va, vb) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if ra, ok := numberValueOf(a); ok {
+		rb, ok := numberValueOf(b)
+		return ok && ra.Cmp(rb) == 0
+	}
+
+	switch a := a.(type) {
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case string, bool, nil:
+		return a == b
+	}
+	return false
+}
+
+// numberValueOf returns the exact value of a generic number. Besides
+// float64, large integers are represented as int64 or uint64 by default,
+// and Go values may hold numbers of any other numeric type
+func numberValueOf(v any) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case float64:
+		return new(big.Rat).SetFloat64(v), true
+	case float32:
+		return new(big.Rat).SetFloat64(float64(v)), true
+	case int:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint64:
+		return new(big.Rat).SetUint64(v), true
+	case uintptr:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case json.Number:
+		return new(big.Rat).SetString(string(v))
+	case *big.Float:
+		r, _ := v.Rat(nil)
+		return r, r != nil
+	}
+	return nil, false
+}
+
+// EqualAt reports whether the values at the location specified by the
+// JSON pointer `spec` in a and b are equal. Values are compared as Test
+// does: numbers are compared by value, and the order of object members is
+// not significant.
+//
+// a and b can be any targets accepted by (*Pointer).Retrieve. A location
+// that is missing from both documents is considered equal, and one that
+// is missing from only one of them is not.
+func EqualAt(a, b any, spec string) (bool, error) {
+	ptr, err := New(spec)
+	if err != nil {
+		return false, err
+	}
+	va, okA, err := valueAt(ptr, a)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve '%s' from first document: %w", spec, err)
+	}
+	vb, okB, err := valueAt(ptr, b)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve '%s' from second document: %w", spec, err)
+	}
+	if !okA || !okB {
+		return okA == okB, nil
+	}
+	return equalValues(va, vb), nil
+}
+
+// valueAt returns the value at the location of ptr in target, in the form
+// that equalValues compares, and whether the location exists
+func valueAt(ptr *Pointer, target any) (any, bool, error) {
+	var v any
+	if err := ptr.Retrieve(&v, target, WithNumberMode(NumberJSONNumber)); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	v, err := comparableValue(v)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
@@ -0,0 +1,172 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	testcases := []struct {
+		Name     string
+		A        string
+		B        string
+		Expected string
+	}{
+		{Name: "equal", A: `{"a": [1, {"b": null}]}`, B: `{"a": [1, {"b": null}]}`, Expected: `null`},
+		{Name: "member order and number spelling", A: `{"a": 1, "b": 2}`, B: `{"b": 2.0, "a": 1e0}`, Expected: `null`},
+		{Name: "large integers", A: `{"id": 18446744073709551615, "n": -9007199254740993}`, B: `{"id": 18446744073709551615, "n": -9007199254740993}`, Expected: `null`},
+		{
+			Name:     "object members",
+			A:        `{"a": 1, "b": {"c": "x"}, "d/e": true}`,
+			B:        `{"a": 2, "b": {"c": "x", "f": []}}`,
+			Expected: `[{"op":"remove","path":"/d~1e"},{"op":"replace","path":"/a","value":2},{"op":"add","path":"/b/f","value":[]}]`,
+		},
+		{
+			Name:     "shorter array",
+			A:        `[1, 2, 3, 4]`,
+			B:        `[1, 5]`,
+			Expected: `[{"op":"replace","path":"/1","value":5},{"op":"remove","path":"/3"},{"op":"remove","path":"/2"}]`,
+		},
+		{
+			Name:     "longer array",
+			A:        `{"x": []}`,
+			B:        `{"x": [null, {}]}`,
+			Expected: `[{"op":"add","path":"/x/0","value":null},{"op":"add","path":"/x/1","value":{}}]`,
+		},
+		{
+			Name:     "type change",
+			A:        `{"x": [1]}`,
+			B:        `{"x": {"0": 1}}`,
+			Expected: `[{"op":"replace","path":"/x","value":{"0":1}}]`,
+		},
+		{Name: "root", A: `1`, B: `"1"`, Expected: `[{"op":"replace","path":"","value":"1"}]`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			patch, err := jsptr.Diff([]byte(tc.A), tc.B)
+			require.NoError(t, err)
+			buf, err := json.Marshal(patch)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, string(buf))
+
+			// Applying the patch produces the second document
+			result, err := jsptr.ApplyPatch(decodeJSON(t, tc.A), patch)
+			require.NoError(t, err)
+			require.Equal(t, decodeJSON(t, tc.B), result)
+		})
+	}
+
+	t.Run("Go values", func(t *testing.T) {
+		type config struct {
+			Name  string   `json:"name"`
+			Ports []int    `json:"ports"`
+			Tags  []string `json:"tags,omitempty"`
+		}
+		patch, err := jsptr.Diff(config{Name: "a", Ports: []int{80}}, config{Name: "a", Ports: []int{80, 443}, Tags: []string{"x"}})
+		require.NoError(t, err)
+		require.Equal(t, jsptr.Patch{
+			{Op: "add", Path: "/ports/1", Value: 443.0},
+			{Op: "add", Path: "/tags", Value: []any{"x"}},
+		}, patch)
+	})
+	t.Run("options", func(t *testing.T) {
+		patch, err := jsptr.Diff(`{"n": 1}`, `{"n": 12345678901234567890}`, jsptr.WithNumberMode(jsptr.NumberJSONNumber))
+		require.NoError(t, err)
+		require.Equal(t, jsptr.Patch{{Op: "replace", Path: "/n", Value: json.Number("12345678901234567890")}}, patch)
+	})
+	t.Run("invalid documents", func(t *testing.T) {
+		_, err := jsptr.Diff(`{`, `{}`)
+		require.Error(t, err)
+		_, err = jsptr.Diff(`{}`, func() {})
+		require.Error(t, err)
+	})
+}
+
+func TestEqualAt(t *testing.T) {
+	type user struct {
+		Name  string `json:"name"`
+		Age   int    `json:"age"`
+		Admin bool   `json:"admin"`
+	}
+
+	testcases := []struct {
+		Name     string
+		A        any
+		B        any
+		Spec     string
+		Expected bool
+		Error    bool
+	}{
+		{Name: "equal numbers", A: `{"a": 1}`, B: `{"a": 1.0e0}`, Spec: "/a", Expected: true},
+		{Name: "different numbers", A: `{"a": 12345678901234567890}`, B: `{"a": 12345678901234567891}`, Spec: "/a"},
+		{Name: "objects", A: `{"a": {"x": 1, "y": [true]}}`, B: `{"a": {"y": [true], "x": 1}, "b": 2}`, Spec: "/a", Expected: true},
+		{Name: "Go values and JSON text", A: user{Name: "a", Age: 30}, B: `{"name": "a", "age": 30, "admin": false}`, Spec: "", Expected: true},
+		{Name: "different types", A: `{"a": "1"}`, B: `{"a": 1}`, Spec: "/a"},
+		{Name: "missing from both", A: `{}`, B: `{"b": 1}`, Spec: "/a", Expected: true},
+		{Name: "missing from one", A: `{"a": null}`, B: `{}`, Spec: "/a"},
+		{Name: "invalid pointer", A: `{}`, B: `{}`, Spec: "a", Error: true},
+		{Name: "invalid document", A: `{}`, B: `{`, Spec: "/a", Error: true},
+		{Name: "scalar", A: `{"a": 1}`, B: `{"a": 1}`, Spec: "/a/b", Error: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			equal, err := jsptr.EqualAt(tc.A, tc.B, tc.Spec)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, equal)
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	changes, err := jsptr.Changes(
+		`{"user": {"name": "a", "age": 1}, "items": [1, 2, 3, 4], "gone": {"x": 1}}`,
+		`{"user": {"name": "b", "age": 1.0, "email": "b@example.com"}, "items": [1, 5]}`,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []jsptr.Change{
+		{Kind: jsptr.ChangeRemoved, Path: "/gone", Old: map[string]any{"x": 1.0}},
+		{Kind: jsptr.ChangeModified, Path: "/items/1", Old: 2.0, New: 5.0},
+		{Kind: jsptr.ChangeRemoved, Path: "/items/3", Old: 4.0},
+		{Kind: jsptr.ChangeRemoved, Path: "/items/2", Old: 3.0},
+		{Kind: jsptr.ChangeAdded, Path: "/user/email", New: "b@example.com"},
+		{Kind: jsptr.ChangeModified, Path: "/user/name", Old: "a", New: "b"},
+	}, changes)
+
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+	require.Equal(t, []string{
+		`removed /gone`,
+		`changed /items/1: 2 -> 5`,
+		`removed /items/3`,
+		`removed /items/2`,
+		`added /user/email: "b@example.com"`,
+		`changed /user/name: "a" -> "b"`,
+	}, lines)
+
+	changes, err = jsptr.Changes(`{"a": 1}`, `{"a": 1.0}`)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Integers beyond the range of float64 are compared exactly
+	changes, err = jsptr.Changes(`{"id": 18446744073709551615}`, `{"id": 18446744073709551615}`)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Go values may hold numbers of any type
+	changes, err = jsptr.Changes(map[string]any{"a": uint8(1), "b": int32(-2)}, `{"a": 1, "b": -2}`)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, err = jsptr.Changes(`{`, `{}`)
+	require.Error(t, err)
+}
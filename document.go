@@ -0,0 +1,112 @@
+package jsptr
+
+import (
+	"fmt"
+	"time"
+)
+
+// Document is a parsed JSON document that can be queried repeatedly
+// without re-parsing the underlying bytes.
+//
+// A Document is never modified once it has been parsed, so it is safe
+// for concurrent reads, including calls to RetrieveMulti with
+// WithParallelism.
+//
+// Document implements the Source interface, so it can also be passed
+// as the target of (*Pointer).Retrieve
+type Document struct {
+	src jsonSource
+	// duplicateKeys is the policy that the document was parsed with
+	duplicateKeys DuplicateKeyPolicy
+}
+
+// ParseJSON parses the given JSON bytes and returns a Document
+// that can be used to evaluate any number of JSON pointers against it.
+//
+// Use WithDuplicateKeys to specify how objects with duplicate member
+// names are handled, and WithMaxDocumentSize to limit the size of the
+// document.
+func ParseJSON(data []byte, options ...ParseOption) (*Document, error) {
+	var cfg retrieveConfig
+	for _, option := range options {
+		switch option.Ident() {
+		case identDuplicateKeys{}:
+			cfg.duplicateKeys = option.Value().(DuplicateKeyPolicy)
+		case identMaxDocumentSize{}:
+			cfg.maxDocumentSize = option.Value().(int64)
+		}
+	}
+
+	if err := cfg.checkDocumentSize(len(data)); err != nil {
+		return nil, err
+	}
+	data, err := cfg.resolveDuplicates(data)
+	if err != nil {
+		return nil, err
+	}
+	src, err := createJSONSource(data)
+	if err != nil {
+		return nil, err
+	}
+	// Values are initialized up front, so that reads do not modify them
+	prepareJSON(src.(jsonSource).parsed)
+	return &Document{src: src.(jsonSource), duplicateKeys: cfg.duplicateKeys}, nil
+}
+
+// Bytes returns the original JSON bytes that the document was created from
+func (d *Document) Bytes() []byte {
+	return d.src.data
+}
+
+// AppendTo appends the JSON bytes of the document to dst, and returns the
+// extended buffer
+func (d *Document) AppendTo(dst []byte) []byte {
+	return append(dst, d.src.data...)
+}
+
+// MarshalJSON returns a copy of the JSON bytes of the document, so that a
+// Document can be embedded in values that are encoded using encoding/json
+func (d *Document) MarshalJSON() ([]byte, error) {
+	return d.AppendTo(nil), nil
+}
+
+// Retrieve retrieves the value at the location specified by the JSON
+// pointer `spec`, and assigns it to `dst`
+func (d *Document) Retrieve(dst any, spec string, options ...RetrieveOption) (err error) {
+	if m := currentMetrics(); m != nil {
+		start := time.Now()
+		defer func() { m.RetrieveDone(time.Since(start), err) }()
+	}
+
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return err
+	}
+	cfg := newRetrieveConfig(options)
+	return retrieveConverted(dst, cfg, func(dst any) error {
+		return d.retrieveTokens(dst, tokens, cfg)
+	})
+}
+
+func (d *Document) retrieveTokens(dst any, tokens []string, cfg *retrieveConfig) error {
+	if err := cfg.checkParsedDuplicates(d); err != nil {
+		return err
+	}
+	return d.src.retrieveTokens(dst, tokens, cfg)
+}
+
+// Get returns the value at the location specified by the JSON pointer `spec`.
+// Objects are returned as map[string]any and arrays as []any. Numbers are
+// returned as float64, except for integers beyond ±2^53, which are returned
+// as int64 or uint64, unless specified otherwise using WithNumberMode
+func (d *Document) Get(spec string, options ...RetrieveOption) (any, error) {
+	var v any
+	if err := d.Retrieve(&v, spec, options...); err != nil {
+		return nil, fmt.Errorf("failed to retrieve value at '%s': %w", spec, err)
+	}
+	return v, nil
+}
+
+func (d *Document) RetrieveJSONPointer(dst any, ptrspec string) error {
+	return d.Retrieve(dst, ptrspec)
+}
@@ -0,0 +1,50 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument(t *testing.T) {
+	const src = `{"foo": "bar", "array": [1, 2, 3], "nested": {"key": "value"}}`
+
+	doc, err := jsptr.ParseJSON([]byte(src))
+	require.NoError(t, err)
+	require.Equal(t, src, string(doc.Bytes()))
+
+	t.Run("Get", func(t *testing.T) {
+		v, err := doc.Get("/nested/key")
+		require.NoError(t, err)
+		require.Equal(t, "value", v)
+
+		v, err = doc.Get("/array")
+		require.NoError(t, err)
+		require.Equal(t, []any{1.0, 2.0, 3.0}, v)
+
+		_, err = doc.Get("/nonexistent")
+		require.Error(t, err)
+	})
+	t.Run("Retrieve", func(t *testing.T) {
+		var s string
+		require.NoError(t, doc.Retrieve(&s, "/foo"))
+		require.Equal(t, "bar", s)
+
+		var f float64
+		require.NoError(t, doc.Retrieve(&f, "/array/2"))
+		require.Equal(t, 3.0, f)
+	})
+	t.Run("as a Source", func(t *testing.T) {
+		ptr, err := jsptr.New("/array/1")
+		require.NoError(t, err)
+
+		var f float64
+		require.NoError(t, ptr.Retrieve(&f, doc))
+		require.Equal(t, 2.0, f)
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := jsptr.ParseJSON([]byte(`{"foo": }`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,67 @@
+package jsptr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FromDotPath creates a pointer from a dot-separated path such as
+// "foo.bar.0", as used by gjson and many configuration systems. The
+// empty path refers to the root of the document.
+//
+// A backslash escapes the character that follows it, so that member
+// names containing dots can be expressed: "a\.b.c" refers to the member
+// "c" of the member "a.b", and "a\\b" refers to the member "a\b". Empty
+// member names cannot be expressed, so paths such as "a..b" are rejected.
+func FromDotPath(path string) (*Pointer, error) {
+	if path == "" {
+		return &Pointer{pattern: "", tokens: nil}, nil
+	}
+
+	var tokens []string
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\\':
+			i++
+			if i == len(path) {
+				return nil, fmt.Errorf("invalid dot path '%s': trailing escape character", path)
+			}
+			sb.WriteByte(path[i])
+		case '.':
+			if sb.Len() == 0 {
+				return nil, fmt.Errorf("invalid dot path '%s': empty member name", path)
+			}
+			tokens = append(tokens, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if sb.Len() == 0 {
+		return nil, fmt.Errorf("invalid dot path '%s': empty member name", path)
+	}
+	tokens = append(tokens, sb.String())
+
+	return &Pointer{pattern: joinTokens(tokens), tokens: tokens}, nil
+}
+
+// DotString returns the dot-separated path equivalent to the pointer,
+// using the escaping rules described in FromDotPath. Since empty member
+// names cannot be expressed as dot paths, pointers containing them do
+// not round-trip through FromDotPath.
+func (p *Pointer) DotString() string {
+	var sb strings.Builder
+	for i, token := range p.tokens {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		for j := 0; j < len(token); j++ {
+			if c := token[j]; c == '.' || c == '\\' {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(token[j])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDotPath(t *testing.T) {
+	testcases := []struct {
+		Path    string
+		Pointer string
+		Error   bool
+	}{
+		{Path: "", Pointer: ""},
+		{Path: "foo", Pointer: "/foo"},
+		{Path: "foo.bar.0", Pointer: "/foo/bar/0"},
+		{Path: `a\.b.c`, Pointer: "/a.b/c"},
+		{Path: `a\\b`, Pointer: `/a\b`},
+		{Path: "a/b.m~n", Pointer: "/a~1b/m~0n"},
+		{Path: "a..b", Error: true},
+		{Path: ".a", Error: true},
+		{Path: "a.", Error: true},
+		{Path: `a\`, Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Path, func(t *testing.T) {
+			ptr, err := jsptr.FromDotPath(tc.Path)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Pointer, ptr.Pattern())
+
+			// DotString must produce the original path
+			require.Equal(t, tc.Path, ptr.DotString())
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/valyala/fastjson"
+)
+
+// ErrDuplicateKey is the error that is returned (possibly wrapped) when
+// a JSON document contains an object with duplicate member names, and
+// the DuplicateKeysError policy is in effect. Use errors.Is to check
+// for it.
+var ErrDuplicateKey = errors.New("duplicate object key")
+
+// DuplicateKeyPolicy specifies how objects with duplicate member names
+// in JSON documents are handled
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeysUnchecked does not look for duplicate member names.
+	// Which of the duplicated values is used depends on the operation:
+	// following a pointer uses the first one, while decoding an object
+	// uses the last one. This is the default
+	DuplicateKeysUnchecked DuplicateKeyPolicy = iota
+	// DuplicateKeysFirst uses the first of the duplicated values
+	DuplicateKeysFirst
+	// DuplicateKeysLast uses the last of the duplicated values, which
+	// is what encoding/json does
+	DuplicateKeysLast
+	// DuplicateKeysError rejects documents containing duplicate member
+	// names with an error matching ErrDuplicateKey
+	DuplicateKeysError
+)
+
+// resolveDuplicates applies the duplicate key policy of cfg to the JSON
+// document in data. Documents without duplicate member names are
+// returned as is, and so are invalid documents, so that their errors
+// are reported by the regular code path
+func (cfg *retrieveConfig) resolveDuplicates(data []byte) ([]byte, error) {
+	if cfg.duplicateKeys == DuplicateKeysUnchecked {
+		return data, nil
+	}
+
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+
+	parsed, err := parseJSON(p, data)
+	if err != nil {
+		return data, nil
+	}
+
+	changed, err := dedupeJSON(parsed, nil, cfg.duplicateKeys)
+	if err != nil || !changed {
+		return data, err
+	}
+	return parsed.MarshalTo(nil), nil
+}
+
+// resolveTargetDuplicates applies the duplicate key policy of cfg to
+// target, if it is a JSON document given as bytes, a string or a reader
+func (cfg *retrieveConfig) resolveTargetDuplicates(target any) (any, error) {
+	if cfg.duplicateKeys == DuplicateKeysUnchecked {
+		return target, nil
+	}
+	switch v := target.(type) {
+	case []byte:
+		return cfg.resolveDuplicates(v)
+	case string:
+		return cfg.resolveDuplicates([]byte(v))
+	case *Document:
+		if err := cfg.checkParsedDuplicates(v); err != nil {
+			return nil, err
+		}
+		return target, nil
+	case io.Reader:
+		data, err := io.ReadAll(cfg.limitReader(v))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JSON: %w", err)
+		}
+		return cfg.resolveDuplicates(data)
+	default:
+		return target, nil
+	}
+}
+
+// checkParsedDuplicates returns an error if the duplicate key policy of
+// cfg differs from the one that doc was parsed with, as duplicates can
+// only be handled when a document is parsed
+func (cfg *retrieveConfig) checkParsedDuplicates(doc *Document) error {
+	if cfg.duplicateKeys == DuplicateKeysUnchecked || cfg.duplicateKeys == doc.duplicateKeys {
+		return nil
+	}
+	return fmt.Errorf("duplicate key policy of a parsed document cannot be changed: pass WithDuplicateKeys to ParseJSON instead")
+}
+
+// dedupeJSON removes duplicate member names from the objects in v
+// according to policy, and reports whether anything was removed
+func dedupeJSON(v *fastjson.Value, tokens []string, policy DuplicateKeyPolicy) (bool, error) {
+	switch v.Type() {
+	case fastjson.TypeArray:
+		var changed bool
+		for i, elem := range v.GetArray() {
+			c, err := dedupeJSON(elem, append(tokens, fmt.Sprint(i)), policy)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+		return changed, nil
+	case fastjson.TypeObject:
+	default:
+		return false, nil
+	}
+
+	obj, _ := v.Object()
+	var keys []string
+	var values []*fastjson.Value
+	index := make(map[string]int, obj.Len())
+	var duplicate string
+	var found bool
+	obj.Visit(func(key []byte, val *fastjson.Value) {
+		k := string(key)
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(keys)
+			keys = append(keys, k)
+			values = append(values, val)
+			return
+		}
+		if !found {
+			duplicate, found = k, true
+		}
+		if policy == DuplicateKeysLast {
+			values[i] = val
+		}
+	})
+	if found && policy == DuplicateKeysError {
+		return false, fmt.Errorf("%w '%s' in object at '%s'", ErrDuplicateKey, duplicate, joinTokens(tokens))
+	}
+
+	changed := found
+	for i, val := range values {
+		c, err := dedupeJSON(val, append(tokens, keys[i]), policy)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || c
+	}
+	if !found {
+		return changed, nil
+	}
+
+	// Rebuild the object so that every member appears once, at the
+	// position of its first occurrence
+	for _, key := range keys {
+		for obj.Get(key) != nil {
+			obj.Del(key)
+		}
+	}
+	for i, key := range keys {
+		obj.Set(key, values[i])
+	}
+	return true, nil
+}
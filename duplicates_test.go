@@ -0,0 +1,122 @@
+package jsptr_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	const src = `{"role": "user", "nested": {"a": 1, "b": 2, "a": 3}, "role": "admin"}`
+
+	type result struct {
+		Role   string
+		Nested map[string]any
+	}
+
+	testcases := []struct {
+		Name   string
+		Policy jsptr.DuplicateKeyPolicy
+		Role   string
+		Nested map[string]any
+		Error  bool
+	}{
+		{Name: "first", Policy: jsptr.DuplicateKeysFirst, Role: "user", Nested: map[string]any{"a": 1.0, "b": 2.0}},
+		{Name: "last", Policy: jsptr.DuplicateKeysLast, Role: "admin", Nested: map[string]any{"a": 3.0, "b": 2.0}},
+		{Name: "error", Policy: jsptr.DuplicateKeysError, Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			option := jsptr.WithDuplicateKeys(tc.Policy)
+			for name, target := range map[string]func() any{
+				"bytes":  func() any { return []byte(src) },
+				"string": func() any { return src },
+				"reader": func() any { return bytes.NewReader([]byte(src)) },
+			} {
+				t.Run(name, func(t *testing.T) {
+					var role string
+					err := jsptr.MustNew("/role").Retrieve(&role, target(), option)
+					if tc.Error {
+						require.ErrorIs(t, err, jsptr.ErrDuplicateKey)
+						return
+					}
+					require.NoError(t, err)
+					require.Equal(t, tc.Role, role)
+
+					// Decoding objects must agree with following pointers
+					var nested map[string]any
+					require.NoError(t, jsptr.MustNew("/nested").Retrieve(&nested, target(), option))
+					require.Equal(t, tc.Nested, nested)
+
+					var a float64
+					require.NoError(t, jsptr.MustNew("/nested/a").Retrieve(&a, target(), option))
+					require.Equal(t, tc.Nested["a"], a)
+
+					var r result
+					require.NoError(t, jsptr.MustNew("").Retrieve(&r, target(), option))
+					require.Equal(t, result{Role: tc.Role, Nested: tc.Nested}, r)
+				})
+			}
+		})
+	}
+
+	t.Run("documents without duplicates", func(t *testing.T) {
+		var v string
+		require.NoError(t, jsptr.MustNew("/a").Retrieve(&v, []byte(`{"a": "x", "b": {"a": "y"}}`), jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError)))
+		require.Equal(t, "x", v)
+	})
+	t.Run("RetrieveAll", func(t *testing.T) {
+		ptr, err := jsptr.New("/*", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		matches, err := ptr.RetrieveAll([]byte(`{"a": 1, "a": 2}`), jsptr.WithDuplicateKeys(jsptr.DuplicateKeysLast))
+		require.NoError(t, err)
+		require.Equal(t, []jsptr.Match{{Pointer: "/a", Value: 2.0}}, matches)
+
+		_, err = ptr.RetrieveAll([]byte(`{"a": 1, "a": 2}`), jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError))
+		require.ErrorIs(t, err, jsptr.ErrDuplicateKey)
+	})
+	t.Run("RetrieveMulti", func(t *testing.T) {
+		_, err := jsptr.RetrieveMulti([]byte(`[{"a": 1, "a": 2}]`), []*jsptr.Pointer{jsptr.MustNew("/0/a")}, jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError))
+		require.ErrorIs(t, err, jsptr.ErrDuplicateKey)
+	})
+	t.Run("Documents", func(t *testing.T) {
+		const src = `{"a": "first", "a": "second"}`
+
+		_, err := jsptr.ParseJSON([]byte(src), jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError))
+		require.ErrorIs(t, err, jsptr.ErrDuplicateKey)
+		_, err = jsptr.NewSafeDocument([]byte(src), jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError))
+		require.ErrorIs(t, err, jsptr.ErrDuplicateKey)
+
+		doc, err := jsptr.ParseJSON([]byte(src), jsptr.WithDuplicateKeys(jsptr.DuplicateKeysLast))
+		require.NoError(t, err)
+		var s string
+		require.NoError(t, doc.Retrieve(&s, "/a", jsptr.WithDuplicateKeys(jsptr.DuplicateKeysLast)))
+		require.Equal(t, "second", s)
+
+		// Policies that differ from the one used to parse the document
+		// cannot be honored, and must not be silently ignored
+		unchecked, err := jsptr.ParseJSON([]byte(src))
+		require.NoError(t, err)
+		option := jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError)
+		require.ErrorContains(t, unchecked.Retrieve(&s, "/a", option), "ParseJSON")
+		require.Error(t, jsptr.MustNew("/a").Retrieve(&s, unchecked, option))
+		require.Error(t, jsptr.MustNew("/a").Retrieve(&s, doc, option))
+		_, err = jsptr.RetrieveMulti(unchecked, []*jsptr.Pointer{jsptr.MustNew("/a")}, option)
+		require.Error(t, err)
+
+		safe, err := jsptr.NewSafeDocument([]byte(`{"a": 1}`), jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError))
+		require.NoError(t, err)
+		require.ErrorIs(t, safe.Replace([]byte(src)), jsptr.ErrDuplicateKey)
+	})
+	t.Run("RetrieveFile", func(t *testing.T) {
+		fsys := fstest.MapFS{"dup.json": {Data: []byte(`{"a": "first", "a": "second"}`)}}
+		var s string
+		err := jsptr.RetrieveFile(&s, fsys, "dup.json", "/a", jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError))
+		require.ErrorIs(t, err, jsptr.ErrDuplicateKey)
+	})
+}
@@ -0,0 +1,80 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ErrNotFound is the error that is returned (possibly wrapped) when the
+// location referred to by a pointer does not exist in the target, such
+// as when an object member is missing or an array index is out of bounds.
+// Descending through a nil pointer, such as an optional struct field that
+// has not been set, is also reported as a missing location.
+// Use errors.Is to check for it.
+var ErrNotFound = errors.New("not found")
+
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string {
+	return e.msg
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// errNotFound creates an error that matches ErrNotFound
+func errNotFound(format string, args ...any) error {
+	return &notFoundError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Error describes the failure to apply a reference token while following
+// a pointer. It is returned (possibly wrapped) by the retrieval methods,
+// and wraps the underlying cause, so that errors.Is(err, ErrNotFound)
+// keeps working. Use errors.As to access it.
+//
+// Error implements slog.LogValuer, so that logging it records where the
+// traversal stopped as structured attributes.
+type Error struct {
+	// Pattern is the pointer being followed, in its canonical form
+	Pattern string
+	// Prefix is the pointer to the last location that was resolved,
+	// to which Token could not be applied
+	Prefix string
+	// Token is the unescaped reference token that could not be applied
+	Token string
+	// Err is the underlying cause
+	Err error
+}
+
+// Error returns the message of the underlying cause
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// LogValue returns the pattern, prefix, token and cause as a group
+func (e *Error) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("pattern", e.Pattern),
+		slog.String("prefix", e.Prefix),
+		slog.String("token", e.Token),
+		slog.String("error", e.Err.Error()),
+	)
+}
+
+// stepError creates an *Error for the failure to apply tokens[i]
+func (cfg *retrieveConfig) stepError(tokens []string, i int, err error) error {
+	return &Error{
+		Pattern: cfg.location(tokens),
+		Prefix:  cfg.location(tokens[:i]),
+		Token:   tokens[i],
+		Err:     err,
+	}
+}
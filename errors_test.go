@@ -0,0 +1,90 @@
+package jsptr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	const src = `{"a": {"b": [{"c": 1}]}}`
+
+	type nested struct {
+		Raw json.RawMessage `json:"raw"`
+	}
+
+	testcases := []struct {
+		Name    string
+		Pointer string
+		Target  any
+		Prefix  string
+		Token   string
+	}{
+		{Name: "JSON", Pointer: "/a/b/0/d", Target: src, Prefix: "/a/b/0", Token: "d"},
+		{Name: "JSON into a struct", Pointer: "/a/x/y", Target: []byte(src), Prefix: "/a", Token: "x"},
+		{Name: "reader", Pointer: "/a/b/1", Target: strings.NewReader(src), Prefix: "/a/b", Token: "1"},
+		{Name: "Go values", Pointer: "/a/b/0/c/d", Target: decodeJSON(t, src), Prefix: "/a/b/0/c", Token: "d"},
+		{Name: "nested JSON", Pointer: "/raw/a/b/x", Target: nested{Raw: json.RawMessage(src)}, Prefix: "/raw/a/b", Token: "x"},
+		{Name: "escaped tokens", Pointer: "/a/b~1c", Target: src, Prefix: "/a", Token: "b/c"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v struct{ C int }
+			err = ptr.Retrieve(&v, tc.Target)
+			require.Error(t, err)
+
+			var perr *jsptr.Error
+			require.True(t, errors.As(err, &perr), "%T: %s", err, err)
+			require.Equal(t, tc.Pointer, perr.Pattern)
+			require.Equal(t, tc.Prefix, perr.Prefix)
+			require.Equal(t, tc.Token, perr.Token)
+			require.Equal(t, perr.Err.Error(), perr.Error())
+		})
+	}
+
+	t.Run("not found errors are still matched", func(t *testing.T) {
+		ptr, err := jsptr.New("/a/missing")
+		require.NoError(t, err)
+		var v any
+		require.ErrorIs(t, ptr.Retrieve(&v, src), jsptr.ErrNotFound)
+	})
+}
+
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	ptr, err := jsptr.New("/a/b/c")
+	require.NoError(t, err)
+
+	var v any
+	err = ptr.Retrieve(&v, `{"a": {"b": 1}}`)
+	require.Error(t, err)
+
+	var perr *jsptr.Error
+	require.True(t, errors.As(err, &perr))
+	logger.Info("retrieval failed", "pointer", ptr, "error", perr)
+	require.JSONEq(t, `{
+		"level": "INFO",
+		"msg": "retrieval failed",
+		"pointer": {"pattern": "/a/b/c", "tokens": 3},
+		"error": {"pattern": "/a/b/c", "prefix": "/a/b", "token": "c", "error": "cannot index into number with 'c'"}
+	}`, buf.String())
+}
@@ -0,0 +1,570 @@
+package jsptr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+type segmentKind int
+
+const (
+	segmentLiteral segmentKind = iota
+	segmentWildcard
+	segmentRecursive
+	segmentSlice
+	segmentFilter
+	segmentUnion
+)
+
+// segment is a compiled reference token of a pointer that was created
+// with extensions enabled
+type segment struct {
+	kind  segmentKind
+	token string
+	// low and high are the bounds of a slice segment. high is -1 if
+	// the slice extends to the end of the array
+	low, high int
+	// member and value are the member name and the literal that
+	// elements must have to be matched by a filter segment
+	member string
+	value  any
+	// alternatives are the tokens listed by a union segment
+	alternatives []string
+}
+
+// segmentConfig specifies which kinds of extension tokens are recognized
+type segmentConfig struct {
+	extensions bool
+	slices     bool
+	filters    bool
+	unions     bool
+}
+
+func (c segmentConfig) enabled() bool {
+	return c.extensions || c.slices || c.filters || c.unions
+}
+
+// compileSegments compiles tokens into segments. If none of the tokens
+// are extension tokens, nil is returned so that the pointer is evaluated
+// using the regular code path
+func compileSegments(tokens []string, cfg segmentConfig) ([]segment, error) {
+	var extended bool
+	segments := make([]segment, 0, len(tokens))
+	for _, token := range tokens {
+		if cfg.unions {
+			if alternatives, ok := parseUnion(token); ok {
+				segments = append(segments, segment{kind: segmentUnion, token: token, alternatives: alternatives})
+				extended = true
+				continue
+			}
+		}
+		if cfg.filters {
+			if member, value, ok := parseFilter(token); ok {
+				segments = append(segments, segment{kind: segmentFilter, token: token, member: member, value: value})
+				extended = true
+				continue
+			}
+		}
+		if cfg.slices {
+			if low, high, ok := parseSlice(token); ok {
+				segments = append(segments, segment{kind: segmentSlice, token: token, low: low, high: high})
+				extended = true
+				continue
+			}
+		}
+		if !cfg.extensions {
+			segments = append(segments, segment{kind: segmentLiteral, token: token})
+			continue
+		}
+
+		switch token {
+		case "*":
+			segments = append(segments, segment{kind: segmentWildcard, token: token})
+			extended = true
+		case "**":
+			// Consecutive recursive tokens are redundant, and would
+			// only cause the same values to be matched multiple times
+			if n := len(segments); n > 0 && segments[n-1].kind == segmentRecursive {
+				continue
+			}
+			segments = append(segments, segment{kind: segmentRecursive, token: token})
+			extended = true
+		default:
+			segments = append(segments, segment{kind: segmentLiteral, token: token})
+		}
+	}
+	if !extended {
+		return nil, nil
+	}
+	return segments, nil
+}
+
+// parseSlice parses a slice token of the form "low:high", where either
+// bound may be omitted
+func parseSlice(token string) (int, int, bool) {
+	lowspec, highspec, ok := strings.Cut(token, ":")
+	if !ok {
+		return 0, 0, false
+	}
+	low, high := 0, -1
+	if lowspec != "" {
+		v, ok := parseSliceBound(lowspec)
+		if !ok {
+			return 0, 0, false
+		}
+		low = v
+	}
+	if highspec != "" {
+		v, ok := parseSliceBound(highspec)
+		if !ok {
+			return 0, 0, false
+		}
+		high = v
+	}
+	return low, high, true
+}
+
+func parseSliceBound(s string) (int, bool) {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseFilter parses a filter token of the form "[member=literal]". The
+// literal is interpreted as JSON if possible, and as a string otherwise
+func parseFilter(token string) (string, any, bool) {
+	if len(token) < 2 || token[0] != '[' || token[len(token)-1] != ']' {
+		return "", nil, false
+	}
+	member, literal, ok := strings.Cut(token[1:len(token)-1], "=")
+	if !ok || member == "" {
+		return "", nil, false
+	}
+	if v, err := materializeJSON([]byte(literal), editValuesConfig); err == nil {
+		return member, v, true
+	}
+	return member, literal, true
+}
+
+// parseUnion parses a union token of the form "{a,b,c}"
+func parseUnion(token string) ([]string, bool) {
+	if len(token) < 3 || token[0] != '{' || token[len(token)-1] != '}' {
+		return nil, false
+	}
+	return strings.Split(token[1:len(token)-1], ","), true
+}
+
+// matchFilter reports whether node has a member named seg.member whose
+// value equals the literal of the filter segment
+func matchFilter(node any, seg segment, cfg *retrieveConfig) bool {
+	v, err := childNode(node, seg.member, cfg)
+	if err != nil {
+		return false
+	}
+	if n, ok := v.(jsonNode); ok {
+		v = n.v
+	}
+	v, err = comparableValue(v)
+	if err != nil {
+		return false
+	}
+	return equalValues(v, seg.value)
+}
+
+// matchSegments reports whether the location specified by tokens is
+// matched by segments. Patterns that are matched against locations, such
+// as those of Glob, Merge and Redactor, only enable wildcard and recursive
+// tokens, so other kinds of segments never appear here
+func matchSegments(segments []segment, tokens []string) bool {
+	if len(segments) == 0 {
+		return len(tokens) == 0
+	}
+
+	seg := segments[0]
+	switch seg.kind {
+	case segmentRecursive:
+		for i := range len(tokens) + 1 {
+			if matchSegments(segments[1:], tokens[i:]) {
+				return true
+			}
+		}
+		return false
+	case segmentWildcard:
+		return len(tokens) > 0 && matchSegments(segments[1:], tokens[1:])
+	default:
+		return len(tokens) > 0 && tokens[0] == seg.token && matchSegments(segments[1:], tokens[1:])
+	}
+}
+
+// Match is a value matched by a pointer, along with the concrete pointer
+// that refers to its location
+type Match struct {
+	Pointer string
+	Value   any
+}
+
+// RetrieveAll retrieves all values that match the pointer. For pointers
+// without extension tokens, at most one value is returned.
+//
+// Locations that do not exist are not considered an error: they simply
+// do not produce a match. Matches are returned in the same order as
+// the values would be visited by Walk.
+func (p *Pointer) RetrieveAll(target any, options ...RetrieveOption) ([]Match, error) {
+	cfg := newRetrieveConfig(options)
+	target, err := cfg.resolveTargetDuplicates(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	err = resolveNode(target, nil, func(root any) error {
+		return expandConcrete(root, "", p.expandSegments(), cfg, func(ptr string, node any) error {
+			v, err := exportNode(node, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to convert value at '%s': %w", ptr, err)
+			}
+			matches = append(matches, Match{Pointer: ptr, Value: v})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+var errStopExpand = errors.New("stop expanding")
+
+func (p *Pointer) retrieveFirst(dst any, target any, cfg *retrieveConfig) error {
+	target, err := cfg.resolveTargetDuplicates(target)
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	err = resolveNode(target, nil, func(root any) error {
+		return expand(root, "", p.segments, cfg, func(_ string, node any) error {
+			if view, ok := node.(sliceView); ok {
+				node = view.elems
+			}
+			if err := assignNode(dst, node, cfg); err != nil {
+				return err
+			}
+			found = true
+			return errStopExpand
+		})
+	})
+	if err != nil && !errors.Is(err, errStopExpand) {
+		return err
+	}
+	if !found {
+		return errNotFound("no value matched pointer '%s'", p.pattern)
+	}
+	return nil
+}
+
+func (p *Pointer) expandSegments() []segment {
+	if p.segments != nil {
+		return p.segments
+	}
+	segments := make([]segment, len(p.tokens))
+	for i, token := range p.tokens {
+		segments[i] = segment{kind: segmentLiteral, token: token}
+	}
+	return segments
+}
+
+// expand evaluates segments against node, and calls fn for every
+// value that matches
+func expand(node any, ptr string, segments []segment, cfg *retrieveConfig, fn func(string, any) error) error {
+	if len(segments) == 0 {
+		return fn(ptr, node)
+	}
+	if view, ok := node.(sliceView); ok {
+		return expandSlice(view, ptr, segments, cfg, fn)
+	}
+
+	seg := segments[0]
+	switch seg.kind {
+	case segmentRecursive:
+		// "**" matches zero or more levels: first try the rest of the
+		// pointer at the current level, then descend into every member
+		if err := expand(node, ptr, segments[1:], cfg, fn); err != nil {
+			return err
+		}
+		seq, ok := members(node)
+		if !ok {
+			return nil
+		}
+		for token, child := range seq {
+			if err := expand(child, ptr+"/"+escapeToken(token), segments, cfg, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case segmentWildcard:
+		seq, ok := members(node)
+		if !ok {
+			return nil
+		}
+		for token, child := range seq {
+			if err := expand(child, ptr+"/"+escapeToken(token), segments[1:], cfg, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case segmentFilter:
+		// Like "*", but only elements of arrays that satisfy the filter
+		// are matched
+		elems, _, ok := sliceNode(node, 0, -1)
+		if !ok {
+			return nil
+		}
+		seq, _ := members(elems)
+		for token, child := range seq {
+			if !matchFilter(child, seg, cfg) {
+				continue
+			}
+			if err := expand(child, ptr+"/"+escapeToken(token), segments[1:], cfg, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case segmentUnion:
+		// Alternatives are tried in the order they are listed, and
+		// missing locations simply do not match
+		for _, alternative := range seg.alternatives {
+			child, err := childNode(node, alternative, cfg)
+			if err != nil {
+				continue
+			}
+			if err := expand(child, ptr+"/"+escapeToken(alternative), segments[1:], cfg, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case segmentSlice:
+		// Values that are not arrays simply do not match. The remaining
+		// segments are evaluated against the sub-array, which is not
+		// part of the pointer of the values matched within it
+		sub, low, ok := sliceNode(node, seg.low, seg.high)
+		if !ok {
+			return nil
+		}
+		return expand(sliceView{elems: sub, offset: low}, ptr, segments[1:], cfg, fn)
+	default:
+		// Missing locations simply do not match
+		child, err := childNode(node, seg.token, cfg)
+		if err != nil {
+			return nil
+		}
+		return expand(child, ptr+"/"+escapeToken(seg.token), segments[1:], cfg, fn)
+	}
+}
+
+// childNode returns the value referred to by token within node, which
+// may be a jsonNode, a custom Source, or any other Go value
+func childNode(node any, token string, cfg *retrieveConfig) (any, error) {
+	if n, ok := node.(jsonNode); ok {
+		v, err := childJSON(n.v, token)
+		if err != nil {
+			return nil, err
+		}
+		return jsonNode{v}, nil
+	}
+
+	if source, ok := asSource(node); ok {
+		var v any
+		if err := retrieveFromSource(&v, source, []string{token}, cfg); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return child(node, token, cfg)
+}
+
+// sliceView is the sub-array selected by a slice token. expand passes it
+// on in place of the sub-array, so that its elements are reported under
+// their indices in the original array. ptr values that accompany a
+// sliceView refer to the original array.
+//
+// A sliceView is only handed to the callback of expand if the slice token
+// is the last segment, in which case it stands for the whole sub-array
+type sliceView struct {
+	elems  any
+	offset int
+}
+
+// each calls fn for every element of the view, along with its pointer
+// given that ptr is the pointer to the original array
+func (v sliceView) each(ptr string, fn func(string, any) error) error {
+	seq, _ := members(v.elems)
+	i := v.offset
+	for _, elem := range seq {
+		if err := fn(ptr+"/"+strconv.Itoa(i), elem); err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+// expandSlice evaluates segments against the sub-array selected by a
+// slice token, like expand does for other nodes
+func expandSlice(view sliceView, ptr string, segments []segment, cfg *retrieveConfig, fn func(string, any) error) error {
+	seg := segments[0]
+	switch seg.kind {
+	case segmentRecursive:
+		if err := expand(view, ptr, segments[1:], cfg, fn); err != nil {
+			return err
+		}
+		return view.each(ptr, func(ptr string, elem any) error {
+			return expand(elem, ptr, segments, cfg, fn)
+		})
+	case segmentWildcard:
+		return view.each(ptr, func(ptr string, elem any) error {
+			return expand(elem, ptr, segments[1:], cfg, fn)
+		})
+	case segmentFilter:
+		return view.each(ptr, func(ptr string, elem any) error {
+			if !matchFilter(elem, seg, cfg) {
+				return nil
+			}
+			return expand(elem, ptr, segments[1:], cfg, fn)
+		})
+	case segmentSlice:
+		sub, low, _ := sliceNode(view.elems, seg.low, seg.high)
+		return expand(sliceView{elems: sub, offset: view.offset + low}, ptr, segments[1:], cfg, fn)
+	case segmentUnion:
+		for _, alternative := range seg.alternatives {
+			if err := view.expandIndex(alternative, ptr, segments[1:], cfg, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return view.expandIndex(seg.token, ptr, segments[1:], cfg, fn)
+	}
+}
+
+// expandIndex evaluates segments against the element of the view at the
+// index token. Tokens that do not refer to an element simply do not match
+func (v sliceView) expandIndex(token string, ptr string, segments []segment, cfg *retrieveConfig, fn func(string, any) error) error {
+	elem, err := childNode(v.elems, token, cfg)
+	if err != nil {
+		return nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return nil
+	}
+	return expand(elem, ptr+"/"+strconv.Itoa(v.offset+i), segments, cfg, fn)
+}
+
+// expandConcrete works like expand, but sub-arrays selected by a final
+// slice token are reported one element at a time, so that every reported
+// pointer refers to the location of its value
+func expandConcrete(node any, ptr string, segments []segment, cfg *retrieveConfig, fn func(string, any) error) error {
+	return expand(node, ptr, segments, cfg, func(ptr string, node any) error {
+		if view, ok := node.(sliceView); ok {
+			return view.each(ptr, fn)
+		}
+		return fn(ptr, node)
+	})
+}
+
+// sliceNode returns the elements of the array node in the range
+// [low, high). Bounds beyond the end of the array are clamped to its
+// length, and a high bound of -1 refers to the end of the array. The
+// elements are not copied: the result shares them with node. The clamped
+// low bound is also returned, which is the index of the first element
+func sliceNode(node any, low, high int) (any, int, bool) {
+	bounds := func(n int) (int, int) {
+		if high < 0 || high > n {
+			high = n
+		}
+		low = min(low, high)
+		return low, high
+	}
+
+	switch v := node.(type) {
+	case jsonNode:
+		if v.v.Type() != fastjson.TypeArray {
+			return nil, 0, false
+		}
+		elems, _ := v.v.Array()
+		lo, hi := bounds(len(elems))
+		var arena fastjson.Arena
+		arr := arena.NewArray()
+		for i, elem := range elems[lo:hi] {
+			arr.SetArrayItem(i, elem)
+		}
+		return jsonNode{arr}, lo, true
+	case []any:
+		lo, hi := bounds(len(v))
+		return v[lo:hi:hi], lo, true
+	case []byte:
+		return nil, 0, false
+	}
+
+	if _, ok := asSource(node); ok {
+		return nil, 0, false
+	}
+	if v, ok, err := marshaledValue(node, defaultRetrieveConfig); err != nil {
+		return nil, 0, false
+	} else if ok {
+		return sliceNode(v, low, high)
+	}
+
+	rv := reflect.ValueOf(node)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, 0, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice:
+		lo, hi := bounds(rv.Len())
+		return rv.Slice3(lo, hi, hi).Interface(), lo, true
+	case reflect.Array:
+		lo, hi := bounds(rv.Len())
+		elems := make([]any, 0, hi-lo)
+		for i := lo; i < hi; i++ {
+			elems = append(elems, rv.Index(i).Interface())
+		}
+		return elems, lo, true
+	default:
+		return nil, 0, false
+	}
+}
+
+// assignNode assigns the value of node, which may be a jsonNode, to dst
+func assignNode(dst any, node any, cfg *retrieveConfig) error {
+	n, ok := node.(jsonNode)
+	if !ok {
+		return cfg.assign(dst, node)
+	}
+
+	if raw, ok := dst.(*json.RawMessage); ok {
+		*raw = n.v.MarshalTo((*raw)[:0])
+		return nil
+	}
+	if isDecodeTarget(dst) {
+		return decodeInto(dst, n.v.MarshalTo(nil))
+	}
+	return (jsonSource{}).assignFromValue(dst, n.v, cfg)
+}
@@ -0,0 +1,383 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWildcardExtension(t *testing.T) {
+	const src = `{
+		"users": [
+			{"name": "alice", "email": "alice@example.com"},
+			{"name": "bob"},
+			{"name": "carol", "email": "carol@example.com"}
+		],
+		"groups": {"admin": {"size": 1}, "dev": {"size": 2}}
+	}`
+
+	t.Run("RetrieveAll over an array", func(t *testing.T) {
+		ptr, err := jsptr.New("/users/*/email", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+			t.Run(name, func(t *testing.T) {
+				matches, err := ptr.RetrieveAll(target)
+				require.NoError(t, err)
+				require.Equal(t, []jsptr.Match{
+					{Pointer: "/users/0/email", Value: "alice@example.com"},
+					{Pointer: "/users/2/email", Value: "carol@example.com"},
+				}, matches)
+			})
+		}
+	})
+	t.Run("RetrieveAll over an object", func(t *testing.T) {
+		ptr, err := jsptr.New("/groups/*/size", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		matches, err := ptr.RetrieveAll([]byte(src))
+		require.NoError(t, err)
+		require.Equal(t, []jsptr.Match{
+			{Pointer: "/groups/admin/size", Value: 1.0},
+			{Pointer: "/groups/dev/size", Value: 2.0},
+		}, matches)
+	})
+	t.Run("Retrieve returns the first match", func(t *testing.T) {
+		ptr, err := jsptr.New("/users/*/email", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		var email string
+		require.NoError(t, ptr.Retrieve(&email, []byte(src)))
+		require.Equal(t, "alice@example.com", email)
+
+		ptr, err = jsptr.New("/users/*/phone", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+		require.Error(t, ptr.Retrieve(&email, []byte(src)))
+	})
+	t.Run("without extensions '*' is a regular token", func(t *testing.T) {
+		ptr, err := jsptr.New("/*")
+		require.NoError(t, err)
+
+		var v string
+		require.NoError(t, ptr.Retrieve(&v, map[string]any{"*": "star"}))
+		require.Equal(t, "star", v)
+
+		matches, err := ptr.RetrieveAll([]byte(src))
+		require.NoError(t, err)
+		require.Empty(t, matches)
+	})
+	t.Run("structs", func(t *testing.T) {
+		type User struct {
+			Name string `json:"name"`
+		}
+		ptr, err := jsptr.New("/*/name", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		matches, err := ptr.RetrieveAll([]User{{Name: "a"}, {Name: "b"}})
+		require.NoError(t, err)
+		require.Equal(t, []jsptr.Match{
+			{Pointer: "/0/name", Value: "a"},
+			{Pointer: "/1/name", Value: "b"},
+		}, matches)
+	})
+}
+
+func TestRecursiveExtension(t *testing.T) {
+	const src = `{
+		"email": "root@example.com",
+		"users": [
+			{"email": "alice@example.com", "contacts": [{"email": "bob@example.com"}]},
+			{"name": "carol"}
+		],
+		"meta": {"owner": {"email": "owner@example.com"}}
+	}`
+
+	tests := []struct {
+		spec     string
+		expected []jsptr.Match
+	}{
+		{
+			spec: "/**/email",
+			expected: []jsptr.Match{
+				{Pointer: "/email", Value: "root@example.com"},
+				{Pointer: "/users/0/email", Value: "alice@example.com"},
+				{Pointer: "/users/0/contacts/0/email", Value: "bob@example.com"},
+				{Pointer: "/meta/owner/email", Value: "owner@example.com"},
+			},
+		},
+		{
+			spec: "/users/**/email",
+			expected: []jsptr.Match{
+				{Pointer: "/users/0/email", Value: "alice@example.com"},
+				{Pointer: "/users/0/contacts/0/email", Value: "bob@example.com"},
+			},
+		},
+		{
+			spec: "/**/**/owner/email",
+			expected: []jsptr.Match{
+				{Pointer: "/meta/owner/email", Value: "owner@example.com"},
+			},
+		},
+		{
+			spec: "/**/contacts/*/email",
+			expected: []jsptr.Match{
+				{Pointer: "/users/0/contacts/0/email", Value: "bob@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			ptr, err := jsptr.New(tt.spec, jsptr.WithExtensions(true))
+			require.NoError(t, err)
+
+			matches, err := ptr.RetrieveAll([]byte(src))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, matches)
+		})
+	}
+}
+
+func TestSliceToken(t *testing.T) {
+	const src = `{"items": [0, 1, 2, 3, 4, 5], "name": "x"}`
+
+	testcases := []struct {
+		Name     string
+		Pointer  string
+		Expected any
+		Error    bool
+	}{
+		{Name: "both bounds", Pointer: "/items/1:4", Expected: []any{1.0, 2.0, 3.0}},
+		{Name: "no low bound", Pointer: "/items/:2", Expected: []any{0.0, 1.0}},
+		{Name: "no high bound", Pointer: "/items/4:", Expected: []any{4.0, 5.0}},
+		{Name: "whole array", Pointer: "/items/:", Expected: []any{0.0, 1.0, 2.0, 3.0, 4.0, 5.0}},
+		{Name: "clamped bounds", Pointer: "/items/5:100", Expected: []any{5.0}},
+		{Name: "empty range", Pointer: "/items/4:2", Expected: []any{}},
+		{Name: "index into slice", Pointer: "/items/2:5/1", Expected: 3.0},
+		{Name: "slice of a non-array", Pointer: "/name/0:1", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer, jsptr.WithSlices(true))
+			require.NoError(t, err)
+
+			for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+				t.Run(name, func(t *testing.T) {
+					var v any
+					err := ptr.Retrieve(&v, target)
+					if tc.Error {
+						require.Error(t, err)
+						return
+					}
+					require.NoError(t, err)
+					require.Equal(t, tc.Expected, v)
+				})
+			}
+		})
+	}
+
+	t.Run("Go slices keep their type", func(t *testing.T) {
+		ptr, err := jsptr.New("/1:3", jsptr.WithSlices(true))
+		require.NoError(t, err)
+
+		var v []int
+		require.NoError(t, ptr.Retrieve(&v, []int{10, 20, 30, 40}))
+		require.Equal(t, []int{20, 30}, v)
+	})
+	t.Run("combined with extensions", func(t *testing.T) {
+		ptr, err := jsptr.New("/users/0:2/*/name", jsptr.WithSlices(true), jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		matches, err := ptr.RetrieveAll([]byte(`{"users": [{"name": "a"}, {"name": "b"}, {"name": "c"}]}`))
+		require.NoError(t, err)
+		require.Equal(t, []jsptr.Match{
+			{Pointer: "/users/0/name", Value: "a"},
+			{Pointer: "/users/1/name", Value: "b"},
+		}, matches)
+	})
+	t.Run("RetrieveAll reports concrete pointers", func(t *testing.T) {
+		const src = `{"items": [{"n": 0}, {"n": 1, "tags": ["x", "y"]}, {"n": 2}, {"n": 3}, {"n": 4}]}`
+
+		testcases := []struct {
+			Pointer  string
+			Expected []string
+		}{
+			{Pointer: "/items/1:3/*/n", Expected: []string{"/items/1/n", "/items/2/n"}},
+			{Pointer: "/items/1:4", Expected: []string{"/items/1", "/items/2", "/items/3"}},
+			{Pointer: "/items/1:4/1/n", Expected: []string{"/items/2/n"}},
+			{Pointer: "/items/1:4/1:/0", Expected: []string{"/items/2"}},
+			{Pointer: "/items/2:/[n=3]/n", Expected: []string{"/items/3/n"}},
+			{Pointer: "/items/1:2/**/1", Expected: []string{"/items/1/tags/1"}},
+			{Pointer: "/items/1:3/{0,1,5}/n", Expected: []string{"/items/1/n", "/items/2/n"}},
+			{Pointer: "/items/4:2", Expected: nil},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.Pointer, func(t *testing.T) {
+				ptr, err := jsptr.New(tc.Pointer, jsptr.WithSlices(true), jsptr.WithExtensions(true), jsptr.WithFilters(true), jsptr.WithUnions(true))
+				require.NoError(t, err)
+
+				for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+					t.Run(name, func(t *testing.T) {
+						matches, err := ptr.RetrieveAll(target)
+						require.NoError(t, err)
+
+						var pointers []string
+						for _, m := range matches {
+							pointers = append(pointers, m.Pointer)
+
+							// Every pointer can be resolved without extensions
+							var v any
+							require.NoError(t, jsptr.MustNew(m.Pointer).Retrieve(&v, target), m.Pointer)
+							require.Equal(t, m.Value, v, m.Pointer)
+						}
+						require.Equal(t, tc.Expected, pointers)
+					})
+				}
+			})
+		}
+	})
+	t.Run("without slices ':' is part of a regular token", func(t *testing.T) {
+		ptr, err := jsptr.New("/1:2", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		var v string
+		require.NoError(t, ptr.Retrieve(&v, map[string]any{"1:2": "literal"}))
+		require.Equal(t, "literal", v)
+	})
+}
+
+func TestFilterToken(t *testing.T) {
+	const src = `{
+		"users": [
+			{"id": 1, "name": "alice", "email": "alice@example.com", "admin": true},
+			{"id": 42, "name": "bob", "email": "bob@example.com"},
+			{"id": 7, "name": "carol", "email": "carol@example.com", "admin": true},
+			"not an object"
+		]
+	}`
+
+	t.Run("Retrieve returns the first match", func(t *testing.T) {
+		testcases := []struct {
+			Pointer  string
+			Expected string
+		}{
+			{Pointer: "/users/[id=42]/email", Expected: "bob@example.com"},
+			{Pointer: "/users/[name=carol]/email", Expected: "carol@example.com"},
+			{Pointer: `/users/[name="alice"]/email`, Expected: "alice@example.com"},
+			{Pointer: "/users/[admin=true]/name", Expected: "alice"},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.Pointer, func(t *testing.T) {
+				ptr, err := jsptr.New(tc.Pointer, jsptr.WithFilters(true))
+				require.NoError(t, err)
+
+				for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+					t.Run(name, func(t *testing.T) {
+						var v string
+						require.NoError(t, ptr.Retrieve(&v, target))
+						require.Equal(t, tc.Expected, v)
+					})
+				}
+			})
+		}
+	})
+	t.Run("RetrieveAll returns all matches", func(t *testing.T) {
+		ptr, err := jsptr.New("/users/[admin=true]/name", jsptr.WithFilters(true))
+		require.NoError(t, err)
+
+		matches, err := ptr.RetrieveAll([]byte(src))
+		require.NoError(t, err)
+		require.Equal(t, []jsptr.Match{
+			{Pointer: "/users/0/name", Value: "alice"},
+			{Pointer: "/users/2/name", Value: "carol"},
+		}, matches)
+	})
+	t.Run("no match", func(t *testing.T) {
+		ptr, err := jsptr.New("/users/[id=100]/email", jsptr.WithFilters(true))
+		require.NoError(t, err)
+
+		var v string
+		require.ErrorIs(t, ptr.Retrieve(&v, []byte(src)), jsptr.ErrNotFound)
+	})
+	t.Run("Go values", func(t *testing.T) {
+		type user struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		ptr, err := jsptr.New("/[id=2]/name", jsptr.WithFilters(true))
+		require.NoError(t, err)
+
+		var v string
+		require.NoError(t, ptr.Retrieve(&v, []user{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}))
+		require.Equal(t, "b", v)
+	})
+	t.Run("without filters the token is a regular token", func(t *testing.T) {
+		ptr, err := jsptr.New("/[id=1]")
+		require.NoError(t, err)
+
+		var v string
+		require.NoError(t, ptr.Retrieve(&v, map[string]any{"[id=1]": "literal"}))
+		require.Equal(t, "literal", v)
+	})
+}
+
+func TestUnionToken(t *testing.T) {
+	const src = `{
+		"config": {
+			"staging": {"url": "https://staging.example.com"},
+			"dev": {"url": "https://dev.example.com"}
+		}
+	}`
+
+	testcases := []struct {
+		Pointer  string
+		Expected string
+		Error    bool
+	}{
+		{Pointer: "/config/{prod,staging}/url", Expected: "https://staging.example.com"},
+		{Pointer: "/config/{dev,staging}/url", Expected: "https://dev.example.com"},
+		{Pointer: "/config/{staging}/url", Expected: "https://staging.example.com"},
+		{Pointer: "/config/{prod,qa}/url", Error: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer, jsptr.WithUnions(true))
+			require.NoError(t, err)
+
+			for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+				t.Run(name, func(t *testing.T) {
+					var v string
+					err := ptr.Retrieve(&v, target)
+					if tc.Error {
+						require.ErrorIs(t, err, jsptr.ErrNotFound)
+						return
+					}
+					require.NoError(t, err)
+					require.Equal(t, tc.Expected, v)
+				})
+			}
+		})
+	}
+
+	t.Run("RetrieveAll returns all alternatives that exist", func(t *testing.T) {
+		ptr, err := jsptr.New("/config/{prod,staging,dev}/url", jsptr.WithUnions(true))
+		require.NoError(t, err)
+
+		matches, err := ptr.RetrieveAll([]byte(src))
+		require.NoError(t, err)
+		require.Equal(t, []jsptr.Match{
+			{Pointer: "/config/staging/url", Value: "https://staging.example.com"},
+			{Pointer: "/config/dev/url", Value: "https://dev.example.com"},
+		}, matches)
+	})
+	t.Run("without unions the token is a regular token", func(t *testing.T) {
+		ptr, err := jsptr.New("/{a,b}", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		var v string
+		require.NoError(t, ptr.Retrieve(&v, map[string]any{"{a,b}": "literal"}))
+		require.Equal(t, "literal", v)
+	})
+}
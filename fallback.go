@@ -0,0 +1,99 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// First tries each of the pointers in specs in order, and assigns the
+// value at the first location that exists in target to dst. This is
+// useful when a value may be found in one of several locations, for
+// example when a field has moved between versions of a document:
+//
+//	jsptr.First(&dst, payload, "/new/location", "/legacy/location")
+//
+// Only missing locations (see ErrNotFound) cause the next pointer to be
+// tried. Any other error, such as a malformed pointer or a value that
+// cannot be assigned to dst, is returned immediately. If none of the
+// locations exist, an error matching ErrNotFound is returned.
+func First(dst any, target any, specs ...string) error {
+	pointers := make([]*Pointer, len(specs))
+	for i, spec := range specs {
+		ptr, err := New(spec)
+		if err != nil {
+			return fmt.Errorf("invalid pointer '%s': %w", spec, err)
+		}
+		pointers[i] = ptr
+	}
+
+	// Parse JSON targets only once
+	if data, ok := jsonBytes(target); ok {
+		p := parserPool.Get()
+		defer parserPool.Put(p)
+
+		parsed, err := parseJSON(p, data)
+		if err != nil {
+			return err
+		}
+		target = jsonSource{data: data, parsed: parsed}
+	}
+
+	source, err := createSource(target)
+	if err != nil {
+		return err
+	}
+	for _, ptr := range pointers {
+		err := retrieveFromSource(dst, source, ptr.tokens, defaultRetrieveConfig)
+		if err == nil || !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return errNotFound("none of the locations %s exist", strings.Join(specs, ", "))
+}
+
+// RetrieveOrDefault works like Retrieve, but assigns `def` to dst if the
+// location referred to by the pointer does not exist in target.
+//
+// Only missing locations (see ErrNotFound) cause the default to be used.
+// Other errors, such as a value that cannot be assigned to dst, are
+// returned as is.
+func (p *Pointer) RetrieveOrDefault(dst any, target any, def any, options ...RetrieveOption) error {
+	err := p.Retrieve(dst, target, options...)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := newRetrieveConfig(options).assign(dst, def); err != nil {
+		return fmt.Errorf("failed to assign default value: %w", err)
+	}
+	return nil
+}
+
+// Lookup works like Retrieve, but reports a missing location through
+// its boolean return value instead of an error. If the location does not
+// exist, dst is left untouched and Lookup returns false with a nil error.
+//
+// Errors unrelated to the existence of the location, such as a value that
+// cannot be assigned to dst, are still returned.
+func (p *Pointer) Lookup(dst any, target any, options ...RetrieveOption) (bool, error) {
+	if err := p.Retrieve(dst, target, options...); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// jsonBytes returns the JSON bytes held by target, if target is one
+// of the types that are treated as JSON documents
+func jsonBytes(target any) ([]byte, bool) {
+	switch v := target.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		return nil, false
+	}
+}
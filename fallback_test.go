@@ -0,0 +1,122 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirst(t *testing.T) {
+	const src = `{"legacy": {"location": "old"}, "other": {"location": 42}}`
+
+	for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("falls back to later pointers", func(t *testing.T) {
+				var v string
+				require.NoError(t, jsptr.First(&v, target, "/new/location", "/legacy/location"))
+				require.Equal(t, "old", v)
+			})
+			t.Run("uses the first pointer that exists", func(t *testing.T) {
+				var v string
+				require.NoError(t, jsptr.First(&v, target, "/legacy/location", "/new/location"))
+				require.Equal(t, "old", v)
+			})
+			t.Run("nothing exists", func(t *testing.T) {
+				var v string
+				err := jsptr.First(&v, target, "/new/location", "/newer/location")
+				require.ErrorIs(t, err, jsptr.ErrNotFound)
+			})
+			t.Run("type mismatch is not skipped", func(t *testing.T) {
+				var v string
+				err := jsptr.First(&v, target, "/other/location", "/legacy/location")
+				require.Error(t, err)
+				require.NotErrorIs(t, err, jsptr.ErrNotFound)
+			})
+			t.Run("malformed pointer", func(t *testing.T) {
+				var v string
+				require.Error(t, jsptr.First(&v, target, "no-slash", "/legacy/location"))
+			})
+		})
+	}
+}
+
+func TestPointerRetrieveOrDefault(t *testing.T) {
+	const src = `{"timeout": 30, "name": "svc"}`
+
+	for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("existing value", func(t *testing.T) {
+				ptr, err := jsptr.New("/timeout")
+				require.NoError(t, err)
+
+				var v float64
+				require.NoError(t, ptr.RetrieveOrDefault(&v, target, 10.0))
+				require.Equal(t, 30.0, v)
+			})
+			t.Run("missing value", func(t *testing.T) {
+				ptr, err := jsptr.New("/retries")
+				require.NoError(t, err)
+
+				var v float64
+				require.NoError(t, ptr.RetrieveOrDefault(&v, target, 3.0))
+				require.Equal(t, 3.0, v)
+			})
+			t.Run("type mismatch", func(t *testing.T) {
+				ptr, err := jsptr.New("/name")
+				require.NoError(t, err)
+
+				var v float64
+				require.Error(t, ptr.RetrieveOrDefault(&v, target, 3.0))
+			})
+			t.Run("incompatible default", func(t *testing.T) {
+				ptr, err := jsptr.New("/retries")
+				require.NoError(t, err)
+
+				var v float64
+				require.Error(t, ptr.RetrieveOrDefault(&v, target, "three"))
+			})
+		})
+	}
+}
+
+func TestPointerLookup(t *testing.T) {
+	const src = `{"user": {"name": "alice", "tags": ["a", "b"]}}`
+
+	for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+		t.Run(name, func(t *testing.T) {
+			testcases := []struct {
+				Name    string
+				Pointer string
+				Found   bool
+				Error   bool
+				Want    string
+			}{
+				{Name: "existing value", Pointer: "/user/name", Found: true, Want: "alice"},
+				{Name: "missing property", Pointer: "/user/email", Want: "untouched"},
+				{Name: "missing parent", Pointer: "/account/name", Want: "untouched"},
+				{Name: "index out of bounds", Pointer: "/user/tags/5", Want: "untouched"},
+				{Name: "type mismatch", Pointer: "/user/tags", Error: true},
+				{Name: "scalar parent", Pointer: "/user/name/first", Error: true},
+			}
+
+			for _, tc := range testcases {
+				t.Run(tc.Name, func(t *testing.T) {
+					ptr, err := jsptr.New(tc.Pointer)
+					require.NoError(t, err)
+
+					v := "untouched"
+					found, err := ptr.Lookup(&v, target)
+					if tc.Error {
+						require.Error(t, err)
+						require.False(t, found)
+						return
+					}
+					require.NoError(t, err)
+					require.Equal(t, tc.Found, found)
+					require.Equal(t, tc.Want, v)
+				})
+			}
+		})
+	}
+}
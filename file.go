@@ -0,0 +1,173 @@
+package jsptr
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// RetrieveFile reads the JSON file at path from fsys, and retrieves the
+// value at the location specified by the JSON pointer `spec`.
+//
+// If fsys is nil, path is a path in the file system of the operating
+// system, and may be absolute. Use a FileCache to avoid re-reading and
+// re-parsing files that are accessed repeatedly.
+func RetrieveFile(dst any, fsys fs.FS, path, spec string, options ...RetrieveOption) error {
+	ptr, err := New(spec)
+	if err != nil {
+		return err
+	}
+
+	cfg := newRetrieveConfig(options)
+	if cfg.maxDocumentSize > 0 {
+		fi, err := fs.Stat(fileSystem(fsys), path)
+		if err != nil {
+			return fmt.Errorf("failed to stat file: %w", err)
+		}
+		if fi.Size() > cfg.maxDocumentSize {
+			return errDocumentTooLarge(cfg.maxDocumentSize)
+		}
+	}
+
+	data, err := fs.ReadFile(fileSystem(fsys), path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if err := cfg.checkDocumentSize(len(data)); err != nil {
+		return err
+	}
+	data, err = cfg.resolveDuplicates(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := retrieveFromJSON(dst, data, ptr.tokens, cfg); err != nil {
+		return fmt.Errorf("failed to retrieve '%s' from %s: %w", spec, path, err)
+	}
+	return nil
+}
+
+// FileCache keeps parsed JSON files in memory, so that retrieving values
+// from the same files repeatedly does not require reading and parsing
+// them every time. A file is read again if its size or modification time
+// changes.
+//
+// Files are parsed without checking for duplicate member names, so
+// retrievals using WithDuplicateKeys fail. Use RetrieveFile instead.
+//
+// A FileCache is safe for concurrent use.
+type FileCache struct {
+	fsys    fs.FS
+	mu      sync.Mutex
+	entries map[string]*fileEntry
+}
+
+type fileEntry struct {
+	mu      sync.Mutex
+	doc     *Document
+	size    int64
+	modTime time.Time
+}
+
+// NewFileCache creates a new FileCache for files in fsys. If fsys is nil,
+// files are read from the file system of the operating system.
+func NewFileCache(fsys fs.FS) *FileCache {
+	return &FileCache{
+		fsys:    fileSystem(fsys),
+		entries: make(map[string]*fileEntry),
+	}
+}
+
+// Retrieve retrieves the value at the location specified by the JSON
+// pointer `spec` from the JSON file at path
+func (c *FileCache) Retrieve(dst any, path, spec string, options ...RetrieveOption) error {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return err
+	}
+
+	entry := c.entry(path)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if err := entry.load(c.fsys, path); err != nil {
+		return err
+	}
+	cfg := newRetrieveConfig(options)
+	err = retrieveConverted(dst, cfg, func(dst any) error {
+		return entry.doc.retrieveTokens(dst, tokens, cfg)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve '%s' from %s: %w", spec, path, err)
+	}
+	return nil
+}
+
+// Forget removes the file at path from the cache
+func (c *FileCache) Forget(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, path)
+}
+
+// Clear removes all files from the cache
+func (c *FileCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+func (c *FileCache) entry(path string) *fileEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok {
+		entry = &fileEntry{}
+		c.entries[path] = entry
+	}
+	return entry
+}
+
+// load (re-)reads the file at path if it has not been read yet, or if it
+// has changed since it was last read. entry.mu must be held
+func (entry *fileEntry) load(fsys fs.FS, path string) error {
+	fi, err := fs.Stat(fsys, path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	if entry.doc != nil && fi.Size() == entry.size && fi.ModTime().Equal(entry.modTime) {
+		observeCache("file", true)
+		return nil
+	}
+	observeCache("file", false)
+
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	doc, err := ParseJSON(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	entry.doc = doc
+	entry.size = fi.Size()
+	entry.modTime = fi.ModTime()
+	return nil
+}
+
+// osFS gives access to the file system of the operating system. Unlike
+// os.DirFS, it accepts any path that os.Open does, including absolute ones
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func fileSystem(fsys fs.FS) fs.FS {
+	if fsys == nil {
+		return osFS{}
+	}
+	return fsys
+}
@@ -0,0 +1,76 @@
+package jsptr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrieveFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/app.json": &fstest.MapFile{Data: []byte(`{"server": {"port": 8080}}`)},
+		"broken.json":     &fstest.MapFile{Data: []byte(`{"server":`)},
+	}
+
+	t.Run("fs.FS", func(t *testing.T) {
+		var port int
+		require.NoError(t, jsptr.RetrieveFile(&port, fsys, "config/app.json", "/server/port"))
+		require.Equal(t, 8080, port)
+
+		require.ErrorIs(t, jsptr.RetrieveFile(&port, fsys, "config/app.json", "/server/host"), jsptr.ErrNotFound)
+		require.Error(t, jsptr.RetrieveFile(&port, fsys, "missing.json", "/server/port"))
+		require.Error(t, jsptr.RetrieveFile(&port, fsys, "broken.json", "/server/port"))
+		require.Error(t, jsptr.RetrieveFile(&port, fsys, "config/app.json", "server"))
+	})
+	t.Run("OS file system", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"name": "app"}`), 0o600))
+
+		var name string
+		require.NoError(t, jsptr.RetrieveFile(&name, nil, path, "/name"))
+		require.Equal(t, "app", name)
+	})
+}
+
+func TestFileCache(t *testing.T) {
+	modTime := time.Now()
+	fsys := fstest.MapFS{
+		"app.json": &fstest.MapFile{Data: []byte(`{"version": 1}`), ModTime: modTime},
+	}
+	cache := jsptr.NewFileCache(fsys)
+
+	var version int
+	require.NoError(t, cache.Retrieve(&version, "app.json", "/version"))
+	require.Equal(t, 1, version)
+
+	// Files that have not changed are not read again
+	fsys["app.json"].Data = []byte(`{"version": 2}`)
+	require.NoError(t, cache.Retrieve(&version, "app.json", "/version"))
+	require.Equal(t, 1, version)
+
+	// Changes in modification time cause the file to be read again
+	fsys["app.json"].ModTime = modTime.Add(time.Second)
+	require.NoError(t, cache.Retrieve(&version, "app.json", "/version"))
+	require.Equal(t, 2, version)
+
+	// So do changes in size
+	fsys["app.json"].Data = []byte(`{"version": 30}`)
+	require.NoError(t, cache.Retrieve(&version, "app.json", "/version"))
+	require.Equal(t, 30, version)
+
+	// Forgotten files are read again
+	fsys["app.json"].Data = []byte(`{"version": 40}`)
+	cache.Forget("app.json")
+	require.NoError(t, cache.Retrieve(&version, "app.json", "/version"))
+	require.Equal(t, 40, version)
+
+	require.ErrorIs(t, cache.Retrieve(&version, "app.json", "/missing"), jsptr.ErrNotFound)
+
+	delete(fsys, "app.json")
+	require.Error(t, cache.Retrieve(&version, "app.json", "/version"))
+}
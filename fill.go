@@ -0,0 +1,52 @@
+package jsptr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Fill sets each of the values of `values` at the location specified by
+// its JSON pointer key in doc, as Set does, and returns the resulting
+// document. This can be used to stamp values into a template document.
+//
+// Either all values are set, or none are: if one of them cannot be set,
+// doc is left as it was. Values are set in the order of their locations,
+// so that a value set at a location within another value set by the same
+// call is set within that value.
+//
+// The same types of documents and options as those accepted by Set can be
+// used, and the same caveats apply.
+func Fill(doc any, values map[string]any, options ...SetOption) (any, error) {
+	type fill struct {
+		spec   string
+		tokens []string
+	}
+
+	fills := make([]fill, 0, len(values))
+	for spec := range values {
+		tokens, err := parseTokens(spec)
+		if err != nil {
+			return nil, err
+		}
+		fills = append(fills, fill{spec: spec, tokens: tokens})
+	}
+	slices.SortFunc(fills, func(a, b fill) int {
+		return comparePaths(a.tokens, b.tokens)
+	})
+
+	parents := setParents(options)
+	return edit(doc, func(ed editor, root any) (any, error) {
+		for _, f := range fills {
+			tokens := f.tokens
+			if parents {
+				tokens = slices.Clone(tokens)
+				root = ed.makeParents(root, tokens)
+			}
+			var err error
+			if root, err = ed.mutate(root, tokens, editSet, values[f.spec]); err != nil {
+				return nil, fmt.Errorf("failed to set '%s': %w", f.spec, err)
+			}
+		}
+		return root, nil
+	})
+}
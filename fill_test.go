@@ -0,0 +1,57 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFill(t *testing.T) {
+	const manifest = `{"kind":"Deployment","spec":{"image":"","replicas":0,"ports":[]}}`
+
+	t.Run("JSON text", func(t *testing.T) {
+		result, err := jsptr.Fill(manifest, map[string]any{
+			"/spec/image":    "app:1.2.3",
+			"/spec/replicas": 3,
+			"/spec/ports/-":  8080,
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"kind":"Deployment","spec":{"image":"app:1.2.3","replicas":3,"ports":[8080]}}`, result)
+	})
+	t.Run("nested locations", func(t *testing.T) {
+		result, err := jsptr.Fill(map[string]any{}, map[string]any{
+			"/meta/name":       "x",
+			"/meta":            map[string]any{"labels": map[string]any{}},
+			"/meta/labels/app": "y",
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"meta": map[string]any{"name": "x", "labels": map[string]any{"app": "y"}}}, result)
+	})
+	t.Run("WithParents", func(t *testing.T) {
+		result, err := jsptr.Fill(nil, map[string]any{
+			"/spec/template/image": "a",
+			"/spec/env/0/name":     "b",
+		}, jsptr.WithParents(true))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"spec": map[string]any{
+			"template": map[string]any{"image": "a"},
+			"env":      []any{map[string]any{"name": "b"}},
+		}}, result)
+	})
+	t.Run("all or nothing", func(t *testing.T) {
+		doc := decodeJSON(t, manifest)
+		_, err := jsptr.Fill(doc, map[string]any{
+			"/kind":         "Service",
+			"/spec/image":   "app",
+			"/status/ready": true,
+		})
+		require.ErrorIs(t, err, jsptr.ErrNotFound)
+		require.ErrorContains(t, err, "failed to set '/status/ready'")
+		require.Equal(t, decodeJSON(t, manifest), doc)
+	})
+	t.Run("invalid pointer", func(t *testing.T) {
+		_, err := jsptr.Fill(manifest, map[string]any{"kind": "x"})
+		require.Error(t, err)
+	})
+}
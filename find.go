@@ -0,0 +1,45 @@
+package jsptr
+
+// Find walks doc, and returns the pointers to the values for which fn
+// returns true, in the order in which Walk visits them. Objects and arrays
+// are passed to fn as well as the values within them, so that for example
+// empty objects can be found.
+//
+// The same types of targets as those accepted by Walk can be used, and
+// values are passed to fn in the same way.
+func Find(doc any, fn func(ptr string, v any) bool) ([]string, error) {
+	var found []string
+	err := Walk(doc, func(ptr string, v any) error {
+		if fn(ptr, v) {
+			found = append(found, ptr)
+		}
+		return nil
+	}, WithContainers(true))
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// LocateValue returns the pointers to the locations of doc whose value is
+// equal to needle, in the order in which Walk visits them. This can be
+// used to find out where a value came from, for example in a document
+// that was merged from several sources.
+//
+// doc may be JSON text as []byte or string, a *Document, or Go values.
+// Values are compared by their JSON representation: numbers are compared
+// by value, and the order of object members is not significant. To look
+// for a value given as JSON text, pass it as a json.RawMessage.
+func LocateValue(doc, needle any) ([]string, error) {
+	root, err := genericValue(doc, editValuesConfig)
+	if err != nil {
+		return nil, err
+	}
+	want, err := comparableValue(needle)
+	if err != nil {
+		return nil, err
+	}
+	return Find(root, func(_ string, v any) bool {
+		return equalValues(v, want)
+	})
+}
@@ -0,0 +1,98 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	const doc = `{"a": null, "b": {"c": "", "d": [null, "x", {}]}, "e~f": ""}`
+
+	testcases := []struct {
+		Name     string
+		Fn       func(string, any) bool
+		Expected []string
+	}{
+		{
+			Name:     "nulls",
+			Fn:       func(_ string, v any) bool { return v == nil },
+			Expected: []string{"/a", "/b/d/0"},
+		},
+		{
+			Name:     "empty strings",
+			Fn:       func(_ string, v any) bool { return v == "" },
+			Expected: []string{"/b/c", "/e~0f"},
+		},
+		{
+			Name: "empty objects",
+			Fn: func(_ string, v any) bool {
+				m, ok := v.(map[string]any)
+				return ok && len(m) == 0
+			},
+			Expected: []string{"/b/d/2"},
+		},
+		{
+			Name:     "by pointer",
+			Fn:       func(ptr string, _ any) bool { return ptr == "" },
+			Expected: []string{""},
+		},
+		{
+			Name: "none",
+			Fn:   func(string, any) bool { return false },
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			found, err := jsptr.Find(doc, tc.Fn)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, found)
+		})
+	}
+
+	t.Run("Go values", func(t *testing.T) {
+		found, err := jsptr.Find(map[string]any{"a": 1, "b": []int{2, 3}}, func(_ string, v any) bool {
+			_, ok := v.(int)
+			return ok
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/a", "/b/0", "/b/1"}, found)
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := jsptr.Find(`{"a":`, func(string, any) bool { return true })
+		require.Error(t, err)
+	})
+}
+
+func TestLocateValue(t *testing.T) {
+	const doc = `{"z": {"host": "db", "port": 5432}, "a": [5432, {"port": 5432.0, "host": "db"}], "b": "5432"}`
+
+	testcases := []struct {
+		Name     string
+		Doc      any
+		Needle   any
+		Expected []string
+	}{
+		{Name: "number", Doc: doc, Needle: 5432, Expected: []string{"/z/port", "/a/0", "/a/1/port"}},
+		{Name: "string", Doc: doc, Needle: "5432", Expected: []string{"/b"}},
+		{Name: "object", Doc: doc, Needle: map[string]any{"port": 5432, "host": "db"}, Expected: []string{"/z", "/a/1"}},
+		{Name: "JSON text", Doc: doc, Needle: json.RawMessage(`{"host":"db","port":5.432e3}`), Expected: []string{"/z", "/a/1"}},
+		{Name: "root", Doc: `[1]`, Needle: []int{1}, Expected: []string{""}},
+		{Name: "missing", Doc: doc, Needle: false},
+		{Name: "Go values", Doc: map[string]any{"a": []int{1, 2}, "b": []float64{1, 2}}, Needle: []any{1, 2.0}, Expected: []string{"/a", "/b"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			found, err := jsptr.LocateValue(tc.Doc, tc.Needle)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, found)
+		})
+	}
+
+	_, err := jsptr.LocateValue(`{`, 1)
+	require.Error(t, err)
+	_, err = jsptr.LocateValue(`{}`, func() {})
+	require.Error(t, err)
+}
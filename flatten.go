@@ -0,0 +1,132 @@
+package jsptr
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// Flatten converts target into a map whose keys are JSON pointers, and
+// whose values are the leaf values found at those locations. Empty
+// objects and arrays are included as values, so that the original
+// structure can be restored using Unflatten.
+//
+// For example, `{"a": [{"b": 1}], "c": {}}` is flattened to
+// `{"/a/0/b": 1, "/c": map[string]any{}}`
+//
+// Options such as WithNumberMode control how the values of JSON documents
+// are converted, as they do for Walk. WithContainers has no effect.
+func Flatten(target any, options ...WalkOption) (map[string]any, error) {
+	// Only empty containers are recorded, so that containers with
+	// members are not converted into Go values just to be discarded
+	_, cfg := walkOptions(options)
+	result := make(map[string]any)
+	err := walkTarget(target, func(ptr string, v any) error {
+		result[ptr] = v
+		return nil
+	}, visitEmptyContainers, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Unflatten reconstructs a document from a map whose keys are JSON
+// pointers, such as the one produced by Flatten. Objects are created as
+// map[string]any, and arrays as []any. A container is created as an
+// array only if its members are exactly the indices 0 through n-1.
+//
+// Unflatten returns nil if flat is empty. An error is returned if a
+// pointer is malformed, or if a location is assigned both a value and
+// members.
+func Unflatten(flat map[string]any) (any, error) {
+	root := &flatNode{}
+	for _, spec := range slices.Sorted(maps.Keys(flat)) {
+		tokens, err := parseTokens(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pointer '%s': %w", spec, err)
+		}
+
+		node := root
+		for _, token := range tokens {
+			node = node.child(token)
+		}
+		node.value = flat[spec]
+		node.hasValue = true
+	}
+
+	return root.build("")
+}
+
+// flatNode is used to gather values before the containers are built,
+// as whether a container is an object or an array depends on all of
+// its members
+type flatNode struct {
+	value    any
+	hasValue bool
+	children map[string]*flatNode
+}
+
+func (n *flatNode) child(token string) *flatNode {
+	if n.children == nil {
+		n.children = make(map[string]*flatNode)
+	}
+	c, ok := n.children[token]
+	if !ok {
+		c = &flatNode{}
+		n.children[token] = c
+	}
+	return c
+}
+
+func (n *flatNode) build(ptr string) (any, error) {
+	if len(n.children) == 0 {
+		return n.value, nil
+	}
+	if n.hasValue {
+		// Empty containers may coexist with their own members
+		if !isEmptyNode(n.value) {
+			return nil, fmt.Errorf("location '%s' has both a value and members", ptr)
+		}
+	}
+
+	if n.isArray() {
+		result := make([]any, len(n.children))
+		for i := range result {
+			token := strconv.Itoa(i)
+			v, err := n.children[token].build(ptr + "/" + token)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = v
+		}
+		return result, nil
+	}
+
+	result := make(map[string]any, len(n.children))
+	for token, c := range n.children {
+		v, err := c.build(ptr + "/" + escapeToken(token))
+		if err != nil {
+			return nil, err
+		}
+		result[token] = v
+	}
+	return result, nil
+}
+
+// isArray returns true if the children of n are keyed by 0 through n-1
+func (n *flatNode) isArray() bool {
+	for i := range len(n.children) {
+		if _, ok := n.children[strconv.Itoa(i)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmptyNode returns true if node is a container without members
+func isEmptyNode(node any) bool {
+	count, ok := memberCount(node)
+	return ok && count == 0
+}
@@ -0,0 +1,79 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	const src = `{"a": [{"b": 1}, "x"], "c": {}, "d": [], "e~f": {"g/h": null}, "i": true}`
+
+	flat, err := jsptr.Flatten([]byte(src))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"/a/0/b":     1.0,
+		"/a/1":       "x",
+		"/c":         map[string]any{},
+		"/d":         []any{},
+		"/e~0f/g~1h": nil,
+		"/i":         true,
+	}, flat)
+
+	doc, err := jsptr.Unflatten(flat)
+	require.NoError(t, err)
+	require.Equal(t, decodeJSON(t, src), doc)
+
+	t.Run("with number mode", func(t *testing.T) {
+		flat, err := jsptr.Flatten([]byte(`{"a": [1, 2.5], "b": {"c": 12345678901234567890}}`), jsptr.WithNumberMode(jsptr.NumberJSONNumber))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"/a/0": json.Number("1"),
+			"/a/1": json.Number("2.5"),
+			"/b/c": json.Number("12345678901234567890"),
+		}, flat)
+	})
+	t.Run("Go values", func(t *testing.T) {
+		// Empty containers are recorded regardless of WithContainers
+		flat, err := jsptr.Flatten(map[string]any{"a": []int{}, "b": map[string]int{"c": 1}}, jsptr.WithContainers(false))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"/a": []int{}, "/b/c": 1}, flat)
+	})
+	t.Run("scalar root", func(t *testing.T) {
+		flat, err := jsptr.Flatten(42)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"": 42}, flat)
+
+		doc, err := jsptr.Unflatten(flat)
+		require.NoError(t, err)
+		require.Equal(t, 42, doc)
+	})
+}
+
+func TestUnflatten(t *testing.T) {
+	t.Run("sparse indices make an object", func(t *testing.T) {
+		doc, err := jsptr.Unflatten(map[string]any{"/list/0": "a", "/list/2": "c"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"list": map[string]any{"0": "a", "2": "c"}}, doc)
+	})
+	t.Run("contiguous indices make an array", func(t *testing.T) {
+		doc, err := jsptr.Unflatten(map[string]any{"/1": "b", "/0": "a"})
+		require.NoError(t, err)
+		require.Equal(t, []any{"a", "b"}, doc)
+	})
+	t.Run("conflicting value and members", func(t *testing.T) {
+		_, err := jsptr.Unflatten(map[string]any{"/a": 1, "/a/b": 2})
+		require.Error(t, err)
+	})
+	t.Run("invalid pointer", func(t *testing.T) {
+		_, err := jsptr.Unflatten(map[string]any{"a": 1})
+		require.Error(t, err)
+	})
+	t.Run("empty", func(t *testing.T) {
+		doc, err := jsptr.Unflatten(map[string]any{})
+		require.NoError(t, err)
+		require.Nil(t, doc)
+	})
+}
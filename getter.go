@@ -0,0 +1,22 @@
+package jsptr
+
+import "reflect"
+
+// Getter is implemented by containers that expose their members through
+// an accessor rather than as a map, such as the claims of a JWT. Pointers
+// are evaluated against a Getter token by token, by calling Get with each
+// token in turn, in preference to marshaling the container to JSON.
+type Getter interface {
+	Get(name string) (any, bool)
+}
+
+var getterType = reflect.TypeFor[Getter]()
+
+// getMember returns the member of g named token
+func getMember(g Getter, token string) (any, error) {
+	val, ok := g.Get(token)
+	if !ok {
+		return nil, errNotFound("property '%s' not found", token)
+	}
+	return val, nil
+}
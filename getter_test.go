@@ -0,0 +1,64 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+// claims exposes its members through Get, and omits private members
+// when marshaled
+type claims struct {
+	values map[string]any
+}
+
+func (c *claims) Get(name string) (any, bool) {
+	v, ok := c.values[name]
+	return v, ok
+}
+
+func (c *claims) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"sub": c.values["sub"]})
+}
+
+type claimsHolder struct {
+	Claims *claims `json:"claims"`
+}
+
+func TestGetter(t *testing.T) {
+	c := &claims{values: map[string]any{
+		"sub":     "alice",
+		"private": map[string]any{"roles": []any{"admin", "dev"}},
+	}}
+
+	testcases := []struct {
+		Spec     string
+		Target   any
+		Expected any
+		Error    string
+	}{
+		{Spec: "/sub", Target: c, Expected: "alice"},
+		{Spec: "/private/roles/1", Target: c, Expected: "dev"},
+		{Spec: "/missing", Target: c, Error: "property 'missing' not found"},
+		{Spec: "/token/private/roles/0", Target: map[string]any{"token": c}, Expected: "admin"},
+		{Spec: "/claims/private/roles/0", Target: &claimsHolder{Claims: c}, Expected: "admin"},
+		{Spec: "/claims/sub", Target: &claimsHolder{}, Error: "cannot index into nil"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Spec, func(t *testing.T) {
+			for range 2 {
+				var v any
+				err := jsptr.MustNew(tc.Spec).Retrieve(&v, tc.Target)
+				if tc.Error != "" {
+					require.ErrorContains(t, err, tc.Error)
+					continue
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.Expected, v)
+			}
+		})
+	}
+}
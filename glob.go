@@ -0,0 +1,50 @@
+package jsptr
+
+// Glob is a pattern that concrete JSON pointers can be matched against,
+// such as "/users/*/email". Unlike pointers created with extensions, a
+// Glob is not evaluated against documents: it only matches pointers,
+// which makes it suitable for rules in redaction and access control
+// configurations.
+//
+// A pattern is written like a pointer, where the following tokens have
+// a special meaning:
+//
+//   - "*" matches any single token
+//   - "**" matches zero or more tokens, so that "/**/email" matches
+//     "/email" and "/users/0/email"
+//
+// As with extensions, tokens that are literally "*" or "**" cannot be
+// matched exactly.
+type Glob struct {
+	pattern  string
+	segments []segment
+}
+
+// NewGlob compiles the pattern into a Glob
+func NewGlob(pattern string) (*Glob, error) {
+	ptr, err := New(pattern, WithExtensions(true))
+	if err != nil {
+		return nil, err
+	}
+	return &Glob{pattern: pattern, segments: ptr.expandSegments()}, nil
+}
+
+// Pattern returns the original pattern
+func (g *Glob) Pattern() string {
+	return g.pattern
+}
+
+// Match reports whether the pointer `spec` is matched by the pattern.
+// Invalid pointers never match.
+func (g *Glob) Match(spec string) bool {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return false
+	}
+	return matchSegments(g.segments, tokens)
+}
+
+// MatchPointer reports whether ptr is matched by the pattern
+func (g *Glob) MatchPointer(ptr *Pointer) bool {
+	return matchSegments(g.segments, ptr.tokens)
+}
@@ -0,0 +1,71 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlob(t *testing.T) {
+	testcases := []struct {
+		Pattern string
+		Match   []string
+		NoMatch []string
+	}{
+		{
+			Pattern: "/users/*/email",
+			Match:   []string{"/users/0/email", "/users/a~1b/email"},
+			NoMatch: []string{"/users/email", "/users/0/1/email", "/users/0/email/x", "/users/0/name", "users/0/email"},
+		},
+		{
+			Pattern: "/**/password",
+			Match:   []string{"/password", "/a/password", "/a/0/b/password"},
+			NoMatch: []string{"/password/x", "/passwords", ""},
+		},
+		{
+			Pattern: "/a/**",
+			Match:   []string{"/a", "/a/b", "/a/b/c"},
+			NoMatch: []string{"/b", ""},
+		},
+		{
+			Pattern: "/*",
+			Match:   []string{"/a", "/"},
+			NoMatch: []string{"", "/a/b"},
+		},
+		{
+			Pattern: "/x~1y/*/~0",
+			Match:   []string{"/x~1y/1/~0"},
+			NoMatch: []string{"/x/y/1/~0", "/x~1y/1/~1"},
+		},
+		{
+			// Union tokens are not supported by globs, and are matched
+			// literally
+			Pattern: "/{a,b}",
+			Match:   []string{"/{a,b}"},
+			NoMatch: []string{"/a", "/b"},
+		},
+		{
+			Pattern: "",
+			Match:   []string{""},
+			NoMatch: []string{"/"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Pattern, func(t *testing.T) {
+			g, err := jsptr.NewGlob(tc.Pattern)
+			require.NoError(t, err)
+			require.Equal(t, tc.Pattern, g.Pattern())
+			for _, spec := range tc.Match {
+				require.True(t, g.Match(spec), spec)
+				require.True(t, g.MatchPointer(jsptr.MustNew(spec)), spec)
+			}
+			for _, spec := range tc.NoMatch {
+				require.False(t, g.Match(spec), spec)
+			}
+		})
+	}
+
+	_, err := jsptr.NewGlob("users/*")
+	require.Error(t, err)
+}
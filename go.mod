@@ -4,6 +4,7 @@ go 1.24.4
 
 require (
 	github.com/lestrrat-go/blackmagic v1.0.4
+	github.com/lestrrat-go/option v1.0.1
 	github.com/stretchr/testify v1.10.0
 	github.com/valyala/fastjson v1.6.4
 )
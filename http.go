@@ -0,0 +1,145 @@
+package jsptr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodySize is the maximum number of bytes that RetrieveHTTPBody
+// and RetrieveHTTPRequestBody read from a body, unless specified
+// otherwise using WithMaxBodySize
+const DefaultMaxBodySize = 10 << 20
+
+// RetrieveHTTPBody retrieves the value at the location specified by the
+// JSON pointer `spec` from the JSON body of resp.
+//
+// The body is rejected if the Content-Type header specifies a media type
+// other than application/json or one with a "+json" suffix, or if it is
+// larger than the maximum size (see WithMaxBodySize). Once read, resp.Body
+// is replaced with a reader that yields the same contents, so that the
+// body can still be read by other code.
+func RetrieveHTTPBody(dst any, resp *http.Response, spec string, options ...HTTPOption) error {
+	return retrieveHTTPBody(dst, resp.Header, &resp.Body, spec, options)
+}
+
+// RetrieveHTTPRequestBody works like RetrieveHTTPBody, but retrieves the
+// value from the body of req. This is useful in handlers that need to
+// inspect a field of the payload before passing the request on.
+func RetrieveHTTPRequestBody(dst any, req *http.Request, spec string, options ...HTTPOption) error {
+	return retrieveHTTPBody(dst, req.Header, &req.Body, spec, options)
+}
+
+// ParseHTTPRequestBody parses the JSON body of req into a Document, so
+// that any number of pointers can be evaluated against it, for example
+// using RetrieveMulti. The body is checked and restored in the same way
+// as by RetrieveHTTPBody.
+//
+// Besides WithMaxBodySize, the options that control how documents are
+// parsed, such as WithDuplicateKeys and WithMaxDocumentSize, are accepted.
+// Other options are rejected with an error, as they only apply to the
+// retrieval of values.
+func ParseHTTPRequestBody(req *http.Request, options ...HTTPOption) (*Document, error) {
+	limit, retrieveOptions := splitHTTPOptions(options)
+	parseOptions := make([]ParseOption, 0, len(retrieveOptions))
+	for _, option := range retrieveOptions {
+		po, ok := option.(ParseOption)
+		if !ok {
+			return nil, fmt.Errorf("unsupported option %T: only options that control parsing can be passed to ParseHTTPRequestBody", option.Ident())
+		}
+		parseOptions = append(parseOptions, po)
+	}
+
+	data, err := readHTTPBody(req.Header, &req.Body, limit)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSON(data, parseOptions...)
+}
+
+func retrieveHTTPBody(dst any, header http.Header, body *io.ReadCloser, spec string, options []HTTPOption) error {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return err
+	}
+	limit, retrieveOptions := splitHTTPOptions(options)
+	data, err := readHTTPBody(header, body, limit)
+	if err != nil {
+		return err
+	}
+	return retrieveFromJSON(dst, data, tokens, newRetrieveConfig(retrieveOptions))
+}
+
+// splitHTTPOptions returns the maximum body size specified by options,
+// along with the options that control the retrieval itself
+func splitHTTPOptions(options []HTTPOption) (int64, []RetrieveOption) {
+	limit := int64(DefaultMaxBodySize)
+	var retrieveOptions []RetrieveOption
+	for _, option := range options {
+		if ro, ok := option.(RetrieveOption); ok {
+			retrieveOptions = append(retrieveOptions, ro)
+			continue
+		}
+		switch option.Ident() {
+		case identMaxBodySize{}:
+			if v := option.Value().(int64); v > 0 {
+				limit = v
+			}
+		}
+	}
+	return limit, retrieveOptions
+}
+
+// readHTTPBody reads the JSON body of a request or a response, and
+// replaces it with one that yields the same contents
+func readHTTPBody(header http.Header, body *io.ReadCloser, limit int64) ([]byte, error) {
+	if err := checkContentType(header.Get("Content-Type")); err != nil {
+		return nil, err
+	}
+	if *body == nil || *body == http.NoBody {
+		return nil, fmt.Errorf("empty body")
+	}
+
+	// Read one byte more than the limit to detect oversized bodies.
+	// Whatever happens, the body is restored so that it can be read again
+	original := *body
+	data, err := io.ReadAll(io.LimitReader(original, limit+1))
+	*body = rebufferedBody{
+		Reader: io.MultiReader(bytes.NewReader(data), original),
+		Closer: original,
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("body exceeds the maximum size of %d bytes", limit)
+	}
+	return data, nil
+}
+
+// rebufferedBody replays the part of a body that has already been read,
+// followed by the rest of the original body
+type rebufferedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// checkContentType returns an error if contentType is present, and does
+// not specify a JSON media type
+func checkContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	return fmt.Errorf("unsupported content type %q", mediaType)
+}
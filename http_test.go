@@ -0,0 +1,146 @@
+package jsptr_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrieveHTTPBody(t *testing.T) {
+	const payload = `{"event": {"type": "push", "repository": {"name": "jsptr"}}}`
+
+	newResponse := func(contentType, body string) *http.Response {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		if contentType != "" {
+			resp.Header.Set("Content-Type", contentType)
+		}
+		return resp
+	}
+
+	t.Run("response", func(t *testing.T) {
+		resp := newResponse("application/json; charset=utf-8", payload)
+
+		var name string
+		require.NoError(t, jsptr.RetrieveHTTPBody(&name, resp, "/event/repository/name"))
+		require.Equal(t, "jsptr", name)
+
+		// The body can still be read
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, payload, string(body))
+		require.NoError(t, resp.Body.Close())
+	})
+	t.Run("request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/vnd.github+json")
+
+		var typ string
+		require.NoError(t, jsptr.RetrieveHTTPRequestBody(&typ, req, "/event/type"))
+		require.Equal(t, "push", typ)
+
+		// Downstream handlers see the whole body
+		var name string
+		require.NoError(t, jsptr.RetrieveHTTPRequestBody(&name, req, "/event/repository/name"))
+		require.Equal(t, "jsptr", name)
+	})
+	t.Run("parsed request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		doc, err := jsptr.ParseHTTPRequestBody(req)
+		require.NoError(t, err)
+		v, err := doc.Get("/event/type")
+		require.NoError(t, err)
+		require.Equal(t, "push", v)
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, payload, string(body))
+
+		req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		_, err = jsptr.ParseHTTPRequestBody(req, jsptr.WithMaxBodySize(10))
+		require.ErrorContains(t, err, "maximum size")
+
+		// Options that control parsing are applied to the document
+		const dup = `{"role": "user", "role": "admin"}`
+		req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(dup))
+		_, err = jsptr.ParseHTTPRequestBody(req, jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError))
+		require.ErrorIs(t, err, jsptr.ErrDuplicateKey)
+
+		req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		_, err = jsptr.ParseHTTPRequestBody(req, jsptr.WithMaxDocumentSize(10))
+		require.ErrorIs(t, err, jsptr.ErrLimitExceeded)
+
+		// Options that only apply to retrievals are rejected
+		req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		_, err = jsptr.ParseHTTPRequestBody(req, jsptr.WithNumberMode(jsptr.NumberInt64))
+		require.ErrorContains(t, err, "unsupported option")
+	})
+	t.Run("content types", func(t *testing.T) {
+		testcases := []struct {
+			ContentType string
+			Error       bool
+		}{
+			{ContentType: ""},
+			{ContentType: "application/json"},
+			{ContentType: "application/problem+json"},
+			{ContentType: "text/plain", Error: true},
+			{ContentType: "application/x-www-form-urlencoded", Error: true},
+			{ContentType: "invalid/;;", Error: true},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.ContentType, func(t *testing.T) {
+				var v any
+				err := jsptr.RetrieveHTTPBody(&v, newResponse(tc.ContentType, payload), "/event/type")
+				if tc.Error {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, "push", v)
+			})
+		}
+	})
+	t.Run("size limit", func(t *testing.T) {
+		resp := newResponse("application/json", payload)
+
+		var v any
+		require.Error(t, jsptr.RetrieveHTTPBody(&v, resp, "/event/type", jsptr.WithMaxBodySize(10)))
+
+		// Even oversized bodies can be read in their entirety afterwards
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, payload, string(body))
+
+		resp = newResponse("application/json", payload)
+		require.NoError(t, jsptr.RetrieveHTTPBody(&v, resp, "/event/type", jsptr.WithMaxBodySize(int64(len(payload)))))
+
+		// The limit only applies to HTTP bodies, so it cannot be passed
+		// to functions where it would be ignored
+		_, ok := jsptr.WithMaxBodySize(10).(jsptr.RetrieveOption)
+		require.False(t, ok)
+	})
+	t.Run("retrieve options", func(t *testing.T) {
+		resp := newResponse("application/json", `{"n": 1}`)
+		var v any
+		require.NoError(t, jsptr.RetrieveHTTPBody(&v, resp, "/n", jsptr.WithNumberMode(jsptr.NumberInt64), jsptr.WithMaxBodySize(100)))
+		require.Equal(t, int64(1), v)
+	})
+	t.Run("errors", func(t *testing.T) {
+		var v any
+		require.ErrorIs(t, jsptr.RetrieveHTTPBody(&v, newResponse("", payload), "/missing"), jsptr.ErrNotFound)
+		require.Error(t, jsptr.RetrieveHTTPBody(&v, newResponse("", `{"event":`), "/event"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		require.Error(t, jsptr.RetrieveHTTPRequestBody(&v, req, "/event"))
+	})
+}
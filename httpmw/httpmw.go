@@ -0,0 +1,147 @@
+// Package httpmw provides HTTP middleware that extracts values from JSON
+// request bodies using JSON pointers, and makes them available to
+// downstream handlers through the request context.
+package httpmw
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// Extractor extracts the values at a fixed set of locations from the JSON
+// body of requests. Each location is identified by a name, which is used
+// to look up its value using Value.
+type Extractor struct {
+	names           []string
+	pointers        []*jsptr.Pointer
+	required        bool
+	onError         ErrorHandler
+	parseOptions    []jsptr.HTTPOption
+	retrieveOptions []jsptr.RetrieveOption
+}
+
+// New creates an Extractor for the given locations, which map names to
+// JSON pointer specifications, for example
+//
+//	httpmw.New(map[string]string{"user_id": "/data/user/id"})
+func New(fields map[string]string, options ...Option) (*Extractor, error) {
+	e := &Extractor{
+		names:   slices.Sorted(maps.Keys(fields)),
+		onError: badRequest,
+	}
+	for _, name := range e.names {
+		ptr, err := jsptr.New(fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pointer for '%s': %w", name, err)
+		}
+		e.pointers = append(e.pointers, ptr)
+	}
+
+	for _, option := range options {
+		switch option.Ident() {
+		case identErrorHandler{}:
+			e.onError = option.Value().(ErrorHandler)
+		case identRequired{}:
+			e.required = option.Value().(bool)
+		case identRetrieveOptions{}:
+			e.setRetrieveOptions(option.Value().([]jsptr.HTTPOption))
+		}
+	}
+	return e, nil
+}
+
+// setRetrieveOptions sorts options into those used to read and parse the
+// body, and those used to retrieve the values from it. Options such as
+// WithDuplicateKeys are both
+func (e *Extractor) setRetrieveOptions(options []jsptr.HTTPOption) {
+	e.parseOptions, e.retrieveOptions = nil, nil
+	for _, option := range options {
+		ro, isRetrieve := option.(jsptr.RetrieveOption)
+		if isRetrieve {
+			e.retrieveOptions = append(e.retrieveOptions, ro)
+		}
+		if _, isParse := option.(jsptr.ParseOption); isParse || !isRetrieve {
+			e.parseOptions = append(e.parseOptions, option)
+		}
+	}
+}
+
+// badRequest is the default ErrorHandler. The error is not sent to the
+// client, as it may reveal details of the implementation
+func badRequest(w http.ResponseWriter, _ *http.Request, _ error) {
+	http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+}
+
+// Extract parses the body of req once, and returns the values at the
+// locations of the Extractor, keyed by their names. Values that cannot be
+// found are omitted, unless WithRequired is specified. The body of req is
+// restored, so that it can be read again.
+func (e *Extractor) Extract(req *http.Request) (map[string]any, error) {
+	doc, err := jsptr.ParseHTTPRequestBody(req, e.parseOptions...)
+	if err != nil {
+		return nil, err
+	}
+	results, err := jsptr.RetrieveMulti(doc, e.pointers, e.retrieveOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(e.names))
+	for i, name := range e.names {
+		result := results[e.pointers[i].Pattern()]
+		if result.Err != nil {
+			if e.required {
+				return nil, fmt.Errorf("failed to extract '%s': %w", name, result.Err)
+			}
+			continue
+		}
+		values[name] = result.Value
+	}
+	return values, nil
+}
+
+// Handler returns middleware that extracts the values from the body of
+// each request, and stores them in the context of the request passed to
+// next. Requests without a body are passed on without any values.
+func (e *Extractor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Body == nil || req.Body == http.NoBody {
+			next.ServeHTTP(w, req)
+			return
+		}
+		values, err := e.Extract(req)
+		if err != nil {
+			e.onError(w, req, err)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), values)))
+	})
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that holds values, as if they had been
+// extracted by an Extractor. This is mostly useful for testing handlers
+func NewContext(ctx context.Context, values map[string]any) context.Context {
+	return context.WithValue(ctx, contextKey{}, values)
+}
+
+// Value returns the value extracted under name for the request whose
+// context is ctx
+func Value(ctx context.Context, name string) (any, bool) {
+	values, _ := ctx.Value(contextKey{}).(map[string]any)
+	v, ok := values[name]
+	return v, ok
+}
+
+// Values returns a copy of all of the values extracted for the request
+// whose context is ctx
+func Values(ctx context.Context) map[string]any {
+	values, _ := ctx.Value(contextKey{}).(map[string]any)
+	return maps.Clone(values)
+}
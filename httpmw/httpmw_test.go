@@ -0,0 +1,143 @@
+package httpmw_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/lestrrat-go/jsptr/httpmw"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractor(t *testing.T) {
+	const payload = `{"data": {"user": {"id": 42, "name": "alice"}, "action": "created"}}`
+
+	fields := map[string]string{
+		"user_id": "/data/user/id",
+		"action":  "/data/action",
+		"team":    "/data/team",
+	}
+
+	// handler records the values and the body seen by the next handler
+	type seen struct {
+		values map[string]any
+		body   string
+	}
+	newHandler := func(t *testing.T, options ...httpmw.Option) (http.Handler, *seen) {
+		t.Helper()
+		e, err := httpmw.New(fields, options...)
+		require.NoError(t, err)
+
+		var s seen
+		return e.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			s.values = httpmw.Values(req.Context())
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			s.body = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		})), &s
+	}
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("Values are stored in the context", func(t *testing.T) {
+		h, s := newHandler(t)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(payload))
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, map[string]any{"user_id": float64(42), "action": "created"}, s.values)
+		require.Equal(t, payload, s.body)
+	})
+	t.Run("Value", func(t *testing.T) {
+		e, err := httpmw.New(fields)
+		require.NoError(t, err)
+		values, err := e.Extract(newRequest(payload))
+		require.NoError(t, err)
+
+		ctx := httpmw.NewContext(t.Context(), values)
+		v, ok := httpmw.Value(ctx, "user_id")
+		require.True(t, ok)
+		require.Equal(t, float64(42), v)
+		_, ok = httpmw.Value(ctx, "team")
+		require.False(t, ok)
+		_, ok = httpmw.Value(t.Context(), "user_id")
+		require.False(t, ok)
+	})
+	t.Run("Retrieve options", func(t *testing.T) {
+		h, s := newHandler(t, httpmw.WithRetrieveOptions(jsptr.WithNumberMode(jsptr.NumberInt64)))
+		h.ServeHTTP(httptest.NewRecorder(), newRequest(payload))
+		require.Equal(t, int64(42), s.values["user_id"])
+
+		h, _ = newHandler(t, httpmw.WithRetrieveOptions(jsptr.WithMaxBodySize(10)))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(payload))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+	t.Run("Duplicate keys", func(t *testing.T) {
+		const dup = `{"data": {"user": {"id": 1, "id": 42}, "action": "created"}}`
+
+		h, _ := newHandler(t, httpmw.WithRetrieveOptions(jsptr.WithDuplicateKeys(jsptr.DuplicateKeysError)))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(dup))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		h, s := newHandler(t, httpmw.WithRetrieveOptions(jsptr.WithDuplicateKeys(jsptr.DuplicateKeysLast)))
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(dup))
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, float64(42), s.values["user_id"])
+	})
+	t.Run("Required values", func(t *testing.T) {
+		h, s := newHandler(t, httpmw.WithRequired(true))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(payload))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Nil(t, s.values)
+
+		// Errors are not revealed to clients by default
+		require.Equal(t, "Bad Request\n", rec.Body.String())
+
+		h, _ = newHandler(t, httpmw.WithRequired(true), httpmw.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}))
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(payload))
+		require.Contains(t, rec.Body.String(), "failed to extract 'team'")
+	})
+	t.Run("Invalid bodies", func(t *testing.T) {
+		var handled error
+		h, _ := newHandler(t, httpmw.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(`{"data": `))
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Error(t, handled)
+
+		req := newRequest(payload)
+		req.Header.Set("Content-Type", "text/plain")
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+	t.Run("Requests without a body", func(t *testing.T) {
+		h, s := newHandler(t)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook", nil))
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Nil(t, s.values)
+	})
+	t.Run("Invalid pointers", func(t *testing.T) {
+		_, err := httpmw.New(map[string]string{"bad": "no-slash"})
+		require.Error(t, err)
+	})
+}
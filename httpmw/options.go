@@ -0,0 +1,52 @@
+package httpmw
+
+import (
+	"net/http"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/lestrrat-go/option"
+)
+
+// Option is an option that can be passed to New
+type Option interface {
+	option.Interface
+	httpmwOption()
+}
+
+type httpmwOption struct {
+	option.Interface
+}
+
+func (*httpmwOption) httpmwOption() {}
+
+type identErrorHandler struct{}
+type identRequired struct{}
+type identRetrieveOptions struct{}
+
+// ErrorHandler is called to respond to requests whose values cannot be
+// extracted
+type ErrorHandler func(w http.ResponseWriter, req *http.Request, err error)
+
+// WithErrorHandler specifies the function that responds to requests whose
+// body is not JSON, or whose values cannot be extracted. The request is
+// not passed to the next handler in that case. By default, the request
+// is rejected with 400 Bad Request, without any details of the error.
+// Use a custom handler to log the error, or to report it to clients.
+func WithErrorHandler(v ErrorHandler) Option {
+	return &httpmwOption{option.New(identErrorHandler{}, v)}
+}
+
+// WithRequired specifies whether every pointer must resolve against the
+// body. If true, requests whose body lacks any of the values are passed
+// to the error handler. The default is false, in which case the missing
+// values are absent from the context.
+func WithRequired(v bool) Option {
+	return &httpmwOption{option.New(identRequired{}, v)}
+}
+
+// WithRetrieveOptions specifies the options used to read the body and to
+// retrieve the values, such as jsptr.WithMaxBodySize or
+// jsptr.WithNumberMode
+func WithRetrieveOptions(v ...jsptr.HTTPOption) Option {
+	return &httpmwOption{option.New(identRetrieveOptions{}, v)}
+}
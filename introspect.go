@@ -0,0 +1,207 @@
+package jsptr
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/valyala/fastjson"
+)
+
+// Keys returns the member names of the object at the location specified
+// by the JSON pointer `spec`. If the location holds an array, its indices
+// are returned as strings. The order of the keys is the same as the order
+// used by Walk.
+//
+// Values are never converted, so this is cheap even for large subtrees.
+func Keys(target any, spec string) ([]string, error) {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	err = resolveNode(target, tokens, func(node any) error {
+		seq, ok := members(node)
+		if !ok {
+			return fmt.Errorf("value at '%s' is not an object or an array", spec)
+		}
+		keys = []string{}
+		for key := range seq {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Len returns the number of members of the object or array at the
+// location specified by the JSON pointer `spec`.
+func Len(target any, spec string) (int, error) {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = resolveNode(target, tokens, func(node any) error {
+		n, ok := memberCount(node)
+		if !ok {
+			return fmt.Errorf("value at '%s' is not an object or an array", spec)
+		}
+		count = n
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Kind represents the JSON type of a value
+type Kind int
+
+const (
+	// KindInvalid means that the value cannot be represented in JSON
+	KindInvalid Kind = iota
+	// KindObject means that the value is an object
+	KindObject
+	// KindArray means that the value is an array
+	KindArray
+	// KindString means that the value is a string
+	KindString
+	// KindNumber means that the value is a number
+	KindNumber
+	// KindBool means that the value is true or false
+	KindBool
+	// KindNull means that the value is null
+	KindNull
+)
+
+// String returns "object", "array", "string", "number", "bool", "null"
+// or "invalid"
+func (k Kind) String() string {
+	switch k {
+	case KindObject:
+		return "object"
+	case KindArray:
+		return "array"
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "bool"
+	case KindNull:
+		return "null"
+	default:
+		return "invalid"
+	}
+}
+
+// TypeAt returns the JSON type of the value at the location specified
+// by the JSON pointer `spec`, without converting the value.
+//
+// For Go values the type is determined by how encoding/json would encode
+// the value: for example nil pointers, maps and slices are reported as
+// null, []byte as a string, and types implementing json.Marshaler or
+// encoding.TextMarshaler according to their encoded form.
+func TypeAt(target any, spec string) (Kind, error) {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return KindInvalid, err
+	}
+
+	var kind Kind
+	err = resolveNode(target, tokens, func(node any) error {
+		k, err := kindOf(node)
+		if err != nil {
+			return err
+		}
+		kind = k
+		return nil
+	})
+	if err != nil {
+		return KindInvalid, err
+	}
+	return kind, nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonNumberType    = reflect.TypeFor[json.Number]()
+)
+
+func kindOf(node any) (Kind, error) {
+	if n, ok := node.(jsonNode); ok {
+		switch n.v.Type() {
+		case fastjson.TypeObject:
+			return KindObject, nil
+		case fastjson.TypeArray:
+			return KindArray, nil
+		case fastjson.TypeString:
+			return KindString, nil
+		case fastjson.TypeNumber:
+			return KindNumber, nil
+		case fastjson.TypeTrue, fastjson.TypeFalse:
+			return KindBool, nil
+		default:
+			return KindNull, nil
+		}
+	}
+
+	rv := reflect.ValueOf(node)
+	if !rv.IsValid() {
+		return KindNull, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return KindNull, nil
+		}
+	}
+
+	switch t := rv.Type(); {
+	case t == jsonNumberType:
+		return KindNumber, nil
+	case t.Implements(jsonMarshalerType):
+		buf, err := json.Marshal(node)
+		if err != nil {
+			return KindInvalid, fmt.Errorf("failed to encode %T: %w", node, err)
+		}
+		sc := rawScanner{data: buf}
+		return sc.jsonKind(), nil
+	case t.Implements(textMarshalerType):
+		return KindString, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return kindOf(rv.Elem().Interface())
+	case reflect.Map, reflect.Struct:
+		return KindObject, nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return KindString, nil
+		}
+		return KindArray, nil
+	case reflect.Array:
+		return KindArray, nil
+	case reflect.String:
+		return KindString, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return KindNumber, nil
+	case reflect.Bool:
+		return KindBool, nil
+	default:
+		return KindInvalid, fmt.Errorf("type %T cannot be represented in JSON", node)
+	}
+}
@@ -0,0 +1,157 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysAndLen(t *testing.T) {
+	const src = `{"obj": {"b": 1, "a": 2}, "arr": [1, 2, 3], "empty": {}, "str": "s"}`
+
+	type Obj struct {
+		B int `json:"b"`
+		A int `json:"a"`
+	}
+	type Root struct {
+		Obj   Obj            `json:"obj"`
+		Arr   []int          `json:"arr"`
+		Empty map[string]int `json:"empty"`
+		Str   string         `json:"str"`
+	}
+
+	doc, err := jsptr.ParseJSON([]byte(src))
+	require.NoError(t, err)
+
+	ordered := jsptr.NewOrderedMap()
+	obj := jsptr.NewOrderedMap()
+	obj.Set("b", 1)
+	obj.Set("a", 2)
+	ordered.Set("obj", obj)
+	ordered.Set("arr", []any{1, 2, 3})
+	ordered.Set("empty", jsptr.NewOrderedMap())
+	ordered.Set("str", "s")
+
+	targets := map[string]struct {
+		target  any
+		objKeys []string
+	}{
+		"JSON bytes": {target: []byte(src), objKeys: []string{"b", "a"}},
+		"Document":   {target: doc, objKeys: []string{"b", "a"}},
+		"map":        {target: decodeJSON(t, src), objKeys: []string{"a", "b"}},
+		"OrderedMap": {target: ordered, objKeys: []string{"b", "a"}},
+		"struct": {
+			target:  Root{Obj: Obj{B: 1, A: 2}, Arr: []int{1, 2, 3}, Empty: map[string]int{}, Str: "s"},
+			objKeys: []string{"b", "a"},
+		},
+	}
+
+	for name, tc := range targets {
+		t.Run(name, func(t *testing.T) {
+			keys, err := jsptr.Keys(tc.target, "/obj")
+			require.NoError(t, err)
+			require.Equal(t, tc.objKeys, keys)
+
+			keys, err = jsptr.Keys(tc.target, "/arr")
+			require.NoError(t, err)
+			require.Equal(t, []string{"0", "1", "2"}, keys)
+
+			keys, err = jsptr.Keys(tc.target, "/empty")
+			require.NoError(t, err)
+			require.Empty(t, keys)
+
+			n, err := jsptr.Len(tc.target, "/arr")
+			require.NoError(t, err)
+			require.Equal(t, 3, n)
+
+			n, err = jsptr.Len(tc.target, "/obj")
+			require.NoError(t, err)
+			require.Equal(t, 2, n)
+
+			n, err = jsptr.Len(tc.target, "/empty")
+			require.NoError(t, err)
+			require.Zero(t, n)
+
+			n, err = jsptr.Len(tc.target, "")
+			require.NoError(t, err)
+			require.Equal(t, 4, n)
+
+			_, err = jsptr.Keys(tc.target, "/str")
+			require.Error(t, err)
+			_, err = jsptr.Len(tc.target, "/str")
+			require.Error(t, err)
+			_, err = jsptr.Len(tc.target, "/missing")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTypeAt(t *testing.T) {
+	const src = `{"obj": {}, "arr": [], "str": "s", "num": 1.5, "t": true, "f": false, "null": null}`
+
+	jsonTests := map[string]jsptr.Kind{
+		"":      jsptr.KindObject,
+		"/obj":  jsptr.KindObject,
+		"/arr":  jsptr.KindArray,
+		"/str":  jsptr.KindString,
+		"/num":  jsptr.KindNumber,
+		"/t":    jsptr.KindBool,
+		"/f":    jsptr.KindBool,
+		"/null": jsptr.KindNull,
+	}
+	for spec, expected := range jsonTests {
+		t.Run("JSON "+spec, func(t *testing.T) {
+			kind, err := jsptr.TypeAt([]byte(src), spec)
+			require.NoError(t, err)
+			require.Equal(t, expected, kind)
+		})
+	}
+
+	type Inner struct{}
+	goValue := map[string]any{
+		"struct":   Inner{},
+		"ptr":      &Inner{},
+		"nilptr":   (*Inner)(nil),
+		"nilslice": []int(nil),
+		"bytes":    []byte("abc"),
+		"array":    [2]int{},
+		"int":      42,
+		"number":   json.Number("42"),
+		"time":     time.Now(),
+		"bool":     true,
+		"nil":      nil,
+	}
+	goTests := map[string]jsptr.Kind{
+		"/struct":   jsptr.KindObject,
+		"/ptr":      jsptr.KindObject,
+		"/nilptr":   jsptr.KindNull,
+		"/nilslice": jsptr.KindNull,
+		"/bytes":    jsptr.KindString,
+		"/array":    jsptr.KindArray,
+		"/int":      jsptr.KindNumber,
+		"/number":   jsptr.KindNumber,
+		"/time":     jsptr.KindString,
+		"/bool":     jsptr.KindBool,
+		"/nil":      jsptr.KindNull,
+	}
+	for spec, expected := range goTests {
+		t.Run("Go "+spec, func(t *testing.T) {
+			kind, err := jsptr.TypeAt(goValue, spec)
+			require.NoError(t, err)
+			require.Equal(t, expected, kind, "got %s", kind)
+		})
+	}
+
+	t.Run("missing location", func(t *testing.T) {
+		_, err := jsptr.TypeAt([]byte(src), "/missing")
+		require.Error(t, err)
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := jsptr.TypeAt(map[string]any{"ch": make(chan int)}, "/ch")
+		require.Error(t, err)
+	})
+	require.Equal(t, "object", jsptr.KindObject.String())
+}
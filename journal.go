@@ -0,0 +1,68 @@
+package jsptr
+
+import (
+	"maps"
+	"slices"
+)
+
+// journal records the contents of the containers of a document made of
+// Go values before they are changed in place, so that a failed edit can
+// be rolled back, leaving the document as it was
+type journal struct {
+	undo []func()
+}
+
+// record saves the contents of the containers that lead to the location
+// specified by tokens within node, as those are the containers that
+// editing the location may change. The value at the location itself is
+// replaced rather than changed, so it does not need to be saved
+func (j *journal) record(node any, tokens []string) {
+	current := node
+	for _, token := range tokens {
+		switch v := current.(type) {
+		case map[string]any:
+			if v == nil {
+				return
+			}
+			saved := maps.Clone(v)
+			j.undo = append(j.undo, func() {
+				clear(v)
+				maps.Copy(v, saved)
+			})
+			next, ok := v[token]
+			if !ok {
+				return
+			}
+			current = next
+		case *OrderedMap:
+			saved := v.clone()
+			j.undo = append(j.undo, func() { *v = *saved })
+			next, ok := v.Get(token)
+			if !ok {
+				return
+			}
+			current = next
+		case []any:
+			// Elements may be shifted within the array, but arrays that
+			// have to grow are reallocated, and the containers that hold
+			// them are restored separately
+			saved := slices.Clone(v)
+			j.undo = append(j.undo, func() { copy(v, saved) })
+			index, err := parseIndex(token, len(v))
+			if err != nil {
+				return
+			}
+			current = v[index]
+		default:
+			return
+		}
+	}
+}
+
+// rollback restores the containers recorded so far, in reverse order
+func (j *journal) rollback() {
+	for i := len(j.undo) - 1; i >= 0; i-- {
+		j.undo[i]()
+	}
+	j.undo = nil
+}
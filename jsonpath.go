@@ -0,0 +1,158 @@
+package jsptr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FromJSONPath creates a pointer from a JSONPath expression. Only the
+// subset of JSONPath that can be expressed as a JSON pointer is accepted:
+// the root "$", followed by any number of member accesses (".name",
+// "['name']" or "[\"name\"]") and non-negative array indices ("[0]").
+// Expressions that may match more than one value, such as those using
+// wildcards, recursive descent, slices or filters, are rejected.
+//
+// JSON pointers do not distinguish array indices from member names, so
+// "$.a[0]" and "$.a['0']" result in the same pointer, "/a/0".
+func FromJSONPath(path string) (*Pointer, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath expression must start with '$'")
+	}
+
+	var tokens []string
+	rest := path[1:]
+	for rest != "" {
+		var token string
+		var err error
+		switch rest[0] {
+		case '.':
+			token, rest, err = parseJSONPathMember(rest[1:])
+		case '[':
+			token, rest, err = parseJSONPathBracket(rest[1:])
+		default:
+			err = fmt.Errorf("unexpected character '%c'", rest[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSONPath expression '%s': %w", path, err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return &Pointer{pattern: joinTokens(tokens), tokens: tokens}, nil
+}
+
+// parseJSONPathMember parses the name following a "." in a JSONPath
+// expression, and returns it along with the remainder of the expression
+func parseJSONPathMember(s string) (string, string, error) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		end = len(s)
+	}
+	name := s[:end]
+	switch name {
+	case "":
+		if strings.HasPrefix(s, ".") {
+			return "", "", fmt.Errorf("recursive descent is not supported")
+		}
+		return "", "", fmt.Errorf("empty member name")
+	case "*":
+		return "", "", fmt.Errorf("wildcards are not supported")
+	}
+	return name, s[end:], nil
+}
+
+// parseJSONPathBracket parses the contents of a bracketed selector in a
+// JSONPath expression, and returns the token along with the remainder of
+// the expression
+func parseJSONPathBracket(s string) (string, string, error) {
+	if s != "" && (s[0] == '\'' || s[0] == '"') {
+		quote := s[0]
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; c {
+			case '\\':
+				i++
+				if i == len(s) {
+					return "", "", fmt.Errorf("unterminated string")
+				}
+				sb.WriteByte(s[i])
+			case quote:
+				if i+1 == len(s) || s[i+1] != ']' {
+					return "", "", fmt.Errorf("expected ']' after member name")
+				}
+				return sb.String(), s[i+2:], nil
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", "", fmt.Errorf("unterminated string")
+	}
+
+	index, rest, ok := strings.Cut(s, "]")
+	if !ok {
+		return "", "", fmt.Errorf("unterminated '['")
+	}
+	if !isIndex(index) {
+		return "", "", fmt.Errorf("unsupported selector '[%s]'", index)
+	}
+	return index, rest, nil
+}
+
+// isIndex reports whether token is a non-negative array index without
+// leading zeros
+func isIndex(token string) bool {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return false
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// JSONPath returns the JSONPath expression equivalent to the pointer.
+// Tokens that look like array indices are written as "[0]", tokens that
+// are valid identifiers as ".name", and all other tokens as "['name']".
+// Extension tokens are not translated, and are written as member names.
+func (p *Pointer) JSONPath() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, token := range p.tokens {
+		switch {
+		case isIndex(token):
+			sb.WriteByte('[')
+			sb.WriteString(token)
+			sb.WriteByte(']')
+		case isIdentifier(token):
+			sb.WriteByte('.')
+			sb.WriteString(token)
+		default:
+			sb.WriteString("['")
+			for _, c := range token {
+				if c == '\'' || c == '\\' {
+					sb.WriteByte('\\')
+				}
+				sb.WriteRune(c)
+			}
+			sb.WriteString("']")
+		}
+	}
+	return sb.String()
+}
+
+func isIdentifier(token string) bool {
+	if token == "" {
+		return false
+	}
+	for i, c := range token {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && '0' <= c && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSONPath(t *testing.T) {
+	testcases := []struct {
+		Path     string
+		Expected string
+		Error    bool
+	}{
+		{Path: "$", Expected: ""},
+		{Path: "$.a.b[0]", Expected: "/a/b/0"},
+		{Path: "$['a']['b c']", Expected: "/a/b c"},
+		{Path: `$["a/b"]["m~n"]`, Expected: "/a~1b/m~0n"},
+		{Path: `$['it\'s']`, Expected: "/it's"},
+		{Path: "$.items[10].name", Expected: "/items/10/name"},
+		{Path: "$[0][1]", Expected: "/0/1"},
+		{Path: "a.b", Error: true},
+		{Path: "$..a", Error: true},
+		{Path: "$.*", Error: true},
+		{Path: "$.a[*]", Error: true},
+		{Path: "$.a[-1]", Error: true},
+		{Path: "$.a[0:2]", Error: true},
+		{Path: "$.a[?(@.id==1)]", Error: true},
+		{Path: "$.a.", Error: true},
+		{Path: "$.a['b'", Error: true},
+		{Path: "$.a[0", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Path, func(t *testing.T) {
+			ptr, err := jsptr.FromJSONPath(tc.Path)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, ptr.Pattern())
+		})
+	}
+}
+
+func TestPointerJSONPath(t *testing.T) {
+	testcases := []struct {
+		Pointer  string
+		Expected string
+	}{
+		{Pointer: "", Expected: "$"},
+		{Pointer: "/a/b/0", Expected: "$.a.b[0]"},
+		{Pointer: "/a b/c-d", Expected: "$['a b']['c-d']"},
+		{Pointer: "/it's/back\\slash", Expected: `$['it\'s']['back\\slash']`},
+		{Pointer: "/a~1b/01", Expected: "$['a/b']['01']"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, ptr.JSONPath())
+
+			// The expression must round-trip to the same pointer
+			back, err := jsptr.FromJSONPath(ptr.JSONPath())
+			require.NoError(t, err)
+			require.True(t, ptr.Equal(back))
+		})
+	}
+}
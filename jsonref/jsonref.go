@@ -0,0 +1,278 @@
+// Package jsonref resolves JSON References, which are objects of the form
+// {"$ref": "uri#/pointer"} that refer to a value in the same or another
+// document, as used by JSON Schema and OpenAPI.
+//
+// Documents are loaded by loaders registered per URI scheme, so that it
+// is up to the user whether references may be fetched from the file
+// system or the network. Documents that are already in memory can be
+// registered in a Registry, which resolves references into them by their
+// base URIs and "$id" members, and can itself be used as a loader.
+package jsonref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// DefaultMaxDepth is the maximum number of references that are followed
+// in a chain of references, unless specified otherwise using WithMaxDepth
+const DefaultMaxDepth = 64
+
+// ErrReferenceCycle is the error that is returned (possibly wrapped) when
+// a reference refers, directly or through other references, to a value
+// that contains the reference itself. Use errors.Is to check for it.
+var ErrReferenceCycle = errors.New("reference cycle")
+
+// ErrMaxDepth is the error that is returned (possibly wrapped) when a
+// chain of references is longer than the maximum depth. Use errors.Is
+// to check for it.
+var ErrMaxDepth = errors.New("maximum reference depth exceeded")
+
+type cycleError struct {
+	chain []string
+}
+
+func (e *cycleError) Error() string {
+	return fmt.Sprintf("reference cycle: %s", strings.Join(e.chain, " -> "))
+}
+
+func (e *cycleError) Is(target error) bool {
+	return target == ErrReferenceCycle
+}
+
+// Resolver resolves JSON References using the registered loaders.
+// Loaded documents are cached for the lifetime of the Resolver.
+//
+// A Resolver is safe for concurrent use.
+type Resolver struct {
+	loaders  map[string]Loader
+	maxDepth int
+
+	mu   sync.Mutex
+	docs map[string]any
+}
+
+// NewResolver creates a new Resolver. No loaders are registered by
+// default: use WithLoader to specify which URI schemes can be resolved
+func NewResolver(options ...ResolverOption) *Resolver {
+	r := &Resolver{
+		loaders:  make(map[string]Loader),
+		maxDepth: DefaultMaxDepth,
+		docs:     make(map[string]any),
+	}
+	for _, option := range options {
+		switch option.Ident() {
+		case identLoader{}:
+			sl := option.Value().(schemeLoader)
+			r.loaders[sl.scheme] = sl.loader
+		case identMaxDepth{}:
+			r.maxDepth = option.Value().(int)
+		}
+	}
+	return r
+}
+
+// Resolve returns the value that the absolute reference `ref` refers to.
+// The fragment of ref, if any, must be a JSON pointer. References within
+// the returned value are left as is; use Dereference to resolve them.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (any, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("reference %q is not absolute", ref)
+	}
+
+	doc, err := r.document(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+	v, err := evaluate(doc, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+	return v, nil
+}
+
+// Dereference returns a copy of doc in which every reference has been
+// replaced by the value that it refers to, recursively. Relative
+// references are resolved against base, which may be empty if doc only
+// contains references within itself (such as "#/definitions/user").
+//
+// doc may be JSON text as []byte, or generic Go values, in which case
+// only map[string]any and []any are descended into. Members of an object
+// that sit next to "$ref" are ignored, as required by JSON Reference.
+//
+// Circular references cannot be replaced by their values, and cause an
+// error matching ErrReferenceCycle to be returned. Chains of references
+// that are longer than the maximum depth (see WithMaxDepth) cause an
+// error matching ErrMaxDepth to be returned.
+func (r *Resolver) Dereference(ctx context.Context, doc any, base string) (any, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URI %q: %w", base, err)
+	}
+	doc, err = decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return r.dereference(ctx, doc, scope{root: doc, base: u}, nil)
+}
+
+// scope is the document that relative references are resolved against
+type scope struct {
+	root any
+	base *url.URL
+}
+
+// dereference replaces the references in node. chain holds the
+// references that are being followed, in order to detect cycles
+func (r *Resolver) dereference(ctx context.Context, node any, s scope, chain []string) (any, error) {
+	switch v := node.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			return r.follow(ctx, ref, s, chain)
+		}
+		result := make(map[string]any, len(v))
+		for key, elem := range v {
+			dv, err := r.dereference(ctx, elem, s, chain)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = dv
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, elem := range v {
+			dv, err := r.dereference(ctx, elem, s, chain)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = dv
+		}
+		return result, nil
+	default:
+		return node, nil
+	}
+}
+
+// follow resolves ref within scope s, and dereferences the target
+func (r *Resolver) follow(ctx context.Context, ref string, s scope, chain []string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+	target := s.base.ResolveReference(u)
+
+	id := target.String()
+	if slices.Contains(chain, id) {
+		return nil, &cycleError{chain: append(slices.Clone(chain), id)}
+	}
+	if len(chain) >= r.maxDepth {
+		return nil, fmt.Errorf("failed to resolve reference %q: %w (%d)", ref, ErrMaxDepth, r.maxDepth)
+	}
+	// The chain is clipped, so that siblings do not share its backing array
+	chain = append(slices.Clip(chain), id)
+
+	// References within the same document do not need to be loaded,
+	// which also allows documents without a base URI to refer to themselves
+	if !sameDocument(target, s.base) {
+		if !target.IsAbs() {
+			return nil, fmt.Errorf("cannot resolve relative reference %q without an absolute base URI", ref)
+		}
+		doc, err := r.document(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+		}
+		s = scope{root: doc, base: target}
+	}
+
+	v, err := evaluate(s.root, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+	return r.dereference(ctx, v, s, chain)
+}
+
+// document returns the document identified by u, loading it if necessary
+func (r *Resolver) document(ctx context.Context, u *url.URL) (any, error) {
+	key := withoutFragment(u)
+	id := key.String()
+
+	r.mu.Lock()
+	doc, ok := r.docs[id]
+	r.mu.Unlock()
+	if ok {
+		return doc, nil
+	}
+
+	loader, ok := r.loaders[key.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no loader registered for scheme %q", key.Scheme)
+	}
+	doc, err := loader.Load(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q: %w", id, err)
+	}
+	if doc, err = decode(doc); err != nil {
+		return nil, fmt.Errorf("failed to load %q: %w", id, err)
+	}
+
+	r.mu.Lock()
+	r.docs[id] = doc
+	r.mu.Unlock()
+	return doc, nil
+}
+
+// evaluate returns the value that the fragment of u refers to in doc
+func evaluate(doc any, u *url.URL) (any, error) {
+	if u.Fragment != "" && !strings.HasPrefix(u.Fragment, "/") {
+		return nil, fmt.Errorf("unsupported fragment %q: only JSON pointers are supported", u.Fragment)
+	}
+	ptr, err := jsptr.New(u.Fragment)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := ptr.Retrieve(&v, doc); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// decode converts JSON text into generic Go values
+func decode(doc any) (any, error) {
+	data, ok := doc.([]byte)
+	if !ok {
+		return doc, nil
+	}
+	parsed, err := jsptr.ParseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Get("")
+}
+
+func withoutFragment(u *url.URL) *url.URL {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	return &c
+}
+
+func sameDocument(a, b *url.URL) bool {
+	return withoutFragment(a).String() == withoutFragment(b).String()
+}
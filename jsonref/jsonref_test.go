@@ -0,0 +1,200 @@
+package jsonref_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lestrrat-go/jsptr/jsonref"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	resolver := jsonref.NewResolver(jsonref.WithLoader("https", jsonref.MemoryLoader{
+		"https://example.com/schema.json": []byte(`{"definitions": {"user name": {"type": "string"}, "ref": {"$ref": "#/x"}}}`),
+	}))
+
+	testcases := []struct {
+		Ref      string
+		Expected any
+		Error    bool
+	}{
+		{Ref: "https://example.com/schema.json#/definitions/user%20name", Expected: map[string]any{"type": "string"}},
+		{Ref: "https://example.com/schema.json#/definitions/user%20name/type", Expected: "string"},
+		{Ref: "https://example.com/schema.json#/definitions/ref", Expected: map[string]any{"$ref": "#/x"}},
+		{Ref: "https://example.com/schema.json", Expected: map[string]any{"definitions": map[string]any{"user name": map[string]any{"type": "string"}, "ref": map[string]any{"$ref": "#/x"}}}},
+		{Ref: "https://example.com/schema.json#/definitions/missing", Error: true},
+		{Ref: "https://example.com/schema.json#anchor", Error: true},
+		{Ref: "https://example.com/other.json#/a", Error: true},
+		{Ref: "http://example.com/schema.json#/definitions", Error: true},
+		{Ref: "schema.json#/definitions", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Ref, func(t *testing.T) {
+			v, err := resolver.Resolve(context.Background(), tc.Ref)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, v)
+		})
+	}
+}
+
+func TestDereference(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/root.json": &fstest.MapFile{Data: []byte(`{
+			"properties": {
+				"user": {"$ref": "defs/user.json"},
+				"tags": {"$ref": "#/definitions/tags", "description": "ignored"}
+			},
+			"definitions": {"tags": {"type": "array", "items": {"$ref": "defs/user.json#/definitions/name"}}}
+		}`)},
+		"schemas/defs/user.json": &fstest.MapFile{Data: []byte(`{
+			"type": "object",
+			"properties": {"name": {"$ref": "#/definitions/name"}},
+			"definitions": {"name": {"type": "string"}}
+		}`)},
+	}
+	resolver := jsonref.NewResolver(jsonref.WithLoader("file", jsonref.FSLoader(fsys)))
+
+	ctx := context.Background()
+	root, err := resolver.Resolve(ctx, "file:///schemas/root.json")
+	require.NoError(t, err)
+
+	doc, err := resolver.Dereference(ctx, root, "file:///schemas/root.json")
+	require.NoError(t, err)
+
+	name := map[string]any{"type": "string"}
+	require.Equal(t, map[string]any{
+		"properties": map[string]any{
+			"user": map[string]any{
+				"type":        "object",
+				"properties":  map[string]any{"name": name},
+				"definitions": map[string]any{"name": name},
+			},
+			"tags": map[string]any{"type": "array", "items": name},
+		},
+		"definitions": map[string]any{"tags": map[string]any{"type": "array", "items": name}},
+	}, doc)
+
+	t.Run("documents are not modified", func(t *testing.T) {
+		props := root.(map[string]any)["properties"].(map[string]any)
+		require.Equal(t, map[string]any{"$ref": "defs/user.json"}, props["user"])
+	})
+	t.Run("without a base URI", func(t *testing.T) {
+		doc, err := resolver.Dereference(ctx, []byte(`{"a": {"$ref": "#/b"}, "b": [1, {"$ref": "#/c"}], "c": true}`), "")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"a": []any{1.0, true}, "b": []any{1.0, true}, "c": true}, doc)
+
+		_, err = resolver.Dereference(ctx, []byte(`{"a": {"$ref": "other.json"}}`), "")
+		require.Error(t, err)
+	})
+	t.Run("unresolvable references", func(t *testing.T) {
+		for _, src := range []string{
+			`{"a": {"$ref": "#/missing"}}`,
+			`{"a": {"$ref": "https://example.com/x.json"}}`,
+			`{"a": {"$ref": "defs/missing.json"}}`,
+			`{"a": {"$ref": "%zz"}}`,
+		} {
+			_, err := resolver.Dereference(ctx, []byte(src), "file:///schemas/root.json")
+			require.Error(t, err, src)
+		}
+	})
+}
+
+func TestLoaders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("HTTP", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/schema.json" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"definitions": {"id": {"type": "integer"}}}`))
+		}))
+		defer srv.Close()
+
+		resolver := jsonref.NewResolver(jsonref.WithLoader("http", jsonref.HTTPLoader(srv.Client())))
+		v, err := resolver.Resolve(ctx, srv.URL+"/schema.json#/definitions/id/type")
+		require.NoError(t, err)
+		require.Equal(t, "integer", v)
+
+		_, err = resolver.Resolve(ctx, srv.URL+"/missing.json")
+		require.Error(t, err)
+	})
+	t.Run("OS file system", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "schema.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"type": "null"}`), 0o600))
+
+		resolver := jsonref.NewResolver(jsonref.WithLoader("file", jsonref.FSLoader(nil)))
+		u := url.URL{Scheme: "file", Path: filepath.ToSlash(path), Fragment: "/type"}
+		v, err := resolver.Resolve(ctx, u.String())
+		require.NoError(t, err)
+		require.Equal(t, "null", v)
+	})
+	t.Run("documents are cached", func(t *testing.T) {
+		var loads int
+		resolver := jsonref.NewResolver(jsonref.WithLoader("urn", jsonref.LoaderFunc(func(context.Context, *url.URL) (any, error) {
+			loads++
+			return map[string]any{"a": 1}, nil
+		})))
+		for range 3 {
+			v, err := resolver.Resolve(ctx, "urn:example:doc#/a")
+			require.NoError(t, err)
+			require.Equal(t, 1, v)
+		}
+		require.Equal(t, 1, loads)
+	})
+}
+
+func TestDereferenceCycles(t *testing.T) {
+	ctx := context.Background()
+	resolver := jsonref.NewResolver(jsonref.WithLoader("https", jsonref.MemoryLoader{
+		"https://example.com/a.json": []byte(`{"next": {"$ref": "b.json"}}`),
+		"https://example.com/b.json": []byte(`{"next": {"$ref": "a.json#/next"}}`),
+	}))
+
+	t.Run("cycles", func(t *testing.T) {
+		for _, src := range []string{
+			`{"a": {"$ref": "#/a"}}`,
+			`{"a": {"$ref": "#/b"}, "b": {"$ref": "#/a"}}`,
+			`{"tree": {"children": {"type": "array", "items": {"$ref": "#/tree"}}}}`,
+			`{"root": {"$ref": "#"}}`,
+			`{"start": {"$ref": "https://example.com/a.json"}}`,
+		} {
+			_, err := resolver.Dereference(ctx, []byte(src), "")
+			require.ErrorIs(t, err, jsonref.ErrReferenceCycle, src)
+		}
+	})
+	t.Run("shared references are not cycles", func(t *testing.T) {
+		doc, err := resolver.Dereference(ctx, []byte(`{"a": {"$ref": "#/c"}, "b": [{"$ref": "#/c"}, {"$ref": "#/d"}], "c": {"$ref": "#/d"}, "d": 1}`), "")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"a": 1.0, "b": []any{1.0, 1.0}, "c": 1.0, "d": 1.0}, doc)
+	})
+	t.Run("maximum depth", func(t *testing.T) {
+		const src = `{"a": {"$ref": "#/b"}, "b": {"$ref": "#/c"}, "c": {"$ref": "#/d"}, "d": 1}`
+
+		_, err := jsonref.NewResolver(jsonref.WithMaxDepth(2)).Dereference(ctx, []byte(src), "")
+		require.ErrorIs(t, err, jsonref.ErrMaxDepth)
+
+		doc, err := jsonref.NewResolver(jsonref.WithMaxDepth(3)).Dereference(ctx, []byte(src), "")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"a": 1.0, "b": 1.0, "c": 1.0, "d": 1.0}, doc)
+	})
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := resolver.Dereference(ctx, []byte(`{"a": {"$ref": "#/b"}, "b": 1}`), "")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,92 @@
+package jsonref
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// Loader loads the document identified by an absolute URI. The URI never
+// has a fragment.
+//
+// The document may be returned as JSON text ([]byte), or as generic Go
+// values (map[string]any, []any, and so on)
+type Loader interface {
+	Load(ctx context.Context, uri *url.URL) (any, error)
+}
+
+// LoaderFunc is a function that implements Loader
+type LoaderFunc func(ctx context.Context, uri *url.URL) (any, error)
+
+// Load calls f
+func (f LoaderFunc) Load(ctx context.Context, uri *url.URL) (any, error) {
+	return f(ctx, uri)
+}
+
+// MemoryLoader is a Loader for documents that are held in memory. The
+// keys are absolute URIs without fragments
+type MemoryLoader map[string]any
+
+// Load returns the document registered under uri
+func (m MemoryLoader) Load(_ context.Context, uri *url.URL) (any, error) {
+	doc, ok := m[uri.String()]
+	if !ok {
+		return nil, fmt.Errorf("document %q not found", uri)
+	}
+	return doc, nil
+}
+
+// FSLoader creates a Loader for "file" URIs, which reads documents from
+// fsys. The path of the URI, without its leading slash, is used as the
+// name of the file in fsys. If fsys is nil, the path is used as is to
+// read from the file system of the operating system.
+func FSLoader(fsys fs.FS) Loader {
+	return LoaderFunc(func(_ context.Context, uri *url.URL) (any, error) {
+		if fsys == nil {
+			return os.ReadFile(filepath.FromSlash(uri.Path))
+		}
+		return fs.ReadFile(fsys, strings.TrimPrefix(uri.Path, "/"))
+	})
+}
+
+// HTTPLoader creates a Loader for "http" and "https" URIs, which
+// fetches documents using client. If client is nil, http.DefaultClient
+// is used. Documents larger than jsptr.DefaultMaxBodySize are rejected.
+func HTTPLoader(client *http.Client) Loader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return LoaderFunc(func(ctx context.Context, uri *url.URL) (any, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("unexpected status %q", resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, jsptr.DefaultMaxBodySize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+		if len(data) > jsptr.DefaultMaxBodySize {
+			return nil, fmt.Errorf("body exceeds the maximum size of %d bytes", jsptr.DefaultMaxBodySize)
+		}
+		return data, nil
+	})
+}
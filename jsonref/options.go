@@ -0,0 +1,39 @@
+package jsonref
+
+import "github.com/lestrrat-go/option"
+
+// ResolverOption is an option that can be passed to NewResolver
+type ResolverOption interface {
+	option.Interface
+	resolverOption()
+}
+
+type resolverOption struct {
+	option.Interface
+}
+
+func (*resolverOption) resolverOption() {}
+
+type identLoader struct{}
+type identMaxDepth struct{}
+
+// schemeLoader associates a loader with a URI scheme
+type schemeLoader struct {
+	scheme string
+	loader Loader
+}
+
+// WithLoader specifies the loader used for URIs with the given scheme,
+// such as "file" or "https". It may be specified multiple times to
+// register loaders for several schemes.
+func WithLoader(scheme string, l Loader) ResolverOption {
+	return &resolverOption{option.New(identLoader{}, schemeLoader{scheme: scheme, loader: l})}
+}
+
+// WithMaxDepth specifies the maximum number of references that are
+// followed in a chain of references, where each reference is found in
+// the value that the previous one refers to. The default is
+// DefaultMaxDepth.
+func WithMaxDepth(v int) ResolverOption {
+	return &resolverOption{option.New(identMaxDepth{}, v)}
+}
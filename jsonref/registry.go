@@ -0,0 +1,127 @@
+package jsonref
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// Registry holds documents registered under absolute base URIs, and
+// resolves references into them without loading anything.
+//
+// A Registry implements Loader, so it can also be used as the source of
+// documents for a Resolver. A Registry is safe for concurrent use.
+type Registry struct {
+	mu   sync.RWMutex
+	docs map[string]any
+}
+
+// NewRegistry creates a new empty Registry
+func NewRegistry() *Registry {
+	return &Registry{docs: make(map[string]any)}
+}
+
+// Register registers doc under the base URI `uri`. doc may be JSON text
+// as []byte, or generic Go values.
+//
+// If the document declares its own base URI using an "$id" member, it
+// is registered under that URI as well, resolved against uri. Objects
+// within the document that declare an "$id" (embedded resources, as
+// found in schema bundles) are registered under their own URIs, so that
+// they can be referred to directly. uri may be empty if the document
+// has an absolute "$id".
+func (r *Registry) Register(uri string, doc any) error {
+	base, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid base URI %q: %w", uri, err)
+	}
+	if base.Fragment != "" {
+		return fmt.Errorf("base URI %q must not have a fragment", uri)
+	}
+	doc, err = decode(doc)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]any)
+	if base.IsAbs() {
+		found[base.String()] = doc
+	}
+	if err := collectResources(found, doc, base); err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("document has no absolute base URI")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, resource := range found {
+		r.docs[id] = resource
+	}
+	return nil
+}
+
+// collectResources finds the objects in node that declare an "$id", and
+// adds them to found under their absolute URIs
+func collectResources(found map[string]any, node any, base *url.URL) error {
+	switch v := node.(type) {
+	case map[string]any:
+		if id, ok := v["$id"].(string); ok {
+			u, err := url.Parse(id)
+			if err != nil {
+				return fmt.Errorf("invalid $id %q: %w", id, err)
+			}
+			base = withoutFragment(base.ResolveReference(u))
+			if !base.IsAbs() {
+				return fmt.Errorf("cannot resolve relative $id %q without an absolute base URI", id)
+			}
+			found[base.String()] = v
+		}
+		for _, elem := range v {
+			if err := collectResources(found, elem, base); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			if err := collectResources(found, elem, base); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve returns the value that the absolute reference `ref` refers to,
+// such as "https://example.com/schema.json#/definitions/user". The
+// fragment of ref, if any, must be a JSON pointer.
+func (r *Registry) Resolve(ref string) (any, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+
+	doc, err := r.Load(context.Background(), withoutFragment(u))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+	v, err := evaluate(doc, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+	return v, nil
+}
+
+// Load returns the document registered under uri
+func (r *Registry) Load(_ context.Context, uri *url.URL) (any, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	doc, ok := r.docs[uri.String()]
+	if !ok {
+		return nil, fmt.Errorf("document %q is not registered", uri)
+	}
+	return doc, nil
+}
@@ -0,0 +1,63 @@
+package jsonref_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr/jsonref"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := jsonref.NewRegistry()
+	require.NoError(t, registry.Register("https://example.com/schema.json", []byte(`{
+		"definitions": {"user": {"type": "object", "properties": {"name": {"type": "string"}}}}
+	}`)))
+	require.NoError(t, registry.Register("", map[string]any{
+		"$id": "https://example.com/bundle.json",
+		"$defs": map[string]any{
+			"address": map[string]any{"$id": "address.json", "type": "object"},
+			"nested":  map[string]any{"$id": "https://other.example/nested", "items": []any{map[string]any{"$id": "item", "type": "null"}}},
+		},
+	}))
+
+	testcases := []struct {
+		Ref      string
+		Expected any
+		Error    bool
+	}{
+		{Ref: "https://example.com/schema.json#/definitions/user/properties/name/type", Expected: "string"},
+		{Ref: "https://example.com/bundle.json#/$defs/address/type", Expected: "object"},
+		{Ref: "https://example.com/address.json#/type", Expected: "object"},
+		{Ref: "https://example.com/address.json", Expected: map[string]any{"$id": "address.json", "type": "object"}},
+		{Ref: "https://other.example/item#/type", Expected: "null"},
+		{Ref: "https://example.com/schema.json#/definitions/group", Error: true},
+		{Ref: "https://example.com/unknown.json", Error: true},
+		{Ref: "%zz", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Ref, func(t *testing.T) {
+			v, err := registry.Resolve(tc.Ref)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, v)
+		})
+	}
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		require.Error(t, registry.Register("", []byte(`{"type": "object"}`)), "no base URI")
+		require.Error(t, registry.Register("", []byte(`{"$id": "relative.json"}`)))
+		require.Error(t, registry.Register("https://example.com/x.json#frag", []byte(`{}`)))
+		require.Error(t, registry.Register("https://example.com/x.json", []byte(`{`)))
+	})
+	t.Run("as a loader", func(t *testing.T) {
+		resolver := jsonref.NewResolver(jsonref.WithLoader("https", registry))
+		doc, err := resolver.Dereference(context.Background(), []byte(`{"user": {"$ref": "schema.json#/definitions/user"}}`), "https://example.com/")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"user": map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}}}, doc)
+	})
+}
@@ -1,29 +1,85 @@
 package jsptr
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/lestrrat-go/blackmagic"
 	"github.com/valyala/fastjson"
 )
 
+// parserPool is used for one-shot retrievals against JSON bytes, where
+// the parsed values do not outlive the call to Retrieve
+var parserPool fastjson.ParserPool
+
 // Source is an interface for abstracting different data sources
 type Source interface {
 	RetrieveJSONPointer(dst any, ptrspec string) error
 }
 
+// tokenSource is implemented by the built-in sources. It allows a compiled
+// Pointer to be evaluated against a source without having to re-parse
+// the pointer specification at every level of the traversal.
+type tokenSource interface {
+	retrieveTokens(dst any, tokens []string, cfg *retrieveConfig) error
+}
+
 // Pointer represents a compiled JSON pointer
 type Pointer struct {
 	pattern string
 	tokens  []string
+	// segments is only populated if the pointer was created with
+	// extensions enabled, and it contains at least one extension token
+	segments []segment
+	// plans holds the access plans compiled for the types of the Go
+	// values that the pointer has been evaluated against
+	plans sync.Map // map[planKey]*accessPlan
 }
 
 // New creates a new JSON pointer from a path specification
-func New(pathspec string) (*Pointer, error) {
+func New(pathspec string, options ...NewOption) (*Pointer, error) {
+	return newPointer(pathspec, newPointerConfig(options))
+}
+
+// pointerConfig holds the settings specified by NewOptions. It is comparable,
+// so that it can be used as part of the key of cached pointers
+type pointerConfig struct {
+	segments       segmentConfig
+	maxDepth       int
+	maxTokenLength int
+}
+
+func newPointerConfig(options []NewOption) pointerConfig {
+	var cfg pointerConfig
+	for _, option := range options {
+		switch option.Ident() {
+		case identExtensions{}:
+			cfg.segments.extensions = option.Value().(bool)
+		case identSlices{}:
+			cfg.segments.slices = option.Value().(bool)
+		case identFilters{}:
+			cfg.segments.filters = option.Value().(bool)
+		case identUnions{}:
+			cfg.segments.unions = option.Value().(bool)
+		case identMaxDepth{}:
+			cfg.maxDepth = option.Value().(int)
+		case identMaxTokenLength{}:
+			cfg.maxTokenLength = option.Value().(int)
+		}
+	}
+	return cfg
+}
+
+func newPointer(pathspec string, cfg pointerConfig) (*Pointer, error) {
 	if pathspec == "" {
 		return &Pointer{pattern: "", tokens: nil}, nil
 	}
@@ -31,20 +87,31 @@ func New(pathspec string) (*Pointer, error) {
 	if !strings.HasPrefix(pathspec, "/") {
 		return nil, fmt.Errorf("JSON pointer must start with '/'")
 	}
+	if err := checkPointerLimits(pathspec, cfg.maxDepth, cfg.maxTokenLength); err != nil {
+		return nil, err
+	}
 
 	// Split the path into tokens, skipping the empty first element
 	parts := strings.Split(pathspec, "/")[1:]
-	
+
 	// Unescape each token
 	tokens := make([]string, len(parts))
 	for i, part := range parts {
 		tokens[i] = unescapeToken(part)
 	}
 
-	return &Pointer{
+	ptr := &Pointer{
 		pattern: pathspec,
 		tokens:  tokens,
-	}, nil
+	}
+	if cfg.segments.enabled() {
+		segments, err := compileSegments(tokens, cfg.segments)
+		if err != nil {
+			return nil, err
+		}
+		ptr.segments = segments
+	}
+	return ptr, nil
 }
 
 // Pattern returns the original path specification
@@ -52,14 +119,151 @@ func (p *Pointer) Pattern() string {
 	return p.pattern
 }
 
+// LogValue returns the pattern of the pointer, so that pointers are
+// recorded as structured attributes when logged using log/slog
+func (p *Pointer) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("pattern", p.pattern),
+		slog.Int("tokens", len(p.tokens)),
+	)
+}
+
+// Tokens returns the unescaped reference tokens of the pointer. The
+// returned slice is a copy, and may be freely modified by the caller.
+// It is mainly useful when implementing a Source that evaluates the
+// tokens of a pointer by itself.
+func (p *Pointer) Tokens() []string {
+	return slices.Clone(p.tokens)
+}
+
 // Retrieve retrieves the value at the JSON pointer location
-func (p *Pointer) Retrieve(dst any, target any) error {
+//
+// If target is an io.Reader, a JSON document is read from it. Values that
+// are not on the path to the location are skipped as they are read, so
+// that large documents can be processed without being buffered in memory.
+//
+// If the pointer contains extension tokens (see WithExtensions), the first
+// value that matches the pointer is retrieved.
+func (p *Pointer) Retrieve(dst any, target any, options ...RetrieveOption) error {
+	return p.retrieveWithConfig(dst, target, newRetrieveConfig(options))
+}
+
+func (p *Pointer) retrieveWithConfig(dst any, target any, cfg *retrieveConfig) (err error) {
+	if m := currentMetrics(); m != nil {
+		start := time.Now()
+		defer func() { m.RetrieveDone(time.Since(start), err) }()
+	}
+
+	if cfg.converters != nil {
+		return retrieveConverted(dst, cfg, func(dst any) error {
+			return p.retrieveQuoted(dst, target, cfg)
+		})
+	}
+	return p.retrieveQuoted(dst, target, cfg)
+}
+
+// retrieveQuoted retrieves the value into dst, falling back to decoding
+// scalars encoded inside of strings if WithQuotedFields is enabled
+func (p *Pointer) retrieveQuoted(dst any, target any, cfg *retrieveConfig) error {
+	err := p.retrieve(dst, target, cfg)
+	if err != nil && cfg.quotedFields && isScalarTarget(dst) {
+		// The value may be a scalar encoded inside of a string, as
+		// produced by the ",string" tag option
+		var v any
+		if p.retrieve(&v, target, cfg) == nil {
+			if s, ok := v.(string); ok {
+				return assignQuoted(dst, s)
+			}
+		}
+	}
+	return err
+}
+
+func (p *Pointer) retrieve(dst any, target any, cfg *retrieveConfig) error {
+	if p.segments != nil {
+		return p.retrieveFirst(dst, target, cfg)
+	}
+
+	// JSON bytes are parsed using a pooled parser, as the parsed values
+	// are only needed until they are converted and assigned to dst
+	switch v := target.(type) {
+	case []byte:
+		if err := cfg.checkDocumentSize(len(v)); err != nil {
+			return err
+		}
+		v, err := cfg.resolveDuplicates(v)
+		if err != nil {
+			return err
+		}
+		if cfg.lazyValues {
+			if ok, err := retrieveLazy(dst, v, p.tokens, cfg); ok {
+				return err
+			}
+		}
+		if b := cfg.jsonBackend(); b != nil {
+			return retrieveFromBackend(dst, b, v, p.tokens, cfg)
+		}
+		if cfg.stopEarly {
+			return retrieveFromJSONPrefix(dst, v, p.tokens, cfg)
+		}
+		return retrieveFromJSON(dst, v, p.tokens, cfg)
+	case string:
+		return p.retrieve(dst, []byte(v), cfg)
+	case io.Reader:
+		if cfg.ctx != nil {
+			v = contextReader{ctx: cfg.ctx, r: v}
+		}
+		if cfg.duplicateKeys != DuplicateKeysUnchecked {
+			data, err := io.ReadAll(cfg.limitReader(v))
+			if err != nil {
+				return fmt.Errorf("failed to read JSON: %w", err)
+			}
+			return p.retrieve(dst, data, cfg)
+		}
+		return retrieveFromReader(dst, cfg.limitReader(v), p.tokens, cfg)
+	}
+
+	if ok, err := p.retrievePlanned(dst, target, cfg); ok {
+		return err
+	}
+
 	// Create appropriate source based on target type
 	source, err := createSource(target)
 	if err != nil {
 		return err
 	}
-	return source.RetrieveJSONPointer(dst, p.pattern)
+	if ts, ok := source.(tokenSource); ok {
+		return ts.retrieveTokens(dst, p.tokens, cfg)
+	}
+	return callSource(dst, source, p.pattern, cfg)
+}
+
+// retrieveFromSource evaluates tokens against source, using the
+// tokenSource fast path if it is available
+func retrieveFromSource(dst any, source Source, tokens []string, cfg *retrieveConfig) error {
+	if ts, ok := source.(tokenSource); ok {
+		return ts.retrieveTokens(dst, tokens, cfg)
+	}
+	return callSource(dst, source, joinTokens(tokens), cfg)
+}
+
+// parseTokens parses a pointer specification into its reference tokens
+func parseTokens(ptrspec string) ([]string, error) {
+	ptr, err := New(ptrspec)
+	if err != nil {
+		return nil, err
+	}
+	return ptr.tokens, nil
+}
+
+// joinTokens creates a pointer specification from unescaped reference tokens
+func joinTokens(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escapeToken(token))
+	}
+	return sb.String()
 }
 
 // unescapeToken unescapes JSON pointer tokens
@@ -70,162 +274,263 @@ func unescapeToken(token string) string {
 	return token
 }
 
+// parseIndex parses an array index token, and checks that it
+// is within the bounds of an array of the given length
+func parseIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index '%s'", token)
+	}
+	if index < 0 || index >= length {
+		return 0, errNotFound("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+// escapeToken escapes a reference token so that it can be used
+// as part of a JSON pointer specification
+func escapeToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	// Order matters: '~' must be escaped before '/' is turned into "~1"
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return token
+}
+
 // createSource creates an appropriate source for the given target
 func createSource(target any) (Source, error) {
-	// First check if target already implements Source interface
-	if source, ok := target.(Source); ok {
-		return source, nil
+	// Types registered using RegisterSource are checked before anything
+	// else, so that their failures are reported as is
+	if source, ok, err := registeredSource(target); ok {
+		return source, err
 	}
-
-	// Use reflection to properly detect types
-	rv := reflect.ValueOf(target)
-	if !rv.IsValid() {
-		return scalarSource{data: target}, nil
+	// Then check if target already implements Source interface
+	if source, ok := asSource(target); ok {
+		return source, nil
 	}
 
-	// Handle specific types first
+	// At the top level, bytes and strings are treated as JSON documents
 	switch v := target.(type) {
 	case []byte:
 		return createJSONSource(v)
 	case string:
 		return createJSONSource([]byte(v))
-	case map[string]any:
-		return mapSource{data: v}, nil
+	case *OrderedMap:
+		return valueSource{data: v}, nil
+	case Getter:
+		if !isNilPointer(v) {
+			return valueSource{data: v}, nil
+		}
+	case json.Marshaler:
+		// Types such as decimals, timestamps or SDK wrappers may only
+		// expose their structure through marshaling. Like encoding/json,
+		// the marshaled representation takes precedence. This is checked
+		// before pointers are dereferenced, as MarshalJSON is often
+		// implemented on the pointer type
+		if !isNilPointer(v) {
+			buf, err := v.MarshalJSON()
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal %T: %w", v, err)
+			}
+			return createJSONSource(buf)
+		}
 	}
 
-	// Use reflection for more general type checking
+	rv := reflect.ValueOf(target)
 	switch rv.Kind() {
-	case reflect.Slice, reflect.Array:
-		// Convert to []any for uniform handling
-		length := rv.Len()
-		slice := make([]any, length)
-		for i := range length {
-			slice[i] = rv.Index(i).Interface()
-		}
-		return sliceSource{data: slice}, nil
 	case reflect.Map:
-		// Only handle string-keyed maps
-		if rv.Type().Key().Kind() == reflect.String {
-			// Convert to map[string]any for uniform handling
-			result := make(map[string]any)
-			for _, key := range rv.MapKeys() {
-				keyStr := key.String()
-				value := rv.MapIndex(key).Interface()
-				result[keyStr] = value
-			}
-			return mapSource{data: result}, nil
-		}
 		// Non-string-keyed maps cannot be accessed with JSON pointer
-		return nil, fmt.Errorf("cannot use JSON pointer with non-string-keyed map type %s", rv.Type())
-	case reflect.Struct:
-		return structSource{data: target}, nil
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot use JSON pointer with non-string-keyed map type %s", rv.Type())
+		}
 	case reflect.Ptr:
 		// For pointers, recurse with the pointed-to value
-		if rv.IsNil() {
-			return scalarSource{data: target}, nil
+		if !rv.IsNil() {
+			return createSource(rv.Elem().Interface())
 		}
-		return createSource(rv.Elem().Interface())
-	default:
-		// Scalars (int, bool, float64, etc.)
-		return scalarSource{data: target}, nil
 	}
+
+	// Everything else, including maps, slices, structs and scalars, is
+	// handled by the generic navigator
+	return valueSource{data: target}, nil
 }
 
 // createJSONSource creates a jsonSource with pre-parsed JSON data
 func createJSONSource(data []byte) (Source, error) {
 	var p fastjson.Parser
-	parsed, err := p.ParseBytes(data)
+	parsed, err := parseJSON(&p, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, err
 	}
-	return jsonSource{data: data, parsed: parsed}, nil
+	return jsonSource{data: data, parsed: parsed, owned: true}, nil
 }
 
-// scalarSource handles scalar values (int, bool, float64, etc.)
-type scalarSource struct {
-	data any
+// materializeJSON converts a JSON document into generic Go values
+func materializeJSON(data []byte, cfg *retrieveConfig) (any, error) {
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+
+	parsed, err := parseJSON(p, data)
+	if err != nil {
+		return nil, err
+	}
+	return jsonSource{data: data, parsed: parsed}.materialize(cfg)
 }
 
-func (s scalarSource) RetrieveJSONPointer(dst any, ptrspec string) error {
-	// Scalars can only be retrieved with empty pointer
-	if ptrspec != "" {
-		return fmt.Errorf("cannot index into scalar value %T with pointer '%s'", s.data, ptrspec)
+// retrieveFromJSON parses data using a parser borrowed from parserPool,
+// and retrieves the value pointed by ptrspec. The parser is returned to
+// the pool once the value has been assigned to dst
+func retrieveFromJSON(dst any, data []byte, tokens []string, cfg *retrieveConfig) error {
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+
+	parsed, err := parseJSON(p, data)
+	if err != nil {
+		return err
 	}
-	return blackmagic.AssignIfCompatible(dst, s.data)
+	return jsonSource{data: data, parsed: parsed}.retrieveTokens(dst, tokens, cfg)
 }
 
 // jsonSource handles JSON byte data
 type jsonSource struct {
 	data   []byte
 	parsed *fastjson.Value
+	// owned is true if parsed was created by a parser that is never
+	// reused, so that strings can be aliased from the parsed values
+	owned bool
 }
 
 func (s jsonSource) RetrieveJSONPointer(dst any, ptrspec string) error {
-	// Use cached parsed JSON data
-	v := s.parsed
+	tokens, err := parseTokens(ptrspec)
+	if err != nil {
+		return err
+	}
+	return s.retrieveTokens(dst, tokens, defaultRetrieveConfig)
+}
 
-	// Handle empty pointer - return the parsed data directly
-	if ptrspec == "" {
-		return s.assignFromValue(dst, v)
+func (s jsonSource) retrieveTokens(dst any, tokens []string, cfg *retrieveConfig) error {
+	// Raw messages receive the exact bytes of the subtree, and composite
+	// types such as structs are decoded directly from them
+	if raw, ok := dst.(*json.RawMessage); ok {
+		b, err := locateRaw(s.data, tokens, cfg)
+		if err != nil {
+			return err
+		}
+		*raw = append((*raw)[:0], b...)
+		return nil
+	}
+	if isDecodeTarget(dst) {
+		b, err := locateRaw(s.data, tokens, cfg)
+		if err != nil {
+			return err
+		}
+		return decodeInto(dst, b)
+	}
+	// Parsers borrowed from parserPool are reused, so strings can only
+	// be aliased from the bytes of the document
+	if cfg.zeroCopyStrings && !s.owned {
+		if assignAliasedString(dst, s.data, tokens, cfg) {
+			return nil
+		}
 	}
 
-	// Parse the pointer and navigate to the value
-	ptr, err := New(ptrspec)
+	// Navigate through the cached parsed JSON using the pointer tokens.
+	// An empty pointer refers to the parsed data itself
+	current, err := navigateJSON(s.parsed, tokens, cfg)
 	if err != nil {
 		return err
 	}
+	if cfg.zeroCopyStrings && s.owned {
+		if assignParsedString(dst, current) {
+			return nil
+		}
+	}
+	return s.assignFromValue(dst, current, cfg)
+}
 
-	// Navigate through the JSON using the pointer tokens
+// navigateJSON follows tokens starting from the parsed JSON value v
+func navigateJSON(v *fastjson.Value, tokens []string, cfg *retrieveConfig) (*fastjson.Value, error) {
 	current := v
-	for _, token := range ptr.tokens {
-		switch current.Type() {
-		case fastjson.TypeObject:
-			current = current.Get(token)
-			if current == nil {
-				return fmt.Errorf("property '%s' not found", token)
-			}
-		case fastjson.TypeArray:
-			index, err := strconv.Atoi(token)
-			if err != nil {
-				return fmt.Errorf("invalid array index '%s'", token)
-			}
-			arr, err := current.Array()
-			if err != nil {
-				return fmt.Errorf("failed to get array: %w", err)
-			}
-			if index < 0 || index >= len(arr) {
-				return fmt.Errorf("array index %d out of bounds", index)
-			}
-			current = arr[index]
-		default:
-			return fmt.Errorf("cannot index into %s with '%s'", current.Type(), token)
+	for i, token := range tokens {
+		next, err := childJSON(current, token)
+		if cfg.trace != nil {
+			kind, _ := kindOf(jsonNode{current})
+			cfg.traceStep(tokens, i, kind, err)
+		}
+		if err != nil {
+			return nil, cfg.stepError(tokens, i, err)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// childJSON returns the value referred to by token within the parsed
+// JSON value v
+func childJSON(v *fastjson.Value, token string) (*fastjson.Value, error) {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		next := v.Get(token)
+		if next == nil {
+			return nil, errNotFound("property '%s' not found", token)
+		}
+		return next, nil
+	case fastjson.TypeArray:
+		arr, err := v.Array()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get array: %w", err)
+		}
+		index, err := parseIndex(token, len(arr))
+		if err != nil {
+			return nil, err
 		}
+		return arr[index], nil
+	default:
+		return nil, fmt.Errorf("cannot index into %s with '%s'", v.Type(), token)
 	}
+}
 
-	return s.assignFromValue(dst, current)
+// materialize converts the entire parsed document into generic Go values
+func (s jsonSource) materialize(cfg *retrieveConfig) (any, error) {
+	var v any
+	if err := s.assignFromValue(&v, s.parsed, cfg); err != nil {
+		return nil, err
+	}
+	return v, nil
 }
 
 // assignFromValue converts a fastjson.Value to a Go value and assigns it to dst
-func (s jsonSource) assignFromValue(dst any, v *fastjson.Value) error {
+func (s jsonSource) assignFromValue(dst any, v *fastjson.Value, cfg *retrieveConfig) error {
 	if v == nil {
-		return blackmagic.AssignIfCompatible(dst, nil)
+		return cfg.assign(dst, nil)
 	}
 
 	switch v.Type() {
 	case fastjson.TypeNull:
-		return blackmagic.AssignIfCompatible(dst, nil)
+		return cfg.assign(dst, nil)
 	case fastjson.TypeString:
 		str, err := v.StringBytes()
 		if err != nil {
 			return fmt.Errorf("failed to get string value: %w", err)
 		}
-		return blackmagic.AssignIfCompatible(dst, string(str))
+		return cfg.assign(dst, string(str))
 	case fastjson.TypeNumber:
-		return blackmagic.AssignIfCompatible(dst, v.GetFloat64())
+		// Integer destinations are populated from the original text,
+		// so that no precision is lost by going through float64
+		if ok, err := assignInteger(dst, v.MarshalTo(nil)); ok || err != nil {
+			return err
+		}
+		num, err := numberValue(v.MarshalTo(nil), cfg.numberMode)
+		if err != nil {
+			return err
+		}
+		return cfg.assign(dst, num)
 	case fastjson.TypeTrue:
-		return blackmagic.AssignIfCompatible(dst, true)
+		return cfg.assign(dst, true)
 	case fastjson.TypeFalse:
-		return blackmagic.AssignIfCompatible(dst, false)
+		return cfg.assign(dst, false)
 	case fastjson.TypeArray:
 		arr, err := v.Array()
 		if err != nil {
@@ -234,247 +539,655 @@ func (s jsonSource) assignFromValue(dst any, v *fastjson.Value) error {
 		result := make([]any, len(arr))
 		for i, item := range arr {
 			var temp any
-			if err := s.assignFromValue(&temp, item); err != nil {
+			if err := s.assignFromValue(&temp, item, cfg); err != nil {
 				return fmt.Errorf("failed to convert array item %d: %w", i, err)
 			}
 			result[i] = temp
 		}
-		return blackmagic.AssignIfCompatible(dst, result)
+		return cfg.assign(dst, result)
 	case fastjson.TypeObject:
 		obj, err := v.Object()
 		if err != nil {
 			return fmt.Errorf("failed to get object: %w", err)
 		}
+		// Visit cannot be stopped, so the members that follow a member
+		// that fails to convert are skipped
+		var convErr error
+		convert := func(key []byte, val *fastjson.Value) (any, bool) {
+			if convErr != nil {
+				return nil, false
+			}
+			var temp any
+			if err := s.assignFromValue(&temp, val, cfg); err != nil {
+				convErr = fmt.Errorf("failed to convert member '%s': %w", key, err)
+				return nil, false
+			}
+			return temp, true
+		}
+
+		if cfg.orderedObjects {
+			result := NewOrderedMap()
+			obj.Visit(func(key []byte, val *fastjson.Value) {
+				if temp, ok := convert(key, val); ok {
+					result.Set(string(key), temp)
+				}
+			})
+			if convErr != nil {
+				return convErr
+			}
+			return cfg.assign(dst, result)
+		}
+
 		result := make(map[string]any)
 		obj.Visit(func(key []byte, val *fastjson.Value) {
-			var temp any
-			if err := s.assignFromValue(&temp, val); err == nil {
+			if temp, ok := convert(key, val); ok {
 				result[string(key)] = temp
 			}
 		})
-		return blackmagic.AssignIfCompatible(dst, result)
+		if convErr != nil {
+			return convErr
+		}
+		return cfg.assign(dst, result)
 	default:
 		return fmt.Errorf("unsupported JSON type: %s", v.Type())
 	}
 }
 
-// mapSource handles map[string]any data
-type mapSource struct {
-	data map[string]any
+// numberValue converts the text of a JSON number to a Go value according
+// to mode
+func numberValue(text []byte, mode NumberMode) (any, error) {
+	switch mode {
+	case NumberJSONNumber:
+		return json.Number(text), nil
+	case NumberInt64:
+		if i, err := strconv.ParseInt(string(text), 10, 64); err == nil {
+			return i, nil
+		}
+		return parseFloat(text)
+	case NumberBigFloat:
+		// Allow for roughly 4 bits per digit, so that no digits are lost
+		prec := max(64, uint(len(text))*4)
+		f, _, err := big.ParseFloat(string(text), 10, prec, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse number %s: %w", text, err)
+		}
+		return f, nil
+	default:
+		// Integers that cannot be represented exactly by a float64
+		// are kept as int64 or uint64 to avoid silently corrupting them
+		if i, err := strconv.ParseInt(string(text), 10, 64); err == nil {
+			if i > maxSafeInteger || i < -maxSafeInteger {
+				return i, nil
+			}
+		} else if u, err := strconv.ParseUint(string(text), 10, 64); err == nil {
+			return u, nil
+		}
+		return parseFloat(text)
+	}
 }
 
-func (s mapSource) RetrieveJSONPointer(dst any, ptrspec string) error {
-	// Handle empty pointer - return the data directly
-	if ptrspec == "" {
-		return blackmagic.AssignIfCompatible(dst, s.data)
+// parseFloat parses the text of a JSON number as a float64. Numbers that
+// are out of range are converted to infinity, as fastjson does
+func parseFloat(text []byte) (any, error) {
+	f, err := strconv.ParseFloat(string(text), 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, fmt.Errorf("failed to parse number %s: %w", text, err)
 	}
+	return f, nil
+}
 
-	ptr, err := New(ptrspec)
-	if err != nil {
-		return err
+// maxSafeInteger is the largest integer n such that n and n+1 can both be
+// exactly represented by a float64
+const maxSafeInteger = 1<<53 - 1
+
+// assignInteger assigns the JSON number in text to dst, if dst is a
+// pointer to an integer type and text represents an integral value that
+// fits in it. It reports whether the assignment was handled
+func assignInteger(dst any, text []byte) (bool, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false, nil
 	}
 
-	current := any(s.data)
-	
-	for _, token := range ptr.tokens {
-		switch curr := current.(type) {
-		case map[string]any:
-			val, exists := curr[token]
-			if !exists {
-				return fmt.Errorf("property '%s' not found", token)
-			}
-			current = val
-		case []any:
-			index, err := strconv.Atoi(token)
-			if err != nil {
-				return fmt.Errorf("invalid array index '%s'", token)
-			}
-			if index < 0 || index >= len(curr) {
-				return fmt.Errorf("array index %d out of bounds", index)
-			}
-			current = curr[index]
-		default:
-			return fmt.Errorf("cannot index into %T with '%s'", current, token)
+	elem := rv.Elem()
+	switch elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(text), 10, elem.Type().Bits())
+		if err != nil {
+			return false, nil
+		}
+		elem.SetInt(i)
+		return true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(string(text), 10, elem.Type().Bits())
+		if err != nil {
+			return false, nil
 		}
+		elem.SetUint(u)
+		return true, nil
+	default:
+		return false, nil
 	}
-
-	return blackmagic.AssignIfCompatible(dst, current)
 }
 
-// sliceSource handles []any data
-type sliceSource struct {
-	data []any
+// valueSource handles arbitrary Go values. Maps, slices, arrays, structs
+// and pointers may be freely mixed, as the type of each value is examined
+// at every step of the traversal
+type valueSource struct {
+	data any
 }
 
-func (s sliceSource) RetrieveJSONPointer(dst any, ptrspec string) error {
-	// Handle empty pointer - return the data directly
-	if ptrspec == "" {
-		return blackmagic.AssignIfCompatible(dst, s.data)
-	}
-
-	ptr, err := New(ptrspec)
+func (s valueSource) RetrieveJSONPointer(dst any, ptrspec string) error {
+	tokens, err := parseTokens(ptrspec)
 	if err != nil {
 		return err
 	}
+	return s.retrieveTokens(dst, tokens, defaultRetrieveConfig)
+}
 
-	// First token must be an array index
-	index, err := strconv.Atoi(ptr.tokens[0])
+func (s valueSource) retrieveTokens(dst any, tokens []string, cfg *retrieveConfig) error {
+	v, rest, err := navigate(s.data, tokens, cfg)
 	if err != nil {
-		return fmt.Errorf("invalid array index '%s'", ptr.tokens[0])
+		return err
+	}
+	if len(rest) > 0 {
+		return retrieveFromSource(dst, v.(Source), rest, cfg.within(tokens[:len(tokens)-len(rest)]))
 	}
-	if index < 0 || index >= len(s.data) {
-		return fmt.Errorf("array index %d out of bounds", index)
+	// Raw JSON values are decoded only once they are addressed
+	if raw, ok := rawJSON(v); ok {
+		return retrieveFromJSON(dst, raw, nil, cfg)
 	}
+	return cfg.assign(dst, v)
+}
 
-	// If only one token, return the element
-	if len(ptr.tokens) == 1 {
-		return blackmagic.AssignIfCompatible(dst, s.data[index])
+// navigate follows tokens starting from node, and returns the value at the
+// end of the path. If a custom Source is encountered along the way,
+// navigation stops there, and the Source is returned along with the tokens
+// that it is responsible for evaluating
+func navigate(node any, tokens []string, cfg *retrieveConfig) (any, []string, error) {
+	current := node
+	for i, token := range tokens {
+		if source, ok := asSource(current); ok {
+			return source, tokens[i:], nil
+		}
+		// Partially decoded JSON, such as the members of a
+		// map[string]json.RawMessage, is only parsed once reached
+		if raw, ok := rawJSON(current); ok {
+			return rawSource(raw), tokens[i:], nil
+		}
+
+		next, err := child(current, token, cfg)
+		if cfg.trace != nil {
+			kind, _ := kindOf(current)
+			cfg.traceStep(tokens, i, kind, err)
+		}
+		if err != nil {
+			return nil, nil, cfg.stepError(tokens, i, err)
+		}
+		current = next
 	}
+	return current, nil, nil
+}
+
+// rawSource is a Source for JSON values that are embedded in Go values as
+// json.RawMessage. The JSON is parsed each time it is accessed, using a
+// pooled parser
+type rawSource []byte
 
-	// Create new pointer for remaining tokens
-	remainingPath := "/" + strings.Join(ptr.tokens[1:], "/")
-	source, err := createSource(s.data[index])
+func (s rawSource) RetrieveJSONPointer(dst any, ptrspec string) error {
+	tokens, err := parseTokens(ptrspec)
 	if err != nil {
 		return err
 	}
-	return source.RetrieveJSONPointer(dst, remainingPath)
+	return s.retrieveTokens(dst, tokens, defaultRetrieveConfig)
 }
 
-// structSource handles struct data with JSON tag caching
-type structSource struct {
-	data any
+func (s rawSource) retrieveTokens(dst any, tokens []string, cfg *retrieveConfig) error {
+	return retrieveFromJSON(dst, s, tokens, cfg)
+}
+
+// rawJSON returns the JSON bytes held by v, if v is a json.RawMessage.
+// Like encoding/json, an empty json.RawMessage is treated as null
+func rawJSON(v any) ([]byte, bool) {
+	var raw json.RawMessage
+	switch v := v.(type) {
+	case json.RawMessage:
+		raw = v
+	case *json.RawMessage:
+		if v == nil {
+			return nil, false
+		}
+		raw = *v
+	default:
+		return nil, false
+	}
+	if len(raw) == 0 {
+		return []byte("null"), true
+	}
+	return raw, true
+}
+
+var sourceType = reflect.TypeFor[Source]()
+
+// asSource returns v as a Source if it implements the interface. Values
+// whose pointer type implements Source (e.g. structs stored by value in
+// a map or a field) are also detected, using a pointer to a copy of v.
+// Values of types registered using RegisterSource use the registered
+// factory
+func asSource(v any) (Source, bool) {
+	if source, ok, err := registeredSource(v); ok {
+		if err != nil {
+			return failedSource{err: err}, true
+		}
+		return source, true
+	}
+	if source, ok := v.(Source); ok {
+		return source, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if !reflect.PointerTo(rv.Type()).Implements(sourceType) {
+		return nil, false
+	}
+	ptr := reflect.New(rv.Type())
+	ptr.Elem().Set(rv)
+	return ptr.Interface().(Source), true
+}
+
+// child returns the value referred to by token within node
+func child(node any, token string, cfg *retrieveConfig) (any, error) {
+	// Fast paths for the types produced by encoding/json
+	switch v := node.(type) {
+	case map[string]any:
+		val, exists := v[token]
+		if !exists {
+			return nil, errNotFound("property '%s' not found", token)
+		}
+		return val, nil
+	case []any:
+		index, err := parseIndex(token, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return v[index], nil
+	case *OrderedMap:
+		val, exists := v.Get(token)
+		if !exists {
+			return nil, errNotFound("property '%s' not found", token)
+		}
+		return val, nil
+	case Getter:
+		if !isNilPointer(v) {
+			return getMember(v, token)
+		}
+	}
+
+	// Like encoding/json, values that marshal themselves are traversed
+	// through their JSON representation
+	if v, ok, err := marshaledValue(node, cfg); err != nil {
+		return nil, err
+	} else if ok {
+		return child(v, token, cfg)
+	}
+
+	rv := reflect.ValueOf(node)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			// A nil pointer has no members, so the location is absent
+			return nil, errNotFound("cannot index into nil %s with '%s'", rv.Type(), token)
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		// Only string-keyed maps can be indexed. The key type may
+		// be a named string type, so convert the token
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot index into non-string-keyed map type %s with '%s'", rv.Type(), token)
+		}
+		val := rv.MapIndex(reflect.ValueOf(token).Convert(rv.Type().Key()))
+		if !val.IsValid() {
+			return nil, errNotFound("property '%s' not found", token)
+		}
+		return val.Interface(), nil
+	case reflect.Slice, reflect.Array:
+		index, err := parseIndex(token, rv.Len())
+		if err != nil {
+			return nil, err
+		}
+		return rv.Index(index).Interface(), nil
+	case reflect.Struct:
+		return getField(rv, token, cfg)
+	default:
+		// Scalars (int, bool, float64, etc.) and nil
+		return nil, fmt.Errorf("cannot index into scalar value %T with '%s'", node, token)
+	}
+}
+
+// marshaledValue returns the generic representation of v, if v implements
+// json.Marshaler. As with encoding/json, nil pointers are not marshaled
+func marshaledValue(v any, cfg *retrieveConfig) (any, bool, error) {
+	m, ok := v.(json.Marshaler)
+	if !ok {
+		return nil, false, nil
+	}
+	if isNilPointer(v) {
+		return nil, false, nil
+	}
+
+	buf, err := m.MarshalJSON()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal %T: %w", v, err)
+	}
+	materialized, err := materializeJSON(buf, cfg)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid JSON produced by %T: %w", v, err)
+	}
+	return materialized, true, nil
 }
 
+// DefaultStructCacheSize is the number of struct types whose field
+// information is cached by default
+const DefaultStructCacheSize = 4096
+
 // Cache for struct field information
 var (
-	structCache = make(map[reflect.Type]*structInfo)
-	cacheMutex  sync.RWMutex
+	structCache     = make(map[structKey]*structInfo)
+	structCacheSize = DefaultStructCacheSize
+	cacheMutex      sync.RWMutex
 )
 
+// SetStructCacheSize sets the maximum number of struct types whose field
+// information is cached. When the cache is full, an arbitrary entry is
+// evicted to make room for a new one. A size of 0 or less disables
+// caching, which may be useful for programs that create struct types
+// dynamically. See also WithStructCache.
+func SetStructCacheSize(n int) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	structCacheSize = n
+	for key := range structCache {
+		if len(structCache) <= max(n, 0) {
+			break
+		}
+		delete(structCache, key)
+	}
+}
+
+// ClearCache removes all entries from the caches of the package: the
+// cache of struct field information, and the cache of pointers used by
+// Cached. Pointers keep the access plans they have compiled for the
+// types they have been evaluated against.
+func ClearCache() {
+	cacheMutex.Lock()
+	clear(structCache)
+	cacheMutex.Unlock()
+
+	pointerCache.clear()
+}
+
+// structKey identifies the field layout of a struct type, as seen
+// through a particular struct tag
+type structKey struct {
+	typ reflect.Type
+	tag string
+}
+
 type structInfo struct {
 	fields map[string]*fieldInfo
+	// names lists the JSON names of the fields in declaration order
+	names []string
 }
 
 type fieldInfo struct {
 	index    []int
 	jsonName string
+	// tagged is true if the name of the field was given by its tag
+	tagged bool
+	// quoted is true if the field is tagged with the ",string" option
+	quoted bool
 }
 
-func (s structSource) RetrieveJSONPointer(dst any, ptrspec string) error {
-	// Handle empty pointer - return the data directly
-	if ptrspec == "" {
-		return blackmagic.AssignIfCompatible(dst, s.data)
+// getField returns the value of the field whose JSON name is fieldName
+func getField(val reflect.Value, fieldName string, cfg *retrieveConfig) (any, error) {
+	info := getStructInfo(val.Type(), cfg)
+	fieldInfo, exists := info.lookup(fieldName, cfg.caseInsensitive)
+	if !exists {
+		return nil, errNotFound("field '%s' not found in struct %s", fieldName, val.Type())
 	}
 
-	ptr, err := New(ptrspec)
+	// Promoted fields may be reached through nil embedded pointers, in
+	// which case the field is absent
+	fieldVal, err := val.FieldByIndexErr(fieldInfo.index)
 	if err != nil {
-		return err
+		return nil, errNotFound("cannot access field '%s' of struct %s: %s", fieldName, val.Type(), err)
 	}
-
-	current := s.data
-	
-	for _, token := range ptr.tokens {
-		current, err = s.getField(current, token)
+	if !fieldVal.CanInterface() {
+		return nil, fmt.Errorf("cannot access field '%s' of struct %s", fieldName, val.Type())
+	}
+	if fieldInfo.quoted && cfg.quotedFields && !(fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil()) {
+		buf, err := json.Marshal(fieldVal.Interface())
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("failed to encode field '%s' of struct %s: %w", fieldName, val.Type(), err)
 		}
+		return string(buf), nil
 	}
-
-	return blackmagic.AssignIfCompatible(dst, current)
+	return fieldVal.Interface(), nil
 }
 
-func (s structSource) getField(obj any, fieldName string) (any, error) {
-	val := reflect.ValueOf(obj)
-	
-	// Handle pointers
-	for val.Kind() == reflect.Ptr {
-		if val.IsNil() {
-			return nil, fmt.Errorf("cannot access field of nil pointer")
-		}
-		val = val.Elem()
+// lookup returns the field whose JSON name is name. If foldCase is true
+// and there is no exact match, the first field in declaration order whose
+// name matches case-insensitively is returned, like encoding/json does
+func (info *structInfo) lookup(name string, foldCase bool) (*fieldInfo, bool) {
+	if field, exists := info.fields[name]; exists {
+		return field, true
 	}
-
-	if val.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("cannot access field '%s' of non-struct type %T", fieldName, obj)
+	if !foldCase {
+		return nil, false
 	}
-
-	info := getStructInfo(val.Type())
-	fieldInfo, exists := info.fields[fieldName]
-	if !exists {
-		return nil, fmt.Errorf("field '%s' not found in struct %T", fieldName, obj)
+	for _, candidate := range info.names {
+		if strings.EqualFold(candidate, name) {
+			return info.fields[candidate], true
+		}
 	}
-
-	fieldVal := val.FieldByIndex(fieldInfo.index)
-	return fieldVal.Interface(), nil
+	return nil, false
 }
 
-func getStructInfo(t reflect.Type) *structInfo {
+func getStructInfo(t reflect.Type, cfg *retrieveConfig) *structInfo {
+	tag := cfg.structTag()
+	if cfg.noStructCache {
+		return newStructInfo(t, tag)
+	}
+
+	key := structKey{typ: t, tag: tag}
 	cacheMutex.RLock()
-	if info, exists := structCache[t]; exists {
+	if info, exists := structCache[key]; exists {
 		cacheMutex.RUnlock()
+		observeCache("struct", true)
 		return info
 	}
 	cacheMutex.RUnlock()
+	observeCache("struct", false)
 
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
 	// Double-check after acquiring write lock
-	if info, exists := structCache[t]; exists {
+	if info, exists := structCache[key]; exists {
 		return info
 	}
 
+	info := newStructInfo(t, tag)
+	if structCacheSize <= 0 {
+		return info
+	}
+	if len(structCache) >= structCacheSize {
+		for key := range structCache {
+			delete(structCache, key)
+			break
+		}
+	}
+	structCache[key] = info
+	return info
+}
+
+func newStructInfo(t reflect.Type, tag string) *structInfo {
 	info := &structInfo{
 		fields: make(map[string]*fieldInfo),
 	}
-
-	// Process all fields, including embedded ones
-	processFields(t, nil, info)
-
-	structCache[t] = info
+	for _, field := range dominantFields(collectFields(t, tag)) {
+		info.fields[field.jsonName] = field
+		info.names = append(info.names, field.jsonName)
+	}
 	return info
 }
 
-func processFields(t reflect.Type, index []int, info *structInfo) {
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		fieldIndex := append(index, i)
+// collectFields returns the fields of t, including those promoted from
+// embedded structs. Like encoding/json, embedded structs are explored
+// breadth first, and each struct type is only explored at the shallowest
+// depth it appears at. Fields with the same name may be returned more
+// than once, and must be resolved using dominantFields
+func collectFields(t reflect.Type, tag string) []*fieldInfo {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []*fieldInfo
+	visited := make(map[reflect.Type]bool)
+	next := []embedded{{typ: t}}
+	for len(next) > 0 {
+		current := next
+		next = nil
 
-		// Handle embedded fields
-		if field.Anonymous {
-			fieldType := field.Type
-			if fieldType.Kind() == reflect.Ptr {
-				fieldType = fieldType.Elem()
+		// The same type may be embedded more than once at the same
+		// depth. All instances are explored so that their fields
+		// conflict with each other
+		var explored []reflect.Type
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
 			}
-			if fieldType.Kind() == reflect.Struct {
-				processFields(fieldType, fieldIndex, info)
+			explored = append(explored, e.typ)
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				field := e.typ.Field(i)
+				fieldType := field.Type
+				if field.Anonymous {
+					if fieldType.Kind() == reflect.Ptr {
+						fieldType = fieldType.Elem()
+					}
+					// Embedded structs of unexported types may still
+					// have exported fields to promote
+					if !field.IsExported() && fieldType.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+
+				tagValue := field.Tag.Get(tag)
+				if tagValue == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tagValue, ",")
+
+				// Use a full slice expression so that sibling fields never
+				// share (and overwrite) the same backing array
+				index := append(e.index[:len(e.index):len(e.index)], i)
+
+				// Embedded structs without a name in their tag have their
+				// fields promoted. Everything else is a regular field
+				if name == "" && field.Anonymous && fieldType.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: fieldType, index: index})
+					continue
+				}
+
+				info := &fieldInfo{
+					index:    index,
+					jsonName: name,
+					tagged:   name != "",
+				}
+				if name == "" {
+					info.jsonName = field.Name
+				}
+				for _, opt := range strings.Split(opts, ",") {
+					if opt == "string" {
+						info.quoted = isQuotable(field.Type)
+					}
+				}
+				fields = append(fields, info)
 			}
-			continue
 		}
-
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
+		for _, typ := range explored {
+			visited[typ] = true
 		}
+	}
+	return fields
+}
 
-		// Get JSON tag
-		jsonTag := field.Tag.Get("json")
-		if jsonTag == "-" {
-			continue
+// dominantFields resolves fields sharing the same name using the rules of
+// encoding/json: the shallowest field wins, and among fields at the same
+// depth, the single one with a name given by its tag wins. If there is no
+// single winner, the name is dropped altogether. The surviving fields are
+// returned in declaration order
+func dominantFields(fields []*fieldInfo) []*fieldInfo {
+	byName := make(map[string][]*fieldInfo)
+	for _, field := range fields {
+		byName[field.jsonName] = append(byName[field.jsonName], field)
+	}
+
+	var result []*fieldInfo
+	for _, candidates := range byName {
+		depth := len(candidates[0].index)
+		for _, field := range candidates[1:] {
+			depth = min(depth, len(field.index))
 		}
 
-		// Parse JSON tag
-		jsonName := field.Name
-		if jsonTag != "" {
-			parts := strings.Split(jsonTag, ",")
-			if parts[0] != "" {
-				jsonName = parts[0]
+		var shallowest, tagged []*fieldInfo
+		for _, field := range candidates {
+			if len(field.index) != depth {
+				continue
+			}
+			shallowest = append(shallowest, field)
+			if field.tagged {
+				tagged = append(tagged, field)
 			}
 		}
 
-		info.fields[jsonName] = &fieldInfo{
-			index:    fieldIndex,
-			jsonName: jsonName,
+		switch {
+		case len(shallowest) == 1:
+			result = append(result, shallowest[0])
+		case len(tagged) == 1:
+			result = append(result, tagged[0])
 		}
 	}
-}
\ No newline at end of file
+
+	slices.SortFunc(result, func(a, b *fieldInfo) int {
+		return slices.Compare(a.index, b.index)
+	})
+	return result
+}
+
+// isQuotable returns true if the ",string" tag option applies to fields
+// of type t. Like encoding/json, only strings, numbers and booleans (or
+// unnamed pointers to them) are affected
+func isQuotable(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
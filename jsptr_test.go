@@ -1,8 +1,17 @@
 package jsptr_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/lestrrat-go/blackmagic"
 	"github.com/lestrrat-go/jsptr"
@@ -148,7 +157,7 @@ func TestPointerRetrieveFromJSON(t *testing.T) {
 
 func TestPointerRetrieveFromMap(t *testing.T) {
 	data := map[string]any{
-		"foo": "bar",
+		"foo":   "bar",
 		"array": []any{1, 2, 3},
 		"nested": map[string]any{
 			"key": "value",
@@ -413,7 +422,7 @@ func (c *CustomSource) RetrieveJSONPointer(dst any, ptrspec string) error {
 			return blackmagic.AssignIfCompatible(dst, value)
 		}
 	}
-	
+
 	return fmt.Errorf("key not found")
 }
 
@@ -470,6 +479,26 @@ func TestPointerWithDifferentSliceTypes(t *testing.T) {
 			expected: 2,
 			wantErr:  false,
 		},
+		{
+			name:     "[]int root is returned as is",
+			data:     []int{1, 2, 3},
+			pointer:  "",
+			expected: []int{1, 2, 3},
+			wantErr:  false,
+		},
+		{
+			name:     "slice of structs",
+			data:     []struct{ Name string }{{Name: "a"}, {Name: "b"}},
+			pointer:  "/1/Name",
+			expected: "b",
+			wantErr:  false,
+		},
+		{
+			name:    "[]int out of bounds",
+			data:    []int{1, 2, 3},
+			pointer: "/3",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -555,6 +584,8 @@ func TestPointerWithScalarTypes(t *testing.T) {
 	}
 }
 
+type namedKey string
+
 func TestPointerWithDifferentMapTypes(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -577,6 +608,33 @@ func TestPointerWithDifferentMapTypes(t *testing.T) {
 			expected: "value",
 			wantErr:  false,
 		},
+		{
+			name:     "map with named string keys",
+			data:     map[namedKey]int{"foo": 42},
+			pointer:  "/foo",
+			expected: 42,
+			wantErr:  false,
+		},
+		{
+			name:     "nested map[string]map[string]int",
+			data:     map[string]map[string]int{"foo": {"bar": 1}},
+			pointer:  "/foo/bar",
+			expected: 1,
+			wantErr:  false,
+		},
+		{
+			name:     "map[string]int root is returned as is",
+			data:     map[string]int{"foo": 42},
+			pointer:  "",
+			expected: map[string]int{"foo": 42},
+			wantErr:  false,
+		},
+		{
+			name:    "map[string]int missing key",
+			data:    map[string]int{"foo": 42},
+			pointer: "/bar",
+			wantErr: true,
+		},
 		{
 			name:    "map[int]string - should be scalar",
 			data:    map[int]string{1: "one", 2: "two"},
@@ -604,12 +662,1044 @@ func TestPointerWithDifferentMapTypes(t *testing.T) {
 func TestPointerWithInvalidJSON(t *testing.T) {
 	// Test that invalid JSON is properly handled during source creation
 	invalidJSON := `{"foo": "bar", "invalid": }`
-	
+
 	ptr, err := jsptr.New("/foo")
 	require.NoError(t, err)
-	
+
 	var result string
 	err = ptr.Retrieve(&result, []byte(invalidJSON))
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to parse JSON")
 }
+
+func TestPointerRetrieveFromJSONConcurrently(t *testing.T) {
+	// Parsers are pooled, so make sure that values retrieved from
+	// one document are not clobbered when the parser is reused
+	ptr, err := jsptr.New("/name")
+	require.NoError(t, err)
+
+	const count = 100
+	results := make([]string, count)
+	var wg sync.WaitGroup
+	for i := range count {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src := fmt.Sprintf(`{"name": "name-%d", "padding": "%s"}`, i, strings.Repeat("x", i))
+			_ = ptr.Retrieve(&results[i], []byte(src))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		require.Equal(t, fmt.Sprintf("name-%d", i), result)
+	}
+}
+
+func TestPointerEscapingInNestedSources(t *testing.T) {
+	// Tokens must survive being handed down from one source to another
+	data := []any{
+		map[string]any{"foo/bar": "value1", "foo~bar": "value2"},
+	}
+
+	for pointer, expected := range map[string]string{
+		"/0/foo~1bar": "value1",
+		"/0/foo~0bar": "value2",
+	} {
+		ptr, err := jsptr.New(pointer)
+		require.NoError(t, err)
+
+		var result string
+		require.NoError(t, ptr.Retrieve(&result, data), pointer)
+		require.Equal(t, expected, result, pointer)
+	}
+}
+
+func TestPointerWithMixedTypes(t *testing.T) {
+	type Item struct {
+		Name  string         `json:"name"`
+		Attrs map[string]any `json:"attrs"`
+	}
+	type Container struct {
+		Items  []Item           `json:"items"`
+		Lookup map[string]*Item `json:"lookup"`
+		Extra  map[string][]int `json:"extra"`
+		Any    any              `json:"any"`
+	}
+
+	item := &Item{Name: "second", Attrs: map[string]any{"tags": []string{"x", "y"}}}
+	data := map[string]any{
+		"container": Container{
+			Items:  []Item{{Name: "first", Attrs: map[string]any{"color": "red"}}, *item},
+			Lookup: map[string]*Item{"second": item, "missing": nil},
+			Extra:  map[string][]int{"nums": {1, 2, 3}},
+			Any:    []any{map[string]any{"deep": Item{Name: "deep"}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		pointer  string
+		expected any
+		wantErr  bool
+	}{
+		{
+			name:     "map -> struct -> slice -> struct -> map",
+			pointer:  "/container/items/0/attrs/color",
+			expected: "red",
+		},
+		{
+			name:     "map -> struct -> map -> pointer -> map -> []string",
+			pointer:  "/container/lookup/second/attrs/tags/1",
+			expected: "y",
+		},
+		{
+			name:     "map -> struct -> map -> []int",
+			pointer:  "/container/extra/nums/2",
+			expected: 3,
+		},
+		{
+			name:     "interface field holding []any",
+			pointer:  "/container/any/0/deep/name",
+			expected: "deep",
+		},
+		{
+			name:    "nil pointer along the path",
+			pointer: "/container/lookup/missing/name",
+			wantErr: true,
+		},
+		{
+			name:    "index into scalar",
+			pointer: "/container/items/0/name/0",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr, err := jsptr.New(tt.pointer)
+			require.NoError(t, err)
+
+			var result any
+			err = ptr.Retrieve(&result, data)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPointerWithNestedCustomSource(t *testing.T) {
+	type Envelope struct {
+		Header  CustomSource  `json:"header"`
+		Headers *CustomSource `json:"headers"`
+	}
+
+	custom := CustomSource{
+		data: map[string]any{"custom_alg": "RS256"},
+	}
+	doc, err := jsptr.ParseJSON([]byte(`{"payload": {"sub": "alice"}}`))
+	require.NoError(t, err)
+
+	data := map[string]any{
+		"envelope": Envelope{Header: custom, Headers: &custom},
+		"list":     []any{&custom},
+		"document": doc,
+	}
+
+	tests := []struct {
+		pointer  string
+		expected string
+	}{
+		{pointer: "/envelope/header/alg", expected: "RS256"},
+		{pointer: "/envelope/headers/alg", expected: "RS256"},
+		{pointer: "/list/0/alg", expected: "RS256"},
+		{pointer: "/document/payload/sub", expected: "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tt.pointer)
+			require.NoError(t, err)
+
+			var result string
+			require.NoError(t, ptr.Retrieve(&result, data))
+			require.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("errors from the nested source are propagated", func(t *testing.T) {
+		ptr, err := jsptr.New("/envelope/header/typ")
+		require.NoError(t, err)
+
+		var result string
+		require.Error(t, ptr.Retrieve(&result, data))
+	})
+}
+
+func TestPointerRetrieveIntoStruct(t *testing.T) {
+	type User struct {
+		ID    int      `json:"id"`
+		Email string   `json:"email"`
+		Tags  []string `json:"tags"`
+	}
+
+	const payload = `{"data": {"user": {"id": 42, "email": "alice@example.com", "tags": ["a", "b"]}, "users": [{"id": 1}, {"id": 2}]}}`
+	expected := User{ID: 42, Email: "alice@example.com", Tags: []string{"a", "b"}}
+
+	targets := map[string]any{
+		"JSON bytes": []byte(payload),
+		"map":        decodeJSON(t, payload),
+	}
+
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			t.Run("struct", func(t *testing.T) {
+				ptr, err := jsptr.New("/data/user")
+				require.NoError(t, err)
+
+				var u User
+				require.NoError(t, ptr.Retrieve(&u, target))
+				require.Equal(t, expected, u)
+			})
+			t.Run("slice of structs", func(t *testing.T) {
+				ptr, err := jsptr.New("/data/users")
+				require.NoError(t, err)
+
+				var users []User
+				require.NoError(t, ptr.Retrieve(&users, target))
+				require.Equal(t, []User{{ID: 1}, {ID: 2}}, users)
+			})
+			t.Run("typed slice", func(t *testing.T) {
+				ptr, err := jsptr.New("/data/user/tags")
+				require.NoError(t, err)
+
+				var tags []string
+				require.NoError(t, ptr.Retrieve(&tags, target))
+				require.Equal(t, []string{"a", "b"}, tags)
+			})
+			t.Run("incompatible value", func(t *testing.T) {
+				ptr, err := jsptr.New("/data/user/email")
+				require.NoError(t, err)
+
+				var u User
+				require.Error(t, ptr.Retrieve(&u, target))
+			})
+		})
+	}
+}
+
+func decodeJSON(t *testing.T, src string) any {
+	t.Helper()
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(src), &v))
+	return v
+}
+
+func TestPointerRetrieveNumberMode(t *testing.T) {
+	const src = `{"int": 9007199254740993, "float": 1.5, "nested": [1, 2.5]}`
+
+	tests := []struct {
+		name     string
+		mode     jsptr.NumberMode
+		pointer  string
+		expected any
+	}{
+		{
+			name:     "default float64",
+			mode:     jsptr.NumberFloat64,
+			pointer:  "/float",
+			expected: 1.5,
+		},
+		{
+			name:     "json.Number keeps original text",
+			mode:     jsptr.NumberJSONNumber,
+			pointer:  "/int",
+			expected: json.Number("9007199254740993"),
+		},
+		{
+			name:     "json.Number in arrays",
+			mode:     jsptr.NumberJSONNumber,
+			pointer:  "/nested",
+			expected: []any{json.Number("1"), json.Number("2.5")},
+		},
+		{
+			name:     "int64 for integral values",
+			mode:     jsptr.NumberInt64,
+			pointer:  "/int",
+			expected: int64(9007199254740993),
+		},
+		{
+			name:     "int64 falls back to float64",
+			mode:     jsptr.NumberInt64,
+			pointer:  "/nested",
+			expected: []any{int64(1), 2.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr, err := jsptr.New(tt.pointer)
+			require.NoError(t, err)
+
+			var result any
+			require.NoError(t, ptr.Retrieve(&result, []byte(src), jsptr.WithNumberMode(tt.mode)))
+			require.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("big.Float preserves all digits", func(t *testing.T) {
+		ptr, err := jsptr.New("/int")
+		require.NoError(t, err)
+
+		var result *big.Float
+		require.NoError(t, ptr.Retrieve(&result, []byte(src), jsptr.WithNumberMode(jsptr.NumberBigFloat)))
+		require.Equal(t, "9007199254740993", result.Text('f', 0))
+	})
+	t.Run("Document", func(t *testing.T) {
+		doc, err := jsptr.ParseJSON([]byte(src))
+		require.NoError(t, err)
+
+		v, err := doc.Get("/int", jsptr.WithNumberMode(jsptr.NumberJSONNumber))
+		require.NoError(t, err)
+		require.Equal(t, json.Number("9007199254740993"), v)
+	})
+}
+
+func TestPointerRetrieveLargeIntegers(t *testing.T) {
+	const src = `{"id": 9007199254740993, "neg": -9007199254740993, "big": 18446744073709551615, "small": 42, "list": [9007199254740993]}`
+
+	tests := []struct {
+		name     string
+		pointer  string
+		dst      func() any
+		expected any
+	}{
+		{
+			name:     "into int64",
+			pointer:  "/id",
+			dst:      func() any { return new(int64) },
+			expected: int64(9007199254740993),
+		},
+		{
+			name:     "into int",
+			pointer:  "/small",
+			dst:      func() any { return new(int) },
+			expected: 42,
+		},
+		{
+			name:     "into uint64",
+			pointer:  "/big",
+			dst:      func() any { return new(uint64) },
+			expected: uint64(18446744073709551615),
+		},
+		{
+			name:     "into any, unsafe integer",
+			pointer:  "/id",
+			dst:      func() any { return new(any) },
+			expected: int64(9007199254740993),
+		},
+		{
+			name:     "into any, negative unsafe integer",
+			pointer:  "/neg",
+			dst:      func() any { return new(any) },
+			expected: int64(-9007199254740993),
+		},
+		{
+			name:     "into any, unsafe unsigned integer",
+			pointer:  "/big",
+			dst:      func() any { return new(any) },
+			expected: uint64(18446744073709551615),
+		},
+		{
+			name:     "into any, safe integer stays float64",
+			pointer:  "/small",
+			dst:      func() any { return new(any) },
+			expected: 42.0,
+		},
+		{
+			name:     "into any, nested in array",
+			pointer:  "/list",
+			dst:      func() any { return new(any) },
+			expected: []any{int64(9007199254740993)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr, err := jsptr.New(tt.pointer)
+			require.NoError(t, err)
+
+			dst := tt.dst()
+			require.NoError(t, ptr.Retrieve(dst, []byte(src)))
+			require.Equal(t, tt.expected, reflect.ValueOf(dst).Elem().Interface())
+		})
+	}
+
+	t.Run("overflow is an error", func(t *testing.T) {
+		ptr, err := jsptr.New("/id")
+		require.NoError(t, err)
+
+		var i32 int32
+		require.Error(t, ptr.Retrieve(&i32, []byte(src)))
+	})
+}
+
+func TestPointerRetrieveNull(t *testing.T) {
+	const src = `{"null": null, "list": [1, null], "obj": {"a": null}}`
+
+	t.Run("into any", func(t *testing.T) {
+		ptr, err := jsptr.New("")
+		require.NoError(t, err)
+
+		var result any
+		require.NoError(t, ptr.Retrieve(&result, []byte(src)))
+		require.Equal(t, map[string]any{
+			"null": nil,
+			"list": []any{1.0, nil},
+			"obj":  map[string]any{"a": nil},
+		}, result)
+	})
+	t.Run("into pointer", func(t *testing.T) {
+		ptr, err := jsptr.New("/null")
+		require.NoError(t, err)
+
+		s := "previous"
+		sp := &s
+		require.NoError(t, ptr.Retrieve(&sp, []byte(src)))
+		require.Nil(t, sp)
+	})
+	t.Run("into slices and maps", func(t *testing.T) {
+		ptr, err := jsptr.New("/null")
+		require.NoError(t, err)
+
+		list := []int{1}
+		require.NoError(t, ptr.Retrieve(&list, []byte(src)))
+		require.Nil(t, list)
+
+		m := map[string]int{"a": 1}
+		require.NoError(t, ptr.Retrieve(&m, map[string]any{"null": nil}))
+		require.Nil(t, m)
+	})
+	t.Run("into types that cannot be null", func(t *testing.T) {
+		ptr, err := jsptr.New("/null")
+		require.NoError(t, err)
+
+		s := "previous"
+		require.ErrorContains(t, ptr.Retrieve(&s, []byte(src)), "cannot assign null to *string")
+		require.Equal(t, "previous", s)
+
+		var n int
+		require.Error(t, ptr.Retrieve(&n, map[string]any{"null": nil}))
+		var sp *string
+		require.Error(t, ptr.Retrieve(&n, map[string]any{"null": sp}))
+	})
+}
+
+func TestPointerNotFound(t *testing.T) {
+	type S struct {
+		Foo string `json:"foo"`
+	}
+	const src = `{"foo": "bar", "list": [1]}`
+
+	targets := map[string]any{
+		"JSON":   []byte(src),
+		"map":    decodeJSON(t, src),
+		"struct": S{Foo: "bar"},
+	}
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			for _, spec := range []string{"/missing", "/list/1"} {
+				ptr, err := jsptr.New(spec)
+				require.NoError(t, err)
+
+				var v any
+				require.ErrorIs(t, ptr.Retrieve(&v, target), jsptr.ErrNotFound, spec)
+			}
+
+			ptr, err := jsptr.New("/foo/bar")
+			require.NoError(t, err)
+			var v any
+			err = ptr.Retrieve(&v, target)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, jsptr.ErrNotFound, "indexing into a scalar is not a missing location")
+		})
+	}
+}
+
+func TestPointerRetrieveCaseInsensitiveFields(t *testing.T) {
+	type S struct {
+		Num   int
+		Name  string `json:"name"`
+		NAME  string `json:"NAME"`
+		Inner struct {
+			Value string
+		}
+	}
+
+	data := S{Num: 42, Name: "lower", NAME: "upper"}
+	data.Inner.Value = "nested"
+
+	testcases := []struct {
+		Pointer string
+		Want    any
+	}{
+		{Pointer: "/num", Want: 42},
+		{Pointer: "/NUM", Want: 42},
+		{Pointer: "/name", Want: "lower"},
+		{Pointer: "/NAME", Want: "upper"},
+		{Pointer: "/Name", Want: "lower"},
+		{Pointer: "/inner/value", Want: "nested"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			require.NoError(t, ptr.Retrieve(&v, data, jsptr.WithCaseInsensitiveFields(true)))
+			require.Equal(t, tc.Want, v)
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		ptr, err := jsptr.New("/num")
+		require.NoError(t, err)
+
+		var v any
+		require.ErrorIs(t, ptr.Retrieve(&v, data), jsptr.ErrNotFound)
+	})
+}
+
+func TestPointerRetrieveWithTagName(t *testing.T) {
+	type Server struct {
+		ListenAddr string `yaml:"listen_addr" json:"listenAddr"`
+		Debug      bool   `yaml:"-"`
+		Timeout    int
+	}
+	type Config struct {
+		Server Server `yaml:"server,omitempty"`
+	}
+
+	data := Config{Server: Server{ListenAddr: ":8080", Debug: true, Timeout: 30}}
+
+	testcases := []struct {
+		Name    string
+		Pointer string
+		Options []jsptr.RetrieveOption
+		Want    any
+		Error   bool
+	}{
+		{Name: "yaml tag", Pointer: "/server/listen_addr", Options: []jsptr.RetrieveOption{jsptr.WithTagName("yaml")}, Want: ":8080"},
+		{Name: "untagged field", Pointer: "/server/Timeout", Options: []jsptr.RetrieveOption{jsptr.WithTagName("yaml")}, Want: 30},
+		{Name: "ignored field", Pointer: "/server/Debug", Options: []jsptr.RetrieveOption{jsptr.WithTagName("yaml")}, Error: true},
+		{Name: "json tag not consulted", Pointer: "/server/listenAddr", Options: []jsptr.RetrieveOption{jsptr.WithTagName("yaml")}, Error: true},
+		{Name: "default json tag", Pointer: "/Server/listenAddr", Want: ":8080"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			err = ptr.Retrieve(&v, data, tc.Options...)
+			if tc.Error {
+				require.ErrorIs(t, err, jsptr.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Want, v)
+		})
+	}
+}
+
+func TestPointerRetrieveQuotedFields(t *testing.T) {
+	type Record struct {
+		ID      int64   `json:"id,string"`
+		Enabled bool    `json:"enabled,string"`
+		Label   string  `json:"label,string"`
+		Count   int     `json:"count"`
+		Tags    []int   `json:"tags,string"`
+		Score   float64 `json:"score,omitempty,string"`
+	}
+
+	data := Record{ID: 1234, Enabled: true, Label: "abc", Count: 7, Tags: []int{1}, Score: 1.5}
+	buf, err := json.Marshal(data)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		Pointer string
+		Want    any
+	}{
+		{Pointer: "/id", Want: "1234"},
+		{Pointer: "/enabled", Want: "true"},
+		{Pointer: "/label", Want: `"abc"`},
+		{Pointer: "/score", Want: "1.5"},
+		// Fields that are not tagged, or that the tag option
+		// does not apply to, are presented as is
+		{Pointer: "/count", Want: 7},
+		{Pointer: "/tags", Want: []int{1}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			require.NoError(t, ptr.Retrieve(&v, data, jsptr.WithQuotedFields(true)))
+			require.Equal(t, tc.Want, v)
+
+			if s, ok := tc.Want.(string); ok {
+				// Same value as retrieving from the JSON encoding
+				require.NoError(t, ptr.Retrieve(&v, buf, jsptr.WithQuotedFields(true)))
+				require.Equal(t, s, v)
+			}
+		})
+	}
+
+	t.Run("quoted values into typed destinations", func(t *testing.T) {
+		for name, target := range map[string]any{"JSON": buf, "struct": data} {
+			t.Run(name, func(t *testing.T) {
+				ptr, err := jsptr.New("/id")
+				require.NoError(t, err)
+				var id int64
+				require.NoError(t, ptr.Retrieve(&id, target, jsptr.WithQuotedFields(true)))
+				require.Equal(t, int64(1234), id)
+
+				ptr, err = jsptr.New("/enabled")
+				require.NoError(t, err)
+				var enabled bool
+				require.NoError(t, ptr.Retrieve(&enabled, target, jsptr.WithQuotedFields(true)))
+				require.True(t, enabled)
+
+				ptr, err = jsptr.New("/label")
+				require.NoError(t, err)
+				var label string
+				require.NoError(t, ptr.Retrieve(&label, target, jsptr.WithQuotedFields(true)))
+				require.Equal(t, `"abc"`, label)
+				require.Error(t, ptr.Retrieve(&id, target, jsptr.WithQuotedFields(true)))
+			})
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		ptr, err := jsptr.New("/id")
+		require.NoError(t, err)
+
+		var v any
+		require.NoError(t, ptr.Retrieve(&v, data))
+		require.Equal(t, int64(1234), v)
+
+		var id int64
+		require.Error(t, ptr.Retrieve(&id, buf))
+	})
+}
+
+// money only exposes its structure through MarshalJSON
+type money struct {
+	units    int64
+	currency string
+}
+
+func (m money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"units": m.units, "currency": m.currency})
+}
+
+func TestPointerRetrieveThroughMarshaler(t *testing.T) {
+	type Order struct {
+		Price   money            `json:"price"`
+		Items   []money          `json:"items"`
+		Raw     json.RawMessage  `json:"raw"`
+		ByName  map[string]money `json:"by_name"`
+		Missing *money           `json:"missing"`
+	}
+
+	price := money{units: 1999, currency: "USD"}
+	data := Order{
+		Price:  price,
+		Items:  []money{{units: 5, currency: "JPY"}},
+		Raw:    json.RawMessage(`{"nested": [true]}`),
+		ByName: map[string]money{"fee": {units: 1, currency: "EUR"}},
+	}
+
+	testcases := []struct {
+		Pointer string
+		Want    any
+		Error   bool
+	}{
+		{Pointer: "/price", Want: price},
+		{Pointer: "/price/currency", Want: "USD"},
+		{Pointer: "/price/units", Want: 1999.0},
+		{Pointer: "/items/0/currency", Want: "JPY"},
+		{Pointer: "/raw/nested/0", Want: true},
+		{Pointer: "/by_name/fee/units", Want: 1.0},
+		{Pointer: "/price/missing", Error: true},
+		{Pointer: "/missing/units", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			err = ptr.Retrieve(&v, data)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Want, v)
+		})
+	}
+
+	t.Run("walk", func(t *testing.T) {
+		require.Equal(t, []walkEntry{
+			{Ptr: "/price/currency", Value: "USD"},
+			{Ptr: "/price/units", Value: 1999.0},
+		}, collectWalk(t, map[string]any{"price": price}))
+	})
+}
+
+func TestPointerRetrieveEmbeddedFields(t *testing.T) {
+	type Base struct {
+		ID     string `json:"id"`
+		Name   string
+		Shared string
+	}
+	type Other struct {
+		Name   string
+		Shared string `json:"Shared"`
+		Deep   string
+	}
+	type Meta struct {
+		Deep string
+	}
+	type Audit struct {
+		Meta
+		Created string `json:"created"`
+	}
+	type wrapper struct {
+		Hidden string
+	}
+	type Named struct {
+		Value string `json:"value"`
+	}
+	type Resource struct {
+		Base
+		Other
+		*Audit
+		wrapper
+		Named `json:"named"`
+		ID    string `json:"id"`
+	}
+
+	data := Resource{
+		Base:    Base{ID: "base", Name: "base", Shared: "base"},
+		Other:   Other{Name: "other", Shared: "other", Deep: "other"},
+		Audit:   &Audit{Meta: Meta{Deep: "audit"}, Created: "yesterday"},
+		wrapper: wrapper{Hidden: "promoted"},
+		Named:   Named{Value: "v"},
+		ID:      "outer",
+	}
+
+	testcases := []struct {
+		Name    string
+		Pointer string
+		Want    any
+		Error   bool
+	}{
+		{Name: "shallower field wins", Pointer: "/id", Want: "outer"},
+		{Name: "tagged field wins at the same depth", Pointer: "/Shared", Want: "other"},
+		{Name: "conflicting fields are dropped", Pointer: "/Name", Error: true},
+		{Name: "embedded pointer fields are promoted", Pointer: "/created", Want: "yesterday"},
+		{Name: "shallower promoted field wins", Pointer: "/Deep", Want: "other"},
+		{Name: "unexported embedded struct fields are promoted", Pointer: "/Hidden", Want: "promoted"},
+		{Name: "embedded struct with a tag name is not promoted", Pointer: "/named/value", Want: "v"},
+		{Name: "fields of named embedded struct are not promoted", Pointer: "/value", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			err = ptr.Retrieve(&v, data)
+			if tc.Error {
+				require.ErrorIs(t, err, jsptr.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Want, v)
+		})
+	}
+
+	t.Run("same members as the marshaled JSON", func(t *testing.T) {
+		buf, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		fromJSON, err := jsptr.Flatten(buf)
+		require.NoError(t, err)
+		fromStruct, err := jsptr.Flatten(data)
+		require.NoError(t, err)
+		require.Equal(t, fromJSON, fromStruct)
+	})
+}
+
+func TestPointerRetrieveThroughNilPointers(t *testing.T) {
+	type TLS struct {
+		Cert string `json:"cert"`
+	}
+	type Base struct {
+		Region string `json:"region"`
+	}
+	type Config struct {
+		*Base
+		TLS   *TLS            `json:"tls"`
+		Named map[string]*TLS `json:"named"`
+	}
+
+	data := &Config{Named: map[string]*TLS{"none": nil}}
+
+	for _, spec := range []string{"/tls/cert", "/region", "/named/none/cert"} {
+		t.Run(spec, func(t *testing.T) {
+			ptr, err := jsptr.New(spec)
+			require.NoError(t, err)
+
+			var v string
+			require.ErrorIs(t, ptr.Retrieve(&v, data), jsptr.ErrNotFound)
+
+			found, err := ptr.Lookup(&v, data)
+			require.NoError(t, err)
+			require.False(t, found)
+		})
+	}
+
+	t.Run("nil pointer itself", func(t *testing.T) {
+		ptr, err := jsptr.New("/tls")
+		require.NoError(t, err)
+
+		v := &TLS{}
+		require.NoError(t, ptr.Retrieve(&v, data))
+		require.Nil(t, v)
+	})
+}
+
+// temperature implements json.Marshaler on its pointer type
+type temperature struct {
+	celsius float64
+}
+
+func (t *temperature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"value": t.celsius, "unit": "C"})
+}
+
+// idSet cannot be navigated as a map, as its keys are not strings
+type idSet map[int]struct{}
+
+func (s idSet) MarshalJSON() ([]byte, error) {
+	ids := make([]int, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return json.Marshal(ids)
+}
+
+func TestPointerRetrieveFromMarshaler(t *testing.T) {
+	testcases := []struct {
+		Name    string
+		Target  any
+		Pointer string
+		Want    any
+	}{
+		{Name: "pointer receiver", Target: &temperature{celsius: 21.5}, Pointer: "/value", Want: 21.5},
+		{Name: "non-string-keyed map", Target: idSet{3: {}, 1: {}}, Pointer: "/1", Want: 3.0},
+		{Name: "root", Target: idSet{2: {}}, Pointer: "", Want: []any{2.0}},
+		{Name: "raw message", Target: json.RawMessage(`{"a": ["b"]}`), Pointer: "/a/0", Want: "b"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			require.NoError(t, ptr.Retrieve(&v, tc.Target))
+			require.Equal(t, tc.Want, v)
+		})
+	}
+
+	t.Run("typed destination", func(t *testing.T) {
+		ptr, err := jsptr.New("")
+		require.NoError(t, err)
+
+		var v struct {
+			Value float64 `json:"value"`
+			Unit  string  `json:"unit"`
+		}
+		require.NoError(t, ptr.Retrieve(&v, &temperature{celsius: -3}))
+		require.Equal(t, -3.0, v.Value)
+		require.Equal(t, "C", v.Unit)
+	})
+}
+
+func TestPointerTokens(t *testing.T) {
+	ptr, err := jsptr.New("/a~1b/m~0n/0")
+	require.NoError(t, err)
+
+	tokens := ptr.Tokens()
+	require.Equal(t, []string{"a/b", "m~n", "0"}, tokens)
+
+	// Modifying the returned tokens does not affect the pointer
+	tokens[0] = "x"
+	require.Equal(t, []string{"a/b", "m~n", "0"}, ptr.Tokens())
+
+	root, err := jsptr.New("")
+	require.NoError(t, err)
+	require.Empty(t, root.Tokens())
+}
+
+func TestPointerRetrieveFromRawMessages(t *testing.T) {
+	target := map[string]json.RawMessage{
+		"user":    json.RawMessage(`{"name": "alice", "tags": ["a", "b"]}`),
+		"count":   json.RawMessage(`3`),
+		"empty":   nil,
+		"invalid": json.RawMessage(`{not json`),
+	}
+
+	testcases := []struct {
+		Pointer string
+		Want    any
+		Error   bool
+	}{
+		{Pointer: "/user/name", Want: "alice"},
+		{Pointer: "/user/tags/1", Want: "b"},
+		{Pointer: "/user", Want: map[string]any{"name": "alice", "tags": []any{"a", "b"}}},
+		{Pointer: "/count", Want: 3.0},
+		{Pointer: "/empty", Want: nil},
+		{Pointer: "/invalid", Error: true},
+		{Pointer: "/user/email", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			err = ptr.Retrieve(&v, target)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Want, v)
+		})
+	}
+
+	t.Run("typed destinations", func(t *testing.T) {
+		ptr, err := jsptr.New("/user")
+		require.NoError(t, err)
+
+		var user struct {
+			Name string   `json:"name"`
+			Tags []string `json:"tags"`
+		}
+		require.NoError(t, ptr.Retrieve(&user, target))
+		require.Equal(t, "alice", user.Name)
+		require.Equal(t, []string{"a", "b"}, user.Tags)
+
+		ptr, err = jsptr.New("/user/tags")
+		require.NoError(t, err)
+
+		var raw json.RawMessage
+		require.NoError(t, ptr.Retrieve(&raw, target))
+		require.Equal(t, `["a", "b"]`, string(raw))
+
+		ptr, err = jsptr.New("/count")
+		require.NoError(t, err)
+
+		var count int
+		require.NoError(t, ptr.Retrieve(&count, target))
+		require.Equal(t, 3, count)
+	})
+
+	t.Run("nested in structs", func(t *testing.T) {
+		type Envelope struct {
+			Kind    string          `json:"kind"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		ptr, err := jsptr.New("/payload/id")
+		require.NoError(t, err)
+
+		var id string
+		require.NoError(t, ptr.Retrieve(&id, Envelope{Kind: "event", Payload: json.RawMessage(`{"id": "x1"}`)}))
+		require.Equal(t, "x1", id)
+	})
+}
+
+type textLevel int
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func TestPointerRetrieveTextUnmarshaler(t *testing.T) {
+	const src = `{"created": "2024-01-02T03:04:05Z", "addr": "10.0.0.1", "level": "info", "bad": "nope"}`
+
+	for name, target := range map[string]any{"JSON": []byte(src), "map": decodeJSON(t, src)} {
+		t.Run(name, func(t *testing.T) {
+			var created time.Time
+			require.NoError(t, jsptr.MustNew("/created").Retrieve(&created, target))
+			require.True(t, created.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+			var addr netip.Addr
+			require.NoError(t, jsptr.MustNew("/addr").Retrieve(&addr, target))
+			require.Equal(t, netip.MustParseAddr("10.0.0.1"), addr)
+
+			var level textLevel
+			require.NoError(t, jsptr.MustNew("/level").Retrieve(&level, target))
+			require.Equal(t, textLevel(1), level)
+
+			require.Error(t, jsptr.MustNew("/bad").Retrieve(&level, target))
+			require.Error(t, jsptr.MustNew("/bad").Retrieve(&created, target))
+		})
+	}
+}
+
+func TestPointerRetrieveMemberConversionErrors(t *testing.T) {
+	errBad := errors.New("bad value")
+	assigner := jsptr.AssignerFunc(func(dst, value any) error {
+		if value == "bad" {
+			return errBad
+		}
+		return jsptr.DefaultAssigner().Assign(dst, value)
+	})
+
+	const src = `{"a": {"ok": 1, "nested": {"x": "bad"}, "last": 2}}`
+	for _, ordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ordered=%t", ordered), func(t *testing.T) {
+			var v any
+			err := jsptr.MustNew("/a").Retrieve(&v, []byte(src), jsptr.WithAssigner(assigner), jsptr.WithOrderedObjects(ordered))
+			require.ErrorIs(t, err, errBad)
+			require.ErrorContains(t, err, "member 'nested'")
+			require.Nil(t, v, "destination must not be partially populated")
+		})
+	}
+}
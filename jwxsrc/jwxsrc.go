@@ -0,0 +1,109 @@
+// Package jwxsrc provides a jsptr.Source implementation for the tokens,
+// keys and headers of github.com/lestrrat-go/jwx/v3, so that JSON pointers
+// can be resolved against them without marshaling them to JSON first.
+package jwxsrc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// Fields is the accessor interface that is shared by jwt.Token, jwk.Key,
+// jws.Headers and jwe.Headers
+type Fields interface {
+	Get(name string, dst any) error
+	Has(name string) bool
+	Keys() []string
+}
+
+// Source is a jsptr.Source backed by a jwt.Token, a jwk.Key, or JWS or JWE
+// headers.
+//
+// The first token of a pointer names a field, which may be a standard
+// field such as "exp" or "kid", or a private claim or parameter. Values
+// are retrieved in the form returned by the underlying Get method, so
+// for example "exp" is a time.Time and "aud" a []string. The remaining
+// tokens are resolved against the value of the field, which allows
+// private claims holding JSON objects and arrays to be traversed. Fields
+// holding other jwx values, such as the "jwk" header of a JWS message,
+// are traversed using a Source of their own.
+//
+// The empty pointer refers to all fields, as a map[string]any.
+type Source struct {
+	fields Fields
+}
+
+// New creates a new Source for the given token, key or headers
+func New(fields Fields) *Source {
+	return &Source{fields: fields}
+}
+
+// RetrieveJSONPointer retrieves the value at the location specified by
+// ptrspec, and assigns it to dst
+func (s *Source) RetrieveJSONPointer(dst any, ptrspec string) error {
+	ptr, err := jsptr.New(ptrspec)
+	if err != nil {
+		return err
+	}
+	tokens := ptr.Tokens()
+	if len(tokens) == 0 {
+		all, err := s.all()
+		if err != nil {
+			return err
+		}
+		return jsptr.Assign(dst, all)
+	}
+
+	name := tokens[0]
+	value, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 1 {
+		return jsptr.Assign(dst, value)
+	}
+
+	// The rest of the pointer follows the first token, and is already
+	// escaped
+	rest := ptrspec[strings.IndexByte(ptrspec[1:], '/')+1:]
+	if nested, ok := value.(Fields); ok {
+		return New(nested).RetrieveJSONPointer(dst, rest)
+	}
+	switch value.(type) {
+	case string, []byte:
+		// Retrieve would treat these as JSON documents
+		return fmt.Errorf("cannot index into %T value of field '%s' with '%s'", value, name, rest)
+	}
+	if err := jsptr.MustNew(rest).Retrieve(dst, value); err != nil {
+		return fmt.Errorf("failed to retrieve '%s' from field '%s': %w", rest, name, err)
+	}
+	return nil
+}
+
+// get returns the value of the field name
+func (s *Source) get(name string) (any, error) {
+	if !s.fields.Has(name) {
+		return nil, fmt.Errorf("field '%s' not found: %w", name, jsptr.ErrNotFound)
+	}
+	var value any
+	if err := s.fields.Get(name, &value); err != nil {
+		return nil, fmt.Errorf("failed to get field '%s': %w", name, err)
+	}
+	return value, nil
+}
+
+// all returns the values of all fields
+func (s *Source) all() (map[string]any, error) {
+	keys := s.fields.Keys()
+	m := make(map[string]any, len(keys))
+	for _, key := range keys {
+		value, err := s.get(key)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,100 @@
+package jwxsrc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/lestrrat-go/jsptr/jwxsrc"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwe"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ jwxsrc.Fields = jwt.New()
+	_ jwxsrc.Fields = jws.NewHeaders()
+	_ jwxsrc.Fields = jwe.NewHeaders()
+)
+
+func TestSource(t *testing.T) {
+	exp := time.Unix(1700000000, 0).UTC()
+	token, err := jwt.NewBuilder().
+		Subject("alice").
+		Audience([]string{"api", "web"}).
+		Expiration(exp).
+		Claim("https://example.com/claims", map[string]any{
+			"roles": []any{"admin", "dev"},
+			"a/b":   "slash",
+		}).
+		Build()
+	require.NoError(t, err)
+
+	key, err := jwk.Import([]byte("secret"))
+	require.NoError(t, err)
+	require.NoError(t, key.Set(jwk.KeyIDKey, "key-1"))
+
+	jwsHeaders := jws.NewHeaders()
+	require.NoError(t, jwsHeaders.Set(jws.KeyIDKey, "key-1"))
+	require.NoError(t, jwsHeaders.Set(jws.JWKKey, key))
+
+	jweHeaders := jwe.NewHeaders()
+	require.NoError(t, jweHeaders.Set(jwe.ContentEncryptionKey, jwa.A256GCM()))
+
+	testcases := []struct {
+		Name     string
+		Fields   jwxsrc.Fields
+		Spec     string
+		Expected any
+		Error    string
+	}{
+		{Name: "standard claim", Fields: token, Spec: "/sub", Expected: "alice"},
+		{Name: "time claim", Fields: token, Spec: "/exp", Expected: exp},
+		{Name: "audience", Fields: token, Spec: "/aud/1", Expected: "web"},
+		{Name: "private claim", Fields: token, Spec: "/https:~1~1example.com~1claims/roles/0", Expected: "admin"},
+		{Name: "escaped member", Fields: token, Spec: "/https:~1~1example.com~1claims/a~1b", Expected: "slash"},
+		{Name: "missing claim", Fields: token, Spec: "/nbf", Error: "field 'nbf' not found"},
+		{Name: "missing member", Fields: token, Spec: "/https:~1~1example.com~1claims/roles/5", Error: "out of bounds"},
+		{Name: "key", Fields: key, Spec: "/kid", Expected: "key-1"},
+		{Name: "key material", Fields: key, Spec: "/k", Expected: []byte("secret")},
+		{Name: "JWS header", Fields: jwsHeaders, Spec: "/kid", Expected: "key-1"},
+		{Name: "nested key", Fields: jwsHeaders, Spec: "/jwk/kid", Expected: "key-1"},
+		{Name: "JWE header", Fields: jweHeaders, Spec: "/enc", Expected: jwa.A256GCM()},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var v any
+			err := jsptr.MustNew(tc.Spec).Retrieve(&v, jwxsrc.New(tc.Fields))
+			if tc.Error != "" {
+				require.ErrorContains(t, err, tc.Error)
+				require.ErrorIs(t, err, jsptr.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, v)
+		})
+	}
+
+	t.Run("Typed destinations", func(t *testing.T) {
+		var roles []string
+		require.NoError(t, jsptr.MustNew("/https:~1~1example.com~1claims/roles").Retrieve(&roles, jwxsrc.New(token)))
+		require.Equal(t, []string{"admin", "dev"}, roles)
+	})
+	t.Run("Scalar fields", func(t *testing.T) {
+		var v any
+		err := jsptr.MustNew("/sub/0").Retrieve(&v, jwxsrc.New(token))
+		require.ErrorContains(t, err, "cannot index into string value of field 'sub'")
+		err = jsptr.MustNew("/k/0").Retrieve(&v, jwxsrc.New(key))
+		require.ErrorContains(t, err, "cannot index into []uint8 value of field 'k'")
+	})
+	t.Run("Empty pointer", func(t *testing.T) {
+		var v map[string]any
+		require.NoError(t, jsptr.MustNew("").Retrieve(&v, jwxsrc.New(jwsHeaders)))
+		require.Equal(t, "key-1", v["kid"])
+		require.Contains(t, v, "jwk")
+	})
+}
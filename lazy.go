@@ -0,0 +1,97 @@
+package jsptr
+
+import (
+	"bytes"
+	"sync"
+)
+
+// LazyValue is a JSON object or array that is only converted to Go values
+// once it is accessed. It is assigned to *any destinations in place of
+// map[string]any and []any values when WithLazyValues is specified.
+//
+// LazyValue implements the Source interface, so it can be passed as the
+// target of (*Pointer).Retrieve, in which case only the value that is
+// retrieved is converted. It also implements json.Marshaler, so that it
+// can be encoded as part of a larger value.
+type LazyValue struct {
+	data []byte
+	// duplicateKeys is the policy that data was read with
+	duplicateKeys DuplicateKeyPolicy
+	once          sync.Once
+	doc           *Document
+	err           error
+}
+
+// document parses the value the first time it is needed
+func (v *LazyValue) document() (*Document, error) {
+	v.once.Do(func() {
+		v.doc, v.err = ParseJSON(v.data, WithDuplicateKeys(v.duplicateKeys))
+	})
+	return v.doc, v.err
+}
+
+// Bytes returns the JSON encoded value
+func (v *LazyValue) Bytes() []byte {
+	return v.data
+}
+
+// Retrieve retrieves the value at the location specified by the JSON
+// pointer `spec`, relative to the value, and assigns it to `dst`. The
+// value is parsed the first time it is accessed, and errors in its
+// syntax are only reported then.
+func (v *LazyValue) Retrieve(dst any, spec string, options ...RetrieveOption) error {
+	doc, err := v.document()
+	if err != nil {
+		return err
+	}
+	return doc.Retrieve(dst, spec, options...)
+}
+
+// Value converts the entire value into generic Go values, the same way
+// it would have been converted without WithLazyValues
+func (v *LazyValue) Value(options ...RetrieveOption) (any, error) {
+	var result any
+	if err := v.Retrieve(&result, "", options...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (v *LazyValue) RetrieveJSONPointer(dst any, ptrspec string) error {
+	return v.Retrieve(dst, ptrspec)
+}
+
+func (v *LazyValue) retrieveTokens(dst any, tokens []string, cfg *retrieveConfig) error {
+	doc, err := v.document()
+	if err != nil {
+		return err
+	}
+	return doc.retrieveTokens(dst, tokens, cfg)
+}
+
+func (v *LazyValue) MarshalJSON() ([]byte, error) {
+	return v.data, nil
+}
+
+// retrieveLazy assigns the value at the location specified by tokens in
+// data to dst as a *LazyValue, if dst is an *any and the value is an
+// object or an array. The document is only scanned up to the end of the
+// value. It reports whether the retrieval was handled
+func retrieveLazy(dst any, data []byte, tokens []string, cfg *retrieveConfig) (bool, error) {
+	ap, ok := dst.(*any)
+	if !ok {
+		return false, nil
+	}
+
+	raw, err := locateRaw(data, tokens, cfg)
+	if err != nil {
+		return true, err
+	}
+	if raw[0] != '{' && raw[0] != '[' {
+		return true, retrieveFromJSON(dst, raw, nil, cfg)
+	}
+	// The value outlives the retrieval, so it must not share its memory
+	// with data, which the caller may reuse
+	*ap = &LazyValue{data: bytes.Clone(raw), duplicateKeys: cfg.duplicateKeys}
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyValues(t *testing.T) {
+	const src = `{"users": [{"name": "alice", "age": 30}, {"name": "bob"}], "count": 2}`
+
+	t.Run("containers are assigned lazily", func(t *testing.T) {
+		var v any
+		require.NoError(t, jsptr.MustNew("").Retrieve(&v, []byte(src), jsptr.WithLazyValues(true)))
+		lazy, ok := v.(*jsptr.LazyValue)
+		require.True(t, ok, "expected *jsptr.LazyValue, got %T", v)
+		require.JSONEq(t, src, string(lazy.Bytes()))
+
+		var name string
+		require.NoError(t, lazy.Retrieve(&name, "/users/1/name"))
+		require.Equal(t, "bob", name)
+
+		// LazyValue can be the target of a pointer
+		var age int
+		require.NoError(t, jsptr.MustNew("/users/0/age").Retrieve(&age, lazy))
+		require.Equal(t, 30, age)
+
+		full, err := lazy.Value()
+		require.NoError(t, err)
+		require.Equal(t, decodeJSON(t, src), full)
+
+		buf, err := json.Marshal(map[string]any{"doc": lazy})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"doc": `+src+`}`, string(buf))
+	})
+	t.Run("nested containers", func(t *testing.T) {
+		var v any
+		require.NoError(t, jsptr.MustNew("/users").Retrieve(&v, src, jsptr.WithLazyValues(true)))
+		lazy, ok := v.(*jsptr.LazyValue)
+		require.True(t, ok, "expected *jsptr.LazyValue, got %T", v)
+
+		full, err := lazy.Value()
+		require.NoError(t, err)
+		require.Equal(t, []any{
+			map[string]any{"name": "alice", "age": 30.0},
+			map[string]any{"name": "bob"},
+		}, full)
+	})
+	t.Run("scalars are converted", func(t *testing.T) {
+		var v any
+		require.NoError(t, jsptr.MustNew("/count").Retrieve(&v, []byte(src), jsptr.WithLazyValues(true)))
+		require.Equal(t, 2.0, v)
+	})
+	t.Run("other destinations are not affected", func(t *testing.T) {
+		var m map[string]any
+		require.NoError(t, jsptr.MustNew("/users/0").Retrieve(&m, []byte(src), jsptr.WithLazyValues(true)))
+		require.Equal(t, map[string]any{"name": "alice", "age": 30.0}, m)
+	})
+	t.Run("missing locations", func(t *testing.T) {
+		var v any
+		require.ErrorIs(t, jsptr.MustNew("/nope").Retrieve(&v, []byte(src), jsptr.WithLazyValues(true)), jsptr.ErrNotFound)
+	})
+	t.Run("syntax errors are reported on access", func(t *testing.T) {
+		var v any
+		require.NoError(t, jsptr.MustNew("/a").Retrieve(&v, []byte(`{"a": {"b": tru}}`), jsptr.WithLazyValues(true)))
+		lazy, ok := v.(*jsptr.LazyValue)
+		require.True(t, ok, "expected *jsptr.LazyValue, got %T", v)
+		_, err := lazy.Value()
+		require.Error(t, err)
+	})
+	t.Run("the value does not share memory with the document", func(t *testing.T) {
+		data := []byte(`{"a": [1, 2]}`)
+		var v any
+		require.NoError(t, jsptr.MustNew("/a").Retrieve(&v, data, jsptr.WithLazyValues(true)))
+		copy(data, `{"a": [3, 4]}`)
+
+		full, err := v.(*jsptr.LazyValue).Value()
+		require.NoError(t, err)
+		require.Equal(t, []any{1.0, 2.0}, full)
+	})
+}
@@ -0,0 +1,77 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrLimitExceeded is the error that is returned (possibly wrapped) when
+// a pointer or a document exceeds one of the limits set using
+// WithMaxDepth, WithMaxTokenLength or WithMaxDocumentSize. Use errors.Is
+// to check for it.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// checkPointerLimits checks pathspec against the limits of cfg, before it
+// is split into tokens, so that hostile pointers are rejected cheaply
+func checkPointerLimits(pathspec string, maxDepth, maxTokenLength int) error {
+	if maxDepth > 0 {
+		if depth := strings.Count(pathspec, "/"); depth > maxDepth {
+			return fmt.Errorf("%w: pointer has %d tokens, more than the maximum of %d", ErrLimitExceeded, depth, maxDepth)
+		}
+	}
+	if maxTokenLength > 0 {
+		for token := range strings.SplitSeq(pathspec[1:], "/") {
+			if len(token) > maxTokenLength {
+				return fmt.Errorf("%w: pointer has a token of %d bytes, more than the maximum of %d", ErrLimitExceeded, len(token), maxTokenLength)
+			}
+		}
+	}
+	return nil
+}
+
+// checkDocumentSize returns an error if a document of the given size is
+// larger than allowed by cfg
+func (cfg *retrieveConfig) checkDocumentSize(size int) error {
+	if cfg.maxDocumentSize > 0 && int64(size) > cfg.maxDocumentSize {
+		return errDocumentTooLarge(cfg.maxDocumentSize)
+	}
+	return nil
+}
+
+func errDocumentTooLarge(limit int64) error {
+	return fmt.Errorf("%w: document is larger than the maximum of %d bytes", ErrLimitExceeded, limit)
+}
+
+// limitReader returns a reader that fails once more than the maximum
+// document size has been read from r
+func (cfg *retrieveConfig) limitReader(r io.Reader) io.Reader {
+	if cfg.maxDocumentSize <= 0 {
+		return r
+	}
+	return &sizeLimitedReader{r: r, remaining: cfg.maxDocumentSize, limit: cfg.maxDocumentSize}
+}
+
+type sizeLimitedReader struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, errDocumentTooLarge(r.limit)
+	}
+	// Read one byte more than allowed, to tell documents that are
+	// exactly as large as the limit from larger ones
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return 0, errDocumentTooLarge(r.limit)
+	}
+	return n, err
+}
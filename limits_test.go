@@ -0,0 +1,86 @@
+package jsptr_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerLimits(t *testing.T) {
+	testcases := []struct {
+		Pointer string
+		Options []jsptr.NewOption
+		Error   bool
+	}{
+		{Pointer: "/a/b/c", Options: []jsptr.NewOption{jsptr.WithMaxDepth(3)}},
+		{Pointer: "/a/b/c/d", Options: []jsptr.NewOption{jsptr.WithMaxDepth(3)}, Error: true},
+		{Pointer: "", Options: []jsptr.NewOption{jsptr.WithMaxDepth(1)}},
+		{Pointer: "/abc/de", Options: []jsptr.NewOption{jsptr.WithMaxTokenLength(3)}},
+		{Pointer: "/abc/defg", Options: []jsptr.NewOption{jsptr.WithMaxTokenLength(3)}, Error: true},
+		{Pointer: "/a~1b/c", Options: []jsptr.NewOption{jsptr.WithMaxTokenLength(3)}, Error: true},
+		{Pointer: "/" + strings.Repeat("a/", 1_000_000), Options: []jsptr.NewOption{jsptr.WithMaxDepth(100)}, Error: true},
+		{Pointer: "/" + strings.Repeat("a/", 1_000)},
+	}
+
+	for _, tc := range testcases {
+		name := tc.Pointer
+		if len(name) > 20 {
+			name = name[:20] + "..."
+		}
+		t.Run(name, func(t *testing.T) {
+			_, err := jsptr.New(tc.Pointer, tc.Options...)
+			if tc.Error {
+				require.ErrorIs(t, err, jsptr.ErrLimitExceeded)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithMaxDocumentSize(t *testing.T) {
+	const src = `{"a": 1}`
+	limit := jsptr.WithMaxDocumentSize(int64(len(src)))
+	tooSmall := jsptr.WithMaxDocumentSize(int64(len(src) - 1))
+
+	ptr, err := jsptr.New("/a")
+	require.NoError(t, err)
+
+	targets := map[string]func() any{
+		"bytes":  func() any { return []byte(src) },
+		"string": func() any { return src },
+		"reader": func() any { return strings.NewReader(src) },
+	}
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			var v int
+			require.NoError(t, ptr.Retrieve(&v, target(), limit))
+			require.Equal(t, 1, v)
+			require.ErrorIs(t, ptr.Retrieve(&v, target(), tooSmall), jsptr.ErrLimitExceeded)
+		})
+	}
+
+	t.Run("multiple pointers", func(t *testing.T) {
+		_, err := jsptr.RetrieveMulti(src, []*jsptr.Pointer{ptr}, tooSmall)
+		require.ErrorIs(t, err, jsptr.ErrLimitExceeded)
+	})
+	t.Run("files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "doc.json")
+		require.NoError(t, os.WriteFile(path, []byte(src), 0o600))
+
+		var v int
+		require.NoError(t, jsptr.RetrieveFile(&v, nil, path, "/a", limit))
+		require.ErrorIs(t, jsptr.RetrieveFile(&v, nil, path, "/a", tooSmall), jsptr.ErrLimitExceeded)
+	})
+	t.Run("large readers are not read past the limit", func(t *testing.T) {
+		r := strings.NewReader(`{"a": 1, "padding": "` + strings.Repeat("x", 1<<20) + `"}`)
+
+		var v int
+		require.ErrorIs(t, ptr.Retrieve(&v, r, jsptr.WithMaxDocumentSize(1024)), jsptr.ErrLimitExceeded)
+		require.Greater(t, r.Len(), 1<<19)
+	})
+}
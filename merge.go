@@ -0,0 +1,118 @@
+package jsptr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MergeStrategy specifies how Merge combines a value of the overlay with
+// the value at the same location in the base document
+type MergeStrategy int
+
+const (
+	// MergeDeep merges objects member by member, using the strategies
+	// of their members, and replaces other values. This is the default
+	MergeDeep MergeStrategy = iota
+	// MergeReplace replaces the value of the base document
+	MergeReplace
+	// MergeAppend appends the elements of an array to those of the array
+	// of the base document. Other values are replaced
+	MergeAppend
+	// MergeKeep keeps the value of the base document if there is one,
+	// and only uses the value of the overlay if the location is missing
+	MergeKeep
+)
+
+// Merge merges overlay into base, and returns the resulting document.
+// This is typically used to layer configuration, such as applying an
+// environment specific document on top of a document of defaults.
+//
+// How the values of overlay are combined with those of base is decided
+// for each location by the rules specified using WithMergeRule. Locations
+// that are not matched by any rule use MergeDeep, so by default objects
+// are merged recursively, and arrays and other values in overlay replace
+// those in base. Unlike JSON Merge Patch (RFC 7386), null is a value like
+// any other, and does not remove members.
+//
+// base and overlay may be JSON text as []byte or string, a *Document, or
+// Go values, which are merged through their JSON representation, and are
+// left untouched. The result is made of map[string]any and []any.
+func Merge(base, overlay any, options ...MergeOption) (any, error) {
+	var m merger
+	for _, option := range options {
+		switch option.Ident() {
+		case identMergeRule{}:
+			v := option.Value().(mergeRule)
+			ptr, err := New(v.pattern, WithExtensions(true))
+			if err != nil {
+				return nil, fmt.Errorf("invalid merge rule pattern '%s': %w", v.pattern, err)
+			}
+			m.rules = append(m.rules, compiledMergeRule{segments: ptr.expandSegments(), strategy: v.strategy})
+		}
+	}
+
+	b, err := genericValue(base, defaultRetrieveConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert base document: %w", err)
+	}
+	o, err := genericValue(overlay, defaultRetrieveConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert overlay document: %w", err)
+	}
+	return m.merge(nil, b, true, o), nil
+}
+
+type compiledMergeRule struct {
+	segments []segment
+	strategy MergeStrategy
+}
+
+type merger struct {
+	rules []compiledMergeRule
+}
+
+// strategy returns the strategy used for the location specified by tokens
+func (m *merger) strategy(tokens []string) MergeStrategy {
+	for _, rule := range m.rules {
+		if matchSegments(rule.segments, tokens) {
+			return rule.strategy
+		}
+	}
+	return MergeDeep
+}
+
+// merge merges the value of the overlay at the location specified by
+// tokens with the value of the base document, if exists is true
+func (m *merger) merge(tokens []string, base any, exists bool, overlay any) any {
+	if !exists {
+		return overlay
+	}
+
+	switch m.strategy(tokens) {
+	case MergeReplace:
+		return overlay
+	case MergeKeep:
+		return base
+	case MergeAppend:
+		if b, ok := base.([]any); ok {
+			if o, ok := overlay.([]any); ok {
+				return append(b, o...)
+			}
+		}
+		return overlay
+	}
+
+	b, ok := base.(map[string]any)
+	if !ok {
+		return overlay
+	}
+	o, ok := overlay.(map[string]any)
+	if !ok {
+		return overlay
+	}
+	for key, value := range o {
+		current, exists := b[key]
+		b[key] = m.merge(append(slices.Clip(tokens), key), current, exists, value)
+	}
+	return b
+}
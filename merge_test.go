@@ -0,0 +1,113 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	const defaults = `{
+		"name": "app",
+		"replicas": 1,
+		"labels": {"team": "a", "tier": "web"},
+		"args": ["--verbose"],
+		"env": {"LOG": "info", "DEBUG": "false"},
+		"services": {"db": {"ports": [5432], "host": "localhost"}},
+		"secret": "default"
+	}`
+	const override = `{
+		"replicas": 3,
+		"labels": {"tier": "api"},
+		"args": ["--port=80"],
+		"env": {"LOG": "debug"},
+		"services": {"db": {"ports": [5433]}, "cache": {"ports": [6379]}},
+		"secret": "override",
+		"extra": null
+	}`
+
+	testcases := []struct {
+		Name     string
+		Options  []jsptr.MergeOption
+		Expected string
+	}{
+		{
+			Name: "defaults",
+			Expected: `{
+				"name": "app",
+				"replicas": 3,
+				"labels": {"team": "a", "tier": "api"},
+				"args": ["--port=80"],
+				"env": {"LOG": "debug", "DEBUG": "false"},
+				"services": {"db": {"ports": [5433], "host": "localhost"}, "cache": {"ports": [6379]}},
+				"secret": "override",
+				"extra": null
+			}`,
+		},
+		{
+			Name: "rules",
+			Options: []jsptr.MergeOption{
+				jsptr.WithMergeRule("/env", jsptr.MergeReplace),
+				jsptr.WithMergeRule("/args", jsptr.MergeAppend),
+				jsptr.WithMergeRule("/services/*/ports", jsptr.MergeAppend),
+				jsptr.WithMergeRule("/**/secret", jsptr.MergeKeep),
+			},
+			Expected: `{
+				"name": "app",
+				"replicas": 3,
+				"labels": {"team": "a", "tier": "api"},
+				"args": ["--verbose", "--port=80"],
+				"env": {"LOG": "debug"},
+				"services": {"db": {"ports": [5432, 5433], "host": "localhost"}, "cache": {"ports": [6379]}},
+				"secret": "default",
+				"extra": null
+			}`,
+		},
+		{
+			Name: "first matching rule",
+			Options: []jsptr.MergeOption{
+				jsptr.WithMergeRule("/labels/tier", jsptr.MergeKeep),
+				jsptr.WithMergeRule("/labels/*", jsptr.MergeReplace),
+				jsptr.WithMergeRule("/labels", jsptr.MergeReplace),
+			},
+			Expected: `{
+				"name": "app",
+				"replicas": 3,
+				"labels": {"tier": "api"},
+				"args": ["--port=80"],
+				"env": {"LOG": "debug", "DEBUG": "false"},
+				"services": {"db": {"ports": [5433], "host": "localhost"}, "cache": {"ports": [6379]}},
+				"secret": "override",
+				"extra": null
+			}`,
+		},
+		{
+			Name:     "root",
+			Options:  []jsptr.MergeOption{jsptr.WithMergeRule("", jsptr.MergeKeep)},
+			Expected: defaults,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := jsptr.Merge(defaults, []byte(override), tc.Options...)
+			require.NoError(t, err)
+			require.Equal(t, decodeJSON(t, tc.Expected), result)
+		})
+	}
+
+	t.Run("Go values are left untouched", func(t *testing.T) {
+		base := map[string]any{"a": map[string]any{"b": 1.0}, "c": []any{1.0}}
+		result, err := jsptr.Merge(base, map[string]any{"a": map[string]any{"d": 2.0}, "c": []any{2.0}},
+			jsptr.WithMergeRule("/c", jsptr.MergeAppend))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"a": map[string]any{"b": 1.0, "d": 2.0}, "c": []any{1.0, 2.0}}, result)
+		require.Equal(t, map[string]any{"a": map[string]any{"b": 1.0}, "c": []any{1.0}}, base)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := jsptr.Merge(`{}`, `{`)
+		require.Error(t, err)
+		_, err = jsptr.Merge(`{}`, `{}`, jsptr.WithMergeRule("a", jsptr.MergeKeep))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package jsptr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fastjson"
+)
+
+// Metrics receives measurements of the work done by this package, so that
+// they can be exported to monitoring systems such as Prometheus or
+// OpenTelemetry. See SetMetrics.
+//
+// Methods are called synchronously, possibly from many goroutines at once,
+// so implementations must be safe for concurrent use and return quickly.
+type Metrics interface {
+	// RetrieveDone is called when a retrieval using a Pointer or a
+	// Document completes. err is nil if the retrieval succeeded. Use
+	// ErrorClass to group failures.
+	RetrieveDone(d time.Duration, err error)
+	// ParseDone is called when a JSON document of the given size (in
+	// bytes) has been parsed. err is nil if the document is valid.
+	ParseDone(size int, d time.Duration, err error)
+	// CacheLookup is called when a value is looked up in one of the
+	// caches of the package. cache is the name of the cache, such as
+	// "struct" for the cache of struct field information, or "pointer"
+	// for the cache used by Cached.
+	CacheLookup(cache string, hit bool)
+}
+
+// metrics holds the Metrics set using SetMetrics, if any
+var metrics atomic.Pointer[Metrics]
+
+// SetMetrics sets the Metrics that receive measurements from the whole
+// package. Passing nil disables the collection of measurements, which is
+// the default.
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&m)
+}
+
+func currentMetrics() Metrics {
+	if m := metrics.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+// Error classes returned by ErrorClass
+const (
+	ErrorClassNotFound = "not_found"
+	ErrorClassSyntax   = "syntax"
+	ErrorClassCanceled = "canceled"
+	ErrorClassOther    = "other"
+)
+
+// ErrorClass returns a short, stable name for the kind of failure that
+// err represents, suitable for use as a metric label: ErrorClassNotFound
+// for missing locations, ErrorClassSyntax for malformed JSON,
+// ErrorClassCanceled for canceled or expired contexts, and
+// ErrorClassOther for everything else. It returns an empty string if
+// err is nil.
+func ErrorClass(err error) string {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, errSyntax), errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorClassSyntax
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassCanceled
+	default:
+		return ErrorClassOther
+	}
+}
+
+// errSyntax is matched by the errors returned for malformed JSON
+var errSyntax = errors.New("syntax error")
+
+type syntaxError struct {
+	err error
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("failed to parse JSON: %s", e.err)
+}
+
+func (e *syntaxError) Unwrap() error {
+	return e.err
+}
+
+func (e *syntaxError) Is(target error) bool {
+	return target == errSyntax
+}
+
+// parseJSON parses data using p, and reports the parse to the metrics
+func parseJSON(p *fastjson.Parser, data []byte) (*fastjson.Value, error) {
+	m := currentMetrics()
+	if m == nil {
+		v, err := p.ParseBytes(data)
+		if err != nil {
+			return nil, &syntaxError{err: err}
+		}
+		return v, nil
+	}
+
+	start := time.Now()
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		err = &syntaxError{err: err}
+	}
+	m.ParseDone(len(data), time.Since(start), err)
+	return v, err
+}
+
+// observeCache reports a lookup in the named cache
+func observeCache(cache string, hit bool) {
+	if m := currentMetrics(); m != nil {
+		m.CacheLookup(cache, hit)
+	}
+}
@@ -0,0 +1,103 @@
+package jsptr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMetrics struct {
+	mu         sync.Mutex
+	retrievals map[string]int
+	parses     []int
+	cache      map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{retrievals: make(map[string]int), cache: make(map[string]int)}
+}
+
+func (m *recordingMetrics) RetrieveDone(_ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retrievals[jsptr.ErrorClass(err)]++
+}
+
+func (m *recordingMetrics) ParseDone(size int, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		size = -1
+	}
+	m.parses = append(m.parses, size)
+}
+
+func (m *recordingMetrics) CacheLookup(cache string, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache[fmt.Sprintf("%s:%t", cache, hit)]++
+}
+
+func TestSetMetrics(t *testing.T) {
+	m := newRecordingMetrics()
+	jsptr.SetMetrics(m)
+	defer jsptr.SetMetrics(nil)
+
+	ptr, err := jsptr.New("/name")
+	require.NoError(t, err)
+
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	var v string
+	require.NoError(t, ptr.Retrieve(&v, `{"name": "a"}`))
+	require.Error(t, ptr.Retrieve(&v, `{"id": 1}`))
+	require.Error(t, ptr.Retrieve(&v, `{"name": `))
+	require.NoError(t, ptr.Retrieve(&v, user{Name: "b"}))
+	require.NoError(t, ptr.Retrieve(&v, &user{Name: "c"}))
+
+	doc, err := jsptr.ParseJSON([]byte(`{"name": 1}`))
+	require.NoError(t, err)
+	require.Error(t, doc.Retrieve(&v, "/name"))
+
+	require.Equal(t, map[string]int{"": 3, jsptr.ErrorClassNotFound: 1, jsptr.ErrorClassSyntax: 1, jsptr.ErrorClassOther: 1}, m.retrievals)
+	require.Equal(t, []int{13, 9, -1, 11}, m.parses)
+	require.Equal(t, 1, m.cache["struct:false"])
+	require.Equal(t, 1, m.cache["struct:true"])
+
+	// Measurements stop once the metrics are removed
+	jsptr.SetMetrics(nil)
+	require.NoError(t, ptr.Retrieve(&v, `{"name": "a"}`))
+	require.Len(t, m.parses, 4)
+}
+
+func TestErrorClass(t *testing.T) {
+	ptr, err := jsptr.New("/a/b")
+	require.NoError(t, err)
+
+	var v any
+	testcases := []struct {
+		Err      error
+		Expected string
+	}{
+		{Err: nil, Expected: ""},
+		{Err: ptr.Retrieve(&v, `{"a": {}}`), Expected: jsptr.ErrorClassNotFound},
+		{Err: ptr.Retrieve(&v, `{"a": {]`), Expected: jsptr.ErrorClassSyntax},
+		{Err: ptr.Retrieve(&v, strings.NewReader(`{"a" 1}`)), Expected: jsptr.ErrorClassSyntax},
+		{Err: ptr.Retrieve(&v, strings.NewReader(`{"a": {"b": 1`)), Expected: jsptr.ErrorClassSyntax},
+		{Err: fmt.Errorf("wrapped: %w", context.Canceled), Expected: jsptr.ErrorClassCanceled},
+		{Err: ptr.Retrieve(&v, `{"a": 1}`), Expected: jsptr.ErrorClassOther},
+		{Err: errors.New("boom"), Expected: jsptr.ErrorClassOther},
+	}
+	for i, tc := range testcases {
+		require.Equal(t, tc.Expected, jsptr.ErrorClass(tc.Err), "case %d: %v", i, tc.Err)
+	}
+}
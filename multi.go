@@ -0,0 +1,130 @@
+package jsptr
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/valyala/fastjson"
+)
+
+// Result holds the outcome of evaluating a single pointer using RetrieveMulti
+type Result struct {
+	Value any
+	Err   error
+}
+
+// RetrieveMulti evaluates all of the given pointers against the same target,
+// and returns the results keyed by their patterns. Failure to resolve an
+// individual pointer is reported in the Err field of its Result, while the
+// returned error is reserved for problems with the target itself (e.g.
+// invalid JSON).
+//
+// JSON targets are parsed only once regardless of the number of pointers,
+// which makes this considerably faster than calling Retrieve repeatedly.
+// Pointers are evaluated concurrently against JSON targets if
+// WithParallelism is specified.
+func RetrieveMulti(target any, pointers []*Pointer, options ...RetrieveOption) (map[string]Result, error) {
+	cfg := newRetrieveConfig(options)
+
+	var data []byte
+	switch v := target.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case *Document:
+		if err := cfg.checkParsedDuplicates(v); err != nil {
+			return nil, err
+		}
+		return retrieveMultiJSON(v.src, pointers, cfg), nil
+	default:
+		source, err := createSource(target)
+		if err != nil {
+			return nil, err
+		}
+		return retrieveMulti(source, pointers, cfg), nil
+	}
+	if err := cfg.checkDocumentSize(len(data)); err != nil {
+		return nil, err
+	}
+	data, err := cfg.resolveDuplicates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+
+	parsed, err := parseJSON(p, data)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.parallelism > 1 {
+		prepareJSON(parsed)
+	}
+	return retrieveMultiJSON(jsonSource{data: data, parsed: parsed}, pointers, cfg), nil
+}
+
+// retrieveMultiJSON evaluates pointers against a parsed JSON document,
+// using as many goroutines as allowed by cfg. The document must have been
+// prepared using prepareJSON if more than one goroutine is used
+func retrieveMultiJSON(source jsonSource, pointers []*Pointer, cfg *retrieveConfig) map[string]Result {
+	workers := min(cfg.parallelism, len(pointers))
+	if workers <= 1 {
+		return retrieveMulti(source, pointers, cfg)
+	}
+
+	results := make([]Result, len(pointers))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(pointers) {
+					return
+				}
+				var v any
+				err := source.retrieveTokens(&v, pointers[i].tokens, cfg)
+				results[i] = Result{Value: v, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	m := make(map[string]Result, len(pointers))
+	for i, ptr := range pointers {
+		m[ptr.pattern] = results[i]
+	}
+	return m
+}
+
+// prepareJSON performs the lazy initialization of every value in v, so
+// that v can then be read from multiple goroutines at once. fastjson
+// unescapes keys and strings the first time they are accessed, which
+// would otherwise modify values while they are being read
+func prepareJSON(v *fastjson.Value) {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		obj, _ := v.Object()
+		obj.Visit(func(_ []byte, val *fastjson.Value) {
+			prepareJSON(val)
+		})
+	case fastjson.TypeArray:
+		for _, elem := range v.GetArray() {
+			prepareJSON(elem)
+		}
+	}
+}
+
+func retrieveMulti(source Source, pointers []*Pointer, cfg *retrieveConfig) map[string]Result {
+	results := make(map[string]Result, len(pointers))
+	for _, ptr := range pointers {
+		var v any
+		err := retrieveFromSource(&v, source, ptr.tokens, cfg)
+		results[ptr.pattern] = Result{Value: v, Err: err}
+	}
+	return results
+}
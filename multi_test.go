@@ -0,0 +1,115 @@
+package jsptr_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrieveMulti(t *testing.T) {
+	const src = `{"user": {"name": "alice", "age": 30}, "tags": ["a", "b"]}`
+
+	var pointers []*jsptr.Pointer
+	for _, spec := range []string{"/user/name", "/user/age", "/tags/1", "/missing"} {
+		ptr, err := jsptr.New(spec)
+		require.NoError(t, err)
+		pointers = append(pointers, ptr)
+	}
+
+	targets := map[string]any{
+		"JSON bytes":  []byte(src),
+		"JSON string": src,
+		"map":         decodeJSON(t, src),
+	}
+	doc, err := jsptr.ParseJSON([]byte(src))
+	require.NoError(t, err)
+	targets["Document"] = doc
+
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			results, err := jsptr.RetrieveMulti(target, pointers)
+			require.NoError(t, err)
+			require.Len(t, results, 4)
+
+			require.NoError(t, results["/user/name"].Err)
+			require.Equal(t, "alice", results["/user/name"].Value)
+			require.NoError(t, results["/user/age"].Err)
+			require.Equal(t, 30.0, results["/user/age"].Value)
+			require.NoError(t, results["/tags/1"].Err)
+			require.Equal(t, "b", results["/tags/1"].Value)
+			require.Error(t, results["/missing"].Err)
+		})
+	}
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := jsptr.RetrieveMulti([]byte(`{`), pointers)
+		require.Error(t, err)
+	})
+}
+
+func TestRetrieveMultiParallel(t *testing.T) {
+	// Escaped keys and strings are unescaped lazily by the parser, which
+	// must not race when pointers are evaluated concurrently
+	var sb strings.Builder
+	sb.WriteString(`{`)
+	var pointers []*jsptr.Pointer
+	for i := range 200 {
+		if i > 0 {
+			sb.WriteString(`,`)
+		}
+		fmt.Fprintf(&sb, `"k\u0065y%d": {"v": "valu\u0065%d", "n": %d}`, i, i, i)
+		pointers = append(pointers, jsptr.MustNew(fmt.Sprintf("/key%d/v", i)), jsptr.MustNew(fmt.Sprintf("/key%d/n", i)))
+	}
+	sb.WriteString(`}`)
+	pointers = append(pointers, jsptr.MustNew("/missing"))
+
+	doc, err := jsptr.ParseJSON([]byte(sb.String()))
+	require.NoError(t, err)
+
+	for name, target := range map[string]any{"bytes": []byte(sb.String()), "string": sb.String(), "Document": doc} {
+		t.Run(name, func(t *testing.T) {
+			results, err := jsptr.RetrieveMulti(target, pointers, jsptr.WithParallelism(8))
+			require.NoError(t, err)
+			require.Len(t, results, len(pointers))
+			for i := range 200 {
+				require.Equal(t, jsptr.Result{Value: fmt.Sprintf("value%d", i)}, results[fmt.Sprintf("/key%d/v", i)])
+				require.Equal(t, jsptr.Result{Value: float64(i)}, results[fmt.Sprintf("/key%d/n", i)])
+			}
+			require.ErrorIs(t, results["/missing"].Err, jsptr.ErrNotFound)
+		})
+	}
+
+	t.Run("Shared Document", func(t *testing.T) {
+		// Documents are read concurrently by callers, and not only by the
+		// goroutines of a single call
+		doc, err := jsptr.ParseJSON([]byte(sb.String()))
+		require.NoError(t, err)
+
+		const callers = 4
+		results := make([]map[string]jsptr.Result, callers)
+		values := make([]any, callers)
+		var wg sync.WaitGroup
+		for i := range callers {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				results[i], _ = jsptr.RetrieveMulti(doc, pointers, jsptr.WithParallelism(4))
+			}()
+			go func() {
+				defer wg.Done()
+				values[i], _ = doc.Get(fmt.Sprintf("/key%d/v", i))
+			}()
+		}
+		wg.Wait()
+
+		for i := range callers {
+			require.Len(t, results[i], len(pointers))
+			require.Equal(t, jsptr.Result{Value: "value7"}, results[i]["/key7/v"])
+			require.Equal(t, fmt.Sprintf("value%d", i), values[i])
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package jsptr
+
+// MustNew works like New, but panics if the pointer cannot be created.
+// It is intended for pointers that are declared as package level
+// variables:
+//
+//	var namePtr = jsptr.MustNew("/user/name")
+func MustNew(pathspec string, options ...NewOption) *Pointer {
+	ptr, err := New(pathspec, options...)
+	if err != nil {
+		panic(err)
+	}
+	return ptr
+}
+
+// MustRetrieve works like Retrieve, but panics if the value cannot be
+// retrieved. It is intended for tests and initialization code, where
+// failing to retrieve a value is a programming error.
+func (p *Pointer) MustRetrieve(dst any, target any, options ...RetrieveOption) {
+	if err := p.Retrieve(dst, target, options...); err != nil {
+		panic(err)
+	}
+}
+
+// MustGet retrieves the value at the location specified by the JSON
+// pointer `spec` in target, and returns it as a value of type T. It
+// panics if the pointer is invalid, or if the value cannot be retrieved.
+//
+//	port := jsptr.MustGet[int](config, "/server/port")
+func MustGet[T any](target any, spec string, options ...RetrieveOption) T {
+	var v T
+	MustNew(spec).MustRetrieve(&v, target, options...)
+	return v
+}
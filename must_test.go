@@ -0,0 +1,31 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMust(t *testing.T) {
+	const doc = `{"server": {"host": "localhost", "port": 8080}}`
+
+	t.Run("MustNew", func(t *testing.T) {
+		ptr := jsptr.MustNew("/server/host")
+		require.Equal(t, "/server/host", ptr.Pattern())
+		require.Panics(t, func() { jsptr.MustNew("server") })
+	})
+	t.Run("MustRetrieve", func(t *testing.T) {
+		var host string
+		jsptr.MustNew("/server/host").MustRetrieve(&host, doc)
+		require.Equal(t, "localhost", host)
+		require.Panics(t, func() { jsptr.MustNew("/server/user").MustRetrieve(&host, doc) })
+	})
+	t.Run("MustGet", func(t *testing.T) {
+		require.Equal(t, 8080, jsptr.MustGet[int](doc, "/server/port"))
+		require.Equal(t, "localhost", jsptr.MustGet[string](doc, "/server/host"))
+		require.Equal(t, map[string]any{"host": "localhost", "port": float64(8080)}, jsptr.MustGet[map[string]any](doc, "/server"))
+		require.Panics(t, func() { jsptr.MustGet[int](doc, "/server/host") })
+		require.Panics(t, func() { jsptr.MustGet[int](doc, "server") })
+	})
+}
@@ -0,0 +1,629 @@
+package jsptr
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/valyala/fastjson"
+)
+
+// editMode specifies how the value at the end of a pointer is changed
+type editMode int
+
+const (
+	// editAdd adds a member or inserts an element, as the RFC 6902 "add"
+	// operation does
+	editAdd editMode = iota
+	// editReplace replaces an existing value
+	editReplace
+	// editSet replaces the value if it exists, and adds it otherwise
+	editSet
+	// editRemove removes an existing value
+	editRemove
+)
+
+// Set sets the value at the location specified by the JSON pointer `spec`
+// in doc, and returns the resulting document.
+//
+// If the location exists, its value is replaced. Otherwise, the value is
+// added as a new object member, or appended to an array if the last token
+// is "-" or the length of the array. The parent of the location must
+// exist. Setting the empty pointer replaces the whole document.
+//
+// doc may be a document made of map[string]any, *OrderedMap and []any,
+// such as one produced by encoding/json, in which case its containers are
+// modified in place. Arrays may have to be reallocated to grow, in which
+// case the containers that hold them are updated, but the document itself
+// may be replaced, so the returned document must be used in place of doc.
+// To have the document updated as well, pass a pointer to it as a *any,
+// *map[string]any or *[]any: the pointer is then returned, and the value
+// that it points to is updated. If an error occurs, the changes that
+// were made are rolled back, leaving the document as it was. doc may also
+// be JSON text as []byte or string, in which case a new document of the
+// same type is returned.
+//
+// Values that implement Sink, whether they are doc itself or values found
+// within it, are asked to assign the value at the rest of the pointer.
+//
+// See WithParents for a way to create the parent of the location along
+// with the objects and arrays that lead to it.
+func Set(doc any, spec string, value any, options ...SetOption) (any, error) {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return nil, err
+	}
+	result, err := set(doc, tokens, value, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set '%s': %w", spec, err)
+	}
+	return result, nil
+}
+
+func set(doc any, tokens []string, value any, options []SetOption) (any, error) {
+	parents := setParents(options)
+	return edit(doc, func(ed editor, root any) (any, error) {
+		tokens := tokens
+		if parents {
+			tokens = slices.Clone(tokens)
+			root = ed.makeParents(root, tokens)
+		}
+		return ed.mutate(root, tokens, editSet, value)
+	})
+}
+
+// Delete removes the value at the location specified by the JSON pointer
+// `spec` from doc, and returns the resulting document. Removing an array
+// element shifts the elements that follow it. An error matching
+// ErrNotFound is returned if the location does not exist.
+//
+// The same types of documents as those accepted by Set can be used, and
+// the same caveats apply.
+func Delete(doc any, spec string) (any, error) {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return nil, err
+	}
+	result, err := edit(doc, func(ed editor, root any) (any, error) {
+		return ed.mutate(root, tokens, editRemove, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete '%s': %w", spec, err)
+	}
+	return result, nil
+}
+
+// Move removes the value at the location specified by the JSON pointer
+// `from` in doc, and adds it at the location specified by `to`, as the
+// RFC 6902 "move" operation does. A value cannot be moved into one of its
+// own children. Moving a value to its own location does nothing.
+//
+// The same types of documents as those accepted by Set can be used, and
+// the same caveats apply.
+func Move(doc any, from, to string) (any, error) {
+	fromTokens, err := parseTokens(from)
+	if err != nil {
+		return nil, err
+	}
+	toTokens, err := parseTokens(to)
+	if err != nil {
+		return nil, err
+	}
+	result, err := edit(doc, func(ed editor, root any) (any, error) {
+		return ed.move(root, fromTokens, toTokens)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to move '%s' to '%s': %w", from, to, err)
+	}
+	return result, nil
+}
+
+// Copy adds a copy of the value at the location specified by the JSON
+// pointer `from` in doc at the location specified by `to`, as the
+// RFC 6902 "copy" operation does. Objects and arrays are copied deeply, so
+// that changing one of the locations afterwards does not affect the other.
+//
+// The same types of documents as those accepted by Set can be used, and
+// the same caveats apply.
+func Copy(doc any, from, to string) (any, error) {
+	fromTokens, err := parseTokens(from)
+	if err != nil {
+		return nil, err
+	}
+	toTokens, err := parseTokens(to)
+	if err != nil {
+		return nil, err
+	}
+	result, err := edit(doc, func(ed editor, root any) (any, error) {
+		return ed.copyValue(root, fromTokens, toTokens)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy '%s' to '%s': %w", from, to, err)
+	}
+	return result, nil
+}
+
+// SetCopy is like Set, but leaves doc untouched: the returned document is
+// a copy of doc that shares all of its containers except for those on the
+// path to the location, which are copied before they are changed. This
+// makes it possible to derive new versions of a document while other
+// goroutines keep reading the previous ones.
+//
+// doc may be a document made of map[string]any, *OrderedMap and []any, or
+// JSON text as []byte or string, in which case SetCopy behaves like Set.
+// Note that values shared by both documents must not be modified in place
+// afterwards, as the change would be visible in both.
+func SetCopy(doc any, spec string, value any, options ...SetOption) (any, error) {
+	switch doc.(type) {
+	case []byte, string:
+		return Set(doc, spec, value, options...)
+	}
+
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return nil, err
+	}
+	root := clonePath(doc, tokens)
+	if setParents(options) {
+		root = makeParents(root, tokens)
+	}
+	result, err := mutate(root, tokens, editSet, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set '%s': %w", spec, err)
+	}
+	return result, nil
+}
+
+// setParents reports whether WithParents(true) is among options
+func setParents(options []SetOption) bool {
+	var parents bool
+	for _, option := range options {
+		switch option.Ident() {
+		case identParents{}:
+			parents = option.Value().(bool)
+		}
+	}
+	return parents
+}
+
+// clonePath returns a copy of node in which the containers that lead to
+// the location specified by tokens are shallow copies of the original
+// ones, so that the location can be changed without affecting node.
+// Copying stops at the first token that does not resolve
+func clonePath(node any, tokens []string) any {
+	if len(tokens) == 0 {
+		return node
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	switch v := node.(type) {
+	case map[string]any:
+		c := maps.Clone(v)
+		if child, ok := c[token]; ok && len(rest) > 0 {
+			c[token] = clonePath(child, rest)
+		}
+		return c
+	case *OrderedMap:
+		c := v.clone()
+		if child, ok := c.Get(token); ok && len(rest) > 0 {
+			c.values[token] = clonePath(child, rest)
+		}
+		return c
+	case []any:
+		// Clip so that appending to the copy cannot write to the spare
+		// capacity of the original array
+		c := slices.Clip(slices.Clone(v))
+		if index, err := parseIndex(token, len(c)); err == nil && len(rest) > 0 {
+			c[index] = clonePath(c[index], rest)
+		}
+		return c
+	}
+	return node
+}
+
+// editor applies changes to a document
+type editor struct {
+	// arena is non-nil if the document is JSON text, in which case the
+	// parsed document is changed in place, and new values are allocated
+	// from the arena
+	arena *fastjson.Arena
+	// journal is non-nil if the document is made of Go values, which are
+	// changed in place. It records their contents before they are changed
+	journal *journal
+}
+
+// edit calls fn with the root of doc, and converts the document returned
+// by fn back to the type of doc.
+//
+// JSON text is parsed and edited without being converted to Go values, so
+// that the parts of the document that are not changed are written back
+// as they were, including the order of object members and the exact text
+// of numbers
+func edit(doc any, fn func(ed editor, root any) (any, error)) (any, error) {
+	var data []byte
+	switch v := doc.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case *any:
+		return editInPlace(v, fn)
+	case *map[string]any:
+		return editInPlace(v, fn)
+	case *[]any:
+		return editInPlace(v, fn)
+	default:
+		j := &journal{}
+		result, err := fn(editor{journal: j}, doc)
+		if err != nil {
+			j.rollback()
+			return nil, err
+		}
+		return result, nil
+	}
+
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+	a := arenaPool.Get()
+	defer arenaPool.Put(a)
+
+	root, err := parseJSON(p, data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := fn(editor{arena: a}, root)
+	if err != nil {
+		return nil, err
+	}
+	buf := result.(*fastjson.Value).MarshalTo(nil)
+
+	// fastjson escapes the keys of the objects that it modifies using Go
+	// syntax, which is not valid JSON for some control characters. Such
+	// documents are rare, and are edited as Go values instead
+	if fastjson.ValidateBytes(buf) != nil {
+		if buf, err = editValues(data, fn); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := doc.(string); ok {
+		return string(buf), nil
+	}
+	return buf, nil
+}
+
+// editInPlace edits the document that ptr points to, and updates it with
+// the resulting document, which must be of the same type
+func editInPlace[T any](ptr *T, fn func(ed editor, root any) (any, error)) (any, error) {
+	j := &journal{}
+	result, err := fn(editor{journal: j}, *ptr)
+	if err != nil {
+		j.rollback()
+		return nil, err
+	}
+	v, ok := result.(T)
+	if !ok {
+		j.rollback()
+		return nil, fmt.Errorf("cannot replace document of type %T with %T", *ptr, result)
+	}
+	*ptr = v
+	return ptr, nil
+}
+
+// editValuesConfig is used to materialize JSON text that is edited as Go
+// values. Member order and numbers are preserved, so that unchanged parts
+// of the document are written back as they were
+var editValuesConfig = &retrieveConfig{orderedObjects: true, numberMode: NumberJSONNumber}
+
+// editValues edits the JSON text data as Go values
+func editValues(data []byte, fn func(ed editor, root any) (any, error)) ([]byte, error) {
+	root, err := materializeJSON(data, editValuesConfig)
+	if err != nil {
+		return nil, err
+	}
+	result, err := fn(editor{}, root)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	return buf, nil
+}
+
+func (ed editor) mutate(root any, tokens []string, mode editMode, value any) (any, error) {
+	if ed.arena == nil {
+		ed.record(root, tokens)
+		return mutate(root, tokens, mode, value)
+	}
+
+	var jv *fastjson.Value
+	if mode != editRemove {
+		var err error
+		if jv, err = ed.jsonValue(value); err != nil {
+			return nil, err
+		}
+	}
+	return mutateJSON(root.(*fastjson.Value), tokens, mode, jv)
+}
+
+// record records the containers of root that editing the location
+// specified by tokens may change
+func (ed editor) record(root any, tokens []string) {
+	if ed.journal != nil {
+		ed.journal.record(root, tokens)
+	}
+}
+
+// get returns the value at the location specified by tokens within root
+func (ed editor) get(root any, tokens []string) (any, error) {
+	if ed.arena != nil {
+		return navigateJSON(root.(*fastjson.Value), tokens, defaultRetrieveConfig)
+	}
+
+	v, rest, err := navigate(root, tokens, defaultRetrieveConfig)
+	if err != nil {
+		return nil, err
+	}
+	if rest != nil {
+		return nil, fmt.Errorf("cannot modify values of type %T", v)
+	}
+	return v, nil
+}
+
+func (ed editor) move(root any, from, to []string) (any, error) {
+	if len(to) > len(from) && slices.Equal(to[:len(from)], from) {
+		return nil, fmt.Errorf("cannot move a value into one of its children")
+	}
+	v, err := ed.get(root, from)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(from, to) {
+		return root, nil
+	}
+	if root, err = ed.mutate(root, from, editRemove, nil); err != nil {
+		return nil, err
+	}
+	return ed.put(root, to, editAdd, v)
+}
+
+func (ed editor) copyValue(root any, from, to []string) (any, error) {
+	v, err := ed.get(root, from)
+	if err != nil {
+		return nil, err
+	}
+	if ed.arena != nil {
+		return ed.put(root, to, editAdd, ed.copyJSON(v.(*fastjson.Value)))
+	}
+	return ed.put(root, to, editAdd, deepCopy(v))
+}
+
+// put changes the location specified by tokens as mutate does, using v,
+// which is a value taken from the document being edited
+func (ed editor) put(root any, tokens []string, mode editMode, v any) (any, error) {
+	if ed.arena == nil {
+		ed.record(root, tokens)
+		return mutate(root, tokens, mode, v)
+	}
+	return mutateJSON(root.(*fastjson.Value), tokens, mode, v.(*fastjson.Value))
+}
+
+// deepCopy returns a copy of v that does not share any containers with it
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, val := range v {
+			c[key] = deepCopy(val)
+		}
+		return c
+	case *OrderedMap:
+		c := NewOrderedMap()
+		for _, key := range v.keys {
+			c.Set(key, deepCopy(v.values[key]))
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, val := range v {
+			c[i] = deepCopy(val)
+		}
+		return c
+	}
+	return v
+}
+
+func (ed editor) makeParents(root any, tokens []string) any {
+	if ed.arena == nil {
+		ed.record(root, tokens)
+		return makeParents(root, tokens)
+	}
+	return ed.makeJSONParents(root.(*fastjson.Value), tokens)
+}
+
+// makeParents creates the containers that lead to the location specified
+// by tokens within node, where they are missing or null, and returns the
+// resulting node. The value at the location itself is left to mutate.
+//
+// "-" tokens that lead to new array elements are replaced in tokens by
+// the index of the element, so that mutate can follow them
+func makeParents(node any, tokens []string) any {
+	if len(tokens) == 0 {
+		return node
+	}
+	if node == nil {
+		node = newContainer(tokens[0])
+	}
+	if len(tokens) == 1 {
+		return node
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	switch v := node.(type) {
+	case map[string]any:
+		if v == nil {
+			v = make(map[string]any)
+		}
+		v[token] = makeParents(v[token], rest)
+		return v
+	case *OrderedMap:
+		child, _ := v.Get(token)
+		v.Set(token, makeParents(child, rest))
+		return v
+	case []any:
+		if token == "-" || token == strconv.Itoa(len(v)) {
+			tokens[0] = strconv.Itoa(len(v))
+			return append(v, makeParents(nil, rest))
+		}
+		if index, err := parseIndex(token, len(v)); err == nil {
+			v[index] = makeParents(v[index], rest)
+		}
+		return v
+	}
+	return node
+}
+
+// newContainer returns an empty container that token can be used with,
+// which is an array if token is "-" or an array index, and an object
+// otherwise
+func newContainer(token string) any {
+	if isArrayToken(token) {
+		return []any{}
+	}
+	return map[string]any{}
+}
+
+func isArrayToken(token string) bool {
+	if token == "-" {
+		return true
+	}
+	index, err := strconv.Atoi(token)
+	return err == nil && index >= 0
+}
+
+// mutate changes the value at the location specified by tokens within
+// node, and returns the resulting node. Containers are modified in place
+// where possible, but arrays may need to be reallocated
+func mutate(node any, tokens []string, mode editMode, value any) (any, error) {
+	if sink, ok := node.(Sink); ok && mode == editSet {
+		if err := sink.AssignJSONPointer(value, joinTokens(tokens)); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+
+	if len(tokens) == 0 {
+		if mode == editRemove {
+			return nil, fmt.Errorf("cannot remove the root of a document")
+		}
+		return value, nil
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	switch v := node.(type) {
+	case map[string]any:
+		cur, exists := v[token]
+		if len(rest) > 0 || mode == editRemove || mode == editReplace {
+			if !exists {
+				return nil, errNotFound("property '%s' not found", token)
+			}
+		}
+		if len(rest) > 0 {
+			nv, err := mutate(cur, rest, mode, value)
+			if err != nil {
+				return nil, err
+			}
+			v[token] = nv
+			return v, nil
+		}
+		if mode == editRemove {
+			delete(v, token)
+			return v, nil
+		}
+		if v == nil {
+			v = make(map[string]any)
+		}
+		v[token] = value
+		return v, nil
+	case *OrderedMap:
+		cur, exists := v.Get(token)
+		if len(rest) > 0 || mode == editRemove || mode == editReplace {
+			if !exists {
+				return nil, errNotFound("property '%s' not found", token)
+			}
+		}
+		if len(rest) > 0 {
+			nv, err := mutate(cur, rest, mode, value)
+			if err != nil {
+				return nil, err
+			}
+			v.Set(token, nv)
+			return v, nil
+		}
+		if mode == editRemove {
+			v.Delete(token)
+			return v, nil
+		}
+		v.Set(token, value)
+		return v, nil
+	case []any:
+		if len(rest) > 0 {
+			index, err := parseIndex(token, len(v))
+			if err != nil {
+				return nil, err
+			}
+			nv, err := mutate(v[index], rest, mode, value)
+			if err != nil {
+				return nil, err
+			}
+			v[index] = nv
+			return v, nil
+		}
+		return mutateElement(v, token, mode, value)
+	case nil:
+		return nil, fmt.Errorf("cannot index into null with '%s'", token)
+	}
+
+	switch kind, _ := kindOf(node); kind {
+	case KindString, KindNumber, KindBool:
+		return nil, fmt.Errorf("cannot index into scalar value %T with '%s'", node, token)
+	default:
+		return nil, fmt.Errorf("cannot modify values of type %T", node)
+	}
+}
+
+// mutateElement changes the element of arr specified by token
+func mutateElement(arr []any, token string, mode editMode, value any) ([]any, error) {
+	// "-" refers to the (nonexistent) element after the last one
+	index := len(arr)
+	if token != "-" {
+		var err error
+		if index, err = strconv.Atoi(token); err != nil {
+			return nil, fmt.Errorf("invalid array index '%s'", token)
+		}
+	}
+
+	switch mode {
+	case editAdd:
+		if index < 0 || index > len(arr) {
+			return nil, errNotFound("array index %d out of bounds", index)
+		}
+		return slices.Insert(arr, index, value), nil
+	case editSet:
+		if index == len(arr) {
+			return append(arr, value), nil
+		}
+	}
+
+	if index < 0 || index >= len(arr) {
+		return nil, errNotFound("array index %d out of bounds", index)
+	}
+	if mode == editRemove {
+		return slices.Delete(arr, index, index+1), nil
+	}
+	arr[index] = value
+	return arr, nil
+}
@@ -0,0 +1,270 @@
+package jsptr
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/valyala/fastjson"
+)
+
+// arenaPool holds the arenas that new values are allocated from while
+// editing JSON text
+var arenaPool fastjson.ArenaPool
+
+// mutateJSON changes the value at the location specified by tokens within
+// the parsed JSON value node, in the same way as mutate does for Go values
+func mutateJSON(node *fastjson.Value, tokens []string, mode editMode, value *fastjson.Value) (*fastjson.Value, error) {
+	if len(tokens) == 0 {
+		if mode == editRemove {
+			return nil, fmt.Errorf("cannot remove the root of a document")
+		}
+		return value, nil
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	switch node.Type() {
+	case fastjson.TypeObject:
+		obj, _ := node.Object()
+		cur := obj.Get(token)
+		if len(rest) > 0 || mode == editRemove || mode == editReplace {
+			if cur == nil {
+				return nil, errNotFound("property '%s' not found", token)
+			}
+		}
+		if len(rest) > 0 {
+			nv, err := mutateJSON(cur, rest, mode, value)
+			if err != nil {
+				return nil, err
+			}
+			if nv != cur {
+				obj.Set(token, nv)
+			}
+			return node, nil
+		}
+		if mode == editRemove {
+			obj.Del(token)
+			return node, nil
+		}
+		obj.Set(token, value)
+		return node, nil
+	case fastjson.TypeArray:
+		arr, _ := node.Array()
+		if len(rest) > 0 {
+			index, err := parseIndex(token, len(arr))
+			if err != nil {
+				return nil, err
+			}
+			nv, err := mutateJSON(arr[index], rest, mode, value)
+			if err != nil {
+				return nil, err
+			}
+			arr[index] = nv
+			return node, nil
+		}
+		return mutateJSONElement(node, token, mode, value)
+	case fastjson.TypeNull:
+		return nil, fmt.Errorf("cannot index into null with '%s'", token)
+	default:
+		return nil, fmt.Errorf("cannot index into scalar value %s with '%s'", node.Type(), token)
+	}
+}
+
+// copyJSON returns a copy of the parsed JSON value v that does not share
+// any objects or arrays with it. Other values are never changed in place,
+// so they are shared
+func (ed editor) copyJSON(v *fastjson.Value) *fastjson.Value {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		c := ed.arena.NewObject()
+		obj, _ := v.Object()
+		obj.Visit(func(key []byte, val *fastjson.Value) {
+			c.Set(string(key), ed.copyJSON(val))
+		})
+		return c
+	case fastjson.TypeArray:
+		c := ed.arena.NewArray()
+		arr, _ := v.Array()
+		for i, val := range arr {
+			c.SetArrayItem(i, ed.copyJSON(val))
+		}
+		return c
+	}
+	return v
+}
+
+// makeJSONParents creates the containers that lead to the location
+// specified by tokens within the parsed JSON value node, in the same way
+// as makeParents does for Go values
+func (ed editor) makeJSONParents(node *fastjson.Value, tokens []string) *fastjson.Value {
+	if len(tokens) == 0 {
+		return node
+	}
+	if node == nil || node.Type() == fastjson.TypeNull {
+		if isArrayToken(tokens[0]) {
+			node = ed.arena.NewArray()
+		} else {
+			node = ed.arena.NewObject()
+		}
+	}
+	if len(tokens) == 1 {
+		return node
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	switch node.Type() {
+	case fastjson.TypeObject:
+		obj, _ := node.Object()
+		cur := obj.Get(token)
+		if nv := ed.makeJSONParents(cur, rest); nv != cur {
+			obj.Set(token, nv)
+		}
+	case fastjson.TypeArray:
+		arr, _ := node.Array()
+		if token == "-" || token == strconv.Itoa(len(arr)) {
+			tokens[0] = strconv.Itoa(len(arr))
+			node.SetArrayItem(len(arr), ed.makeJSONParents(nil, rest))
+		} else if index, err := parseIndex(token, len(arr)); err == nil {
+			arr[index] = ed.makeJSONParents(arr[index], rest)
+		}
+	}
+	return node
+}
+
+// mutateJSONElement changes the element of the JSON array node specified
+// by token. The elements of the array are shifted in place, as the slice
+// returned by (*fastjson.Value).Array is the one held by node
+func mutateJSONElement(node *fastjson.Value, token string, mode editMode, value *fastjson.Value) (*fastjson.Value, error) {
+	arr, _ := node.Array()
+	n := len(arr)
+
+	// "-" refers to the (nonexistent) element after the last one
+	index := n
+	if token != "-" {
+		var err error
+		if index, err = strconv.Atoi(token); err != nil {
+			return nil, fmt.Errorf("invalid array index '%s'", token)
+		}
+	}
+
+	switch mode {
+	case editAdd:
+		if index < 0 || index > n {
+			return nil, errNotFound("array index %d out of bounds", index)
+		}
+		// Grow the array by one element, and make room for the new one
+		node.SetArrayItem(n, value)
+		arr, _ = node.Array()
+		copy(arr[index+1:], arr[index:n])
+		arr[index] = value
+		return node, nil
+	case editSet:
+		if index == n {
+			node.SetArrayItem(n, value)
+			return node, nil
+		}
+	}
+
+	if index < 0 || index >= n {
+		return nil, errNotFound("array index %d out of bounds", index)
+	}
+	if mode == editRemove {
+		node.Del(strconv.Itoa(index))
+		return node, nil
+	}
+	arr[index] = value
+	return node, nil
+}
+
+// jsonValue converts the Go value v to a JSON value allocated from the
+// arena of ed. Generic values are converted directly, and other values
+// through their JSON encoding
+func (ed editor) jsonValue(v any) (*fastjson.Value, error) {
+	a := ed.arena
+	switch v := v.(type) {
+	case nil:
+		return a.NewNull(), nil
+	case bool:
+		if v {
+			return a.NewTrue(), nil
+		}
+		return a.NewFalse(), nil
+	case string:
+		// Strings that must be escaped are encoded by encoding/json, as
+		// fastjson escapes them using Go syntax
+		if !strings.ContainsFunc(v, needsEscape) {
+			return a.NewString(v), nil
+		}
+	case int:
+		return a.NewNumberInt(v), nil
+	case int64:
+		return a.NewNumberString(strconv.FormatInt(v, 10)), nil
+	case float64:
+		// Use the same representation as encoding/json
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("failed to encode value: unsupported value %v", v)
+		}
+		buf, _ := json.Marshal(v)
+		return a.NewNumberString(string(buf)), nil
+	case map[string]any:
+		if v == nil {
+			return a.NewNull(), nil
+		}
+		// Members are sorted by key, as encoding/json does
+		obj := a.NewObject()
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			jv, err := ed.jsonValue(v[key])
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key, jv)
+		}
+		return obj, nil
+	case *OrderedMap:
+		if v == nil {
+			return a.NewNull(), nil
+		}
+		obj := a.NewObject()
+		for _, key := range v.Keys() {
+			val, _ := v.Get(key)
+			jv, err := ed.jsonValue(val)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key, jv)
+		}
+		return obj, nil
+	case []any:
+		if v == nil {
+			return a.NewNull(), nil
+		}
+		arr := a.NewArray()
+		for i, elem := range v {
+			jv, err := ed.jsonValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			arr.SetArrayItem(i, jv)
+		}
+		return arr, nil
+	}
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	// The parsed value must outlive the call, so it is parsed using a
+	// parser of its own
+	var p fastjson.Parser
+	return parseJSON(&p, buf)
+}
+
+// needsEscape reports whether r must be escaped in a JSON string
+func needsEscape(r rune) bool {
+	return r < 0x20 || r == '"' || r == '\\' || r == utf8.RuneError
+}
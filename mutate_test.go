@@ -0,0 +1,405 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	const src = `{"b": {"c": 1}, "a": [1, 2]}`
+
+	testcases := []struct {
+		Pointer  string
+		Value    any
+		Expected string
+		Error    bool
+		NotFound bool
+	}{
+		{Pointer: "/b/c", Value: 2, Expected: `{"b":{"c":2},"a":[1,2]}`},
+		{Pointer: "/b/d", Value: "x", Expected: `{"b":{"c":1,"d":"x"},"a":[1,2]}`},
+		{Pointer: "/a/0", Value: nil, Expected: `{"b":{"c":1},"a":[null,2]}`},
+		{Pointer: "/a/2", Value: 3, Expected: `{"b":{"c":1},"a":[1,2,3]}`},
+		{Pointer: "/a/-", Value: map[string]any{"x": true}, Expected: `{"b":{"c":1},"a":[1,2,{"x":true}]}`},
+		{Pointer: "", Value: []int{1}, Expected: `[1]`},
+		{Pointer: "/a/3", Value: 3, Error: true, NotFound: true},
+		{Pointer: "/x/y", Value: 3, Error: true, NotFound: true},
+		{Pointer: "/a/x", Value: 3, Error: true},
+		{Pointer: "/b/c/d", Value: 3, Error: true},
+		{Pointer: "b", Value: 3, Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			result, err := jsptr.Set([]byte(src), tc.Pointer, tc.Value)
+			if tc.Error {
+				require.Error(t, err)
+				if tc.NotFound {
+					require.ErrorIs(t, err, jsptr.ErrNotFound)
+				}
+				return
+			}
+			require.NoError(t, err)
+			// Member order is preserved
+			require.Equal(t, tc.Expected, string(result.([]byte)))
+		})
+	}
+
+	t.Run("generic values", func(t *testing.T) {
+		doc := decodeJSON(t, src)
+		result, err := jsptr.Set(doc, "/b/d", "x")
+		require.NoError(t, err)
+		require.Equal(t, "x", doc.(map[string]any)["b"].(map[string]any)["d"], "maps are modified in place")
+
+		result, err = jsptr.Set(result, "/a/-", 3.0)
+		require.NoError(t, err)
+		require.Equal(t, []any{1.0, 2.0, 3.0}, result.(map[string]any)["a"])
+	})
+	t.Run("ordered maps", func(t *testing.T) {
+		doc := jsptr.NewOrderedMap()
+		doc.Set("z", 1)
+		result, err := jsptr.Set(doc, "/a", 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"z", "a"}, result.(*jsptr.OrderedMap).Keys())
+	})
+	t.Run("strings", func(t *testing.T) {
+		result, err := jsptr.Set(`{"n": 12345678901234567890}`, "/m", 1)
+		require.NoError(t, err)
+		require.Equal(t, `{"n":12345678901234567890,"m":1}`, result)
+	})
+	t.Run("unsupported documents", func(t *testing.T) {
+		_, err := jsptr.Set(struct{ A int }{}, "/A", 1)
+		require.Error(t, err)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	const src = `{"b": {"c": 1}, "a": [1, 2, 3]}`
+
+	testcases := []struct {
+		Pointer  string
+		Expected string
+		Error    bool
+		NotFound bool
+	}{
+		{Pointer: "/b/c", Expected: `{"b":{},"a":[1,2,3]}`},
+		{Pointer: "/b", Expected: `{"a":[1,2,3]}`},
+		{Pointer: "/a/1", Expected: `{"b":{"c":1},"a":[1,3]}`},
+		{Pointer: "/b/d", Error: true, NotFound: true},
+		{Pointer: "/a/3", Error: true, NotFound: true},
+		{Pointer: "/a/-", Error: true, NotFound: true},
+		{Pointer: "", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			result, err := jsptr.Delete([]byte(src), tc.Pointer)
+			if tc.Error {
+				require.Error(t, err)
+				if tc.NotFound {
+					require.ErrorIs(t, err, jsptr.ErrNotFound)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, string(result.([]byte)))
+		})
+	}
+}
+
+func TestEditJSONText(t *testing.T) {
+	t.Run("Unchanged values are preserved", func(t *testing.T) {
+		const src = `{"z": 1.50, "a": [1e2, "xA"], "m": {"k": -0}}`
+		result, err := jsptr.Set(src, "/m/n", true)
+		require.NoError(t, err)
+		require.Equal(t, `{"z":1.50,"a":[1e2,"xA"],"m":{"k":-0,"n":true}}`, result)
+	})
+	t.Run("Arrays", func(t *testing.T) {
+		patch := jsptr.Patch{
+			{Op: "add", Path: "/a/1", Value: "b"},
+			{Op: "add", Path: "/a/0", Value: "first"},
+			{Op: "add", Path: "/a/-", Value: "last"},
+			{Op: "remove", Path: "/a/2"},
+			{Op: "replace", Path: "/a/2", Value: []any{1, 2}},
+		}
+		result, err := jsptr.ApplyPatch([]byte(`{"a": ["a", "c"]}`), patch)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":["first","a",[1,2],"last"]}`, string(result.([]byte)))
+	})
+	t.Run("Values", func(t *testing.T) {
+		type point struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		}
+		testcases := []struct {
+			Value    any
+			Expected string
+		}{
+			{Value: nil, Expected: `{"v":null}`},
+			{Value: "quote \" and \\ and \x01", Expected: `{"v":"quote \" and \\ and \u0001"}`},
+			{Value: 100000000.0, Expected: `{"v":100000000}`},
+			{Value: int64(1) << 60, Expected: `{"v":1152921504606846976}`},
+			{Value: json.Number("1.0"), Expected: `{"v":1.0}`},
+			{Value: map[string]any{"b": 1, "a": []any{}}, Expected: `{"v":{"a":[],"b":1}}`},
+			{Value: point{X: 1, Y: 2}, Expected: `{"v":{"x":1,"y":2}}`},
+			{Value: json.RawMessage(`[true]`), Expected: `{"v":[true]}`},
+		}
+		for _, tc := range testcases {
+			result, err := jsptr.Set(`{}`, "/v", tc.Value)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, result)
+		}
+
+		_, err := jsptr.Set(`{}`, "/v", math.NaN())
+		require.Error(t, err)
+	})
+	t.Run("Keys with control characters", func(t *testing.T) {
+		result, err := jsptr.Set(`{"a\u0007": 1}`, "/b", 2)
+		require.NoError(t, err)
+		require.True(t, json.Valid([]byte(result.(string))), result)
+		require.JSONEq(t, `{"a\u0007": 1, "b": 2}`, result.(string))
+	})
+}
+
+func TestEditInPlace(t *testing.T) {
+	t.Run("*any", func(t *testing.T) {
+		var doc any = []any{1.0}
+		result, err := jsptr.Set(&doc, "/-", 2.0)
+		require.NoError(t, err)
+		require.Equal(t, &doc, result)
+		require.Equal(t, []any{1.0, 2.0}, doc)
+
+		_, err = jsptr.Set(&doc, "", "replaced")
+		require.NoError(t, err)
+		require.Equal(t, "replaced", doc)
+	})
+	t.Run("*[]any", func(t *testing.T) {
+		doc := []any{"a", "c"}
+		_, err := jsptr.Set(&doc, "/2", "d")
+		require.NoError(t, err)
+		patch := jsptr.Patch{{Op: "add", Path: "/1", Value: "b"}}
+		_, err = jsptr.ApplyPatch(&doc, patch)
+		require.NoError(t, err)
+		require.Equal(t, []any{"a", "b", "c", "d"}, doc)
+
+		_, err = jsptr.Delete(&doc, "/0")
+		require.NoError(t, err)
+		require.Equal(t, []any{"b", "c", "d"}, doc)
+
+		_, err = jsptr.Set(&doc, "", map[string]any{})
+		require.ErrorContains(t, err, "cannot replace document of type []interface {} with map[string]interface {}")
+		require.Equal(t, []any{"b", "c", "d"}, doc)
+	})
+	t.Run("*map[string]any", func(t *testing.T) {
+		var doc map[string]any
+		_, err := jsptr.Set(&doc, "/a", []any{})
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"a": []any{}}, doc)
+
+		// References to nested containers see the changes, and arrays
+		// are updated in their parents when they grow
+		ref := doc
+		_, err = jsptr.Set(&doc, "/a/-", 1.0)
+		require.NoError(t, err)
+		require.Equal(t, []any{1.0}, ref["a"])
+	})
+}
+
+func TestSetCopy(t *testing.T) {
+	t.Run("map", func(t *testing.T) {
+		shared := map[string]any{"x": 1.0}
+		doc := map[string]any{
+			"a": map[string]any{"b": []any{1.0, 2.0}},
+			"c": shared,
+		}
+		result, err := jsptr.SetCopy(doc, "/a/b/-", 3.0)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"a": map[string]any{"b": []any{1.0, 2.0, 3.0}},
+			"c": map[string]any{"x": 1.0},
+		}, result)
+		require.Equal(t, map[string]any{
+			"a": map[string]any{"b": []any{1.0, 2.0}},
+			"c": map[string]any{"x": 1.0},
+		}, doc)
+
+		// Containers that are not on the path are shared
+		shared["y"] = 2.0
+		require.Equal(t, 2.0, result.(map[string]any)["c"].(map[string]any)["y"])
+	})
+	t.Run("array with spare capacity", func(t *testing.T) {
+		arr := make([]any, 1, 4)
+		arr[0] = "a"
+		result, err := jsptr.SetCopy(arr, "/-", "b")
+		require.NoError(t, err)
+		require.Equal(t, []any{"a", "b"}, result)
+		require.Equal(t, []any{"a"}, arr)
+		require.Nil(t, arr[:2][1])
+	})
+	t.Run("ordered", func(t *testing.T) {
+		doc := jsptr.NewOrderedMap()
+		inner := jsptr.NewOrderedMap()
+		inner.Set("b", 1.0)
+		doc.Set("a", inner)
+		result, err := jsptr.SetCopy(doc, "/a/c", 2.0)
+		require.NoError(t, err)
+
+		buf, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"a": {"b": 1, "c": 2}}`, string(buf))
+		buf, err = json.Marshal(doc)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":{"b":1}}`, string(buf))
+	})
+	t.Run("JSON text", func(t *testing.T) {
+		result, err := jsptr.SetCopy(`{"a":1}`, "/b", 2)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":1,"b":2}`, result)
+	})
+	t.Run("errors", func(t *testing.T) {
+		doc := map[string]any{"a": []any{}}
+		_, err := jsptr.SetCopy(doc, "/a/0/b", 1.0)
+		require.ErrorIs(t, err, jsptr.ErrNotFound)
+		require.Equal(t, map[string]any{"a": []any{}}, doc)
+	})
+}
+
+func TestSetWithParents(t *testing.T) {
+	testcases := []struct {
+		Name     string
+		Doc      any
+		Spec     string
+		Expected any
+		Error    bool
+	}{
+		{
+			Name:     "from scratch",
+			Doc:      nil,
+			Spec:     "/a/b/0/c",
+			Expected: map[string]any{"a": map[string]any{"b": []any{map[string]any{"c": "v"}}}},
+		},
+		{
+			Name:     "existing parents",
+			Doc:      map[string]any{"a": map[string]any{"x": 1.0}},
+			Spec:     "/a/b/-",
+			Expected: map[string]any{"a": map[string]any{"x": 1.0, "b": []any{"v"}}},
+		},
+		{
+			Name:     "null parent",
+			Doc:      map[string]any{"a": nil},
+			Spec:     "/a/b",
+			Expected: map[string]any{"a": map[string]any{"b": "v"}},
+		},
+		{
+			Name:     "appended element",
+			Doc:      []any{1.0},
+			Spec:     "/1/a",
+			Expected: []any{1.0, map[string]any{"a": "v"}},
+		},
+		{
+			Name:     "new element",
+			Doc:      map[string]any{"items": []any{map[string]any{"name": "a"}}},
+			Spec:     "/items/-/name",
+			Expected: map[string]any{"items": []any{map[string]any{"name": "a"}, map[string]any{"name": "v"}}},
+		},
+		{
+			Name:  "hole in array",
+			Doc:   nil,
+			Spec:  "/a/1/b",
+			Error: true,
+		},
+		{
+			Name:  "scalar parent",
+			Doc:   map[string]any{"a": 1.0},
+			Spec:  "/a/b",
+			Error: true,
+		},
+		{
+			Name:     "JSON text",
+			Doc:      `{"z":1,"a":null}`,
+			Spec:     "/a/b/-/c",
+			Expected: `{"z":1,"a":{"b":[{"c":"v"}]}}`,
+		},
+		{
+			Name:     "JSON text appended element",
+			Doc:      []byte(`[[1]]`),
+			Spec:     "/0/1/a",
+			Expected: []byte(`[[1,{"a":"v"}]]`),
+		},
+		{
+			Name:  "JSON text hole in array",
+			Doc:   `{}`,
+			Spec:  "/a/1/b",
+			Error: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := jsptr.Set(tc.Doc, tc.Spec, "v", jsptr.WithParents(true))
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, result)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := jsptr.Set(map[string]any{}, "/a/b", "v", jsptr.WithParents(false))
+		require.ErrorIs(t, err, jsptr.ErrNotFound)
+	})
+	t.Run("SetCopy", func(t *testing.T) {
+		doc := map[string]any{"a": map[string]any{}}
+		result, err := jsptr.SetCopy(doc, "/a/b/c", "v", jsptr.WithParents(true))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"a": map[string]any{"b": map[string]any{"c": "v"}}}, result)
+		require.Equal(t, map[string]any{"a": map[string]any{}}, doc)
+	})
+}
+
+func TestMove(t *testing.T) {
+	doc := map[string]any{"a": map[string]any{"b": 1.0}, "c": []any{}}
+	result, err := jsptr.Move(doc, "/a/b", "/c/-")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"a": map[string]any{}, "c": []any{1.0}}, result)
+
+	_, err = jsptr.Move(result, "/a", "/a/b")
+	require.ErrorContains(t, err, "failed to move '/a' to '/a/b': cannot move a value into one of its children")
+
+	_, err = jsptr.Move(result, "/x", "/y")
+	require.ErrorIs(t, err, jsptr.ErrNotFound)
+
+	text, err := jsptr.Move(`{"a":{"b":1},"c":2}`, "/a", "/d")
+	require.NoError(t, err)
+	require.Equal(t, `{"c":2,"d":{"b":1}}`, text)
+}
+
+func TestCopy(t *testing.T) {
+	doc := map[string]any{"a": map[string]any{"b": []any{1.0}}}
+	result, err := jsptr.Copy(doc, "/a", "/c")
+	require.NoError(t, err)
+	result, err = jsptr.Set(result, "/c/b/0", 2.0)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"a": map[string]any{"b": []any{1.0}},
+		"c": map[string]any{"b": []any{2.0}},
+	}, result)
+
+	_, err = jsptr.Copy(doc, "/x", "/y")
+	require.ErrorIs(t, err, jsptr.ErrNotFound)
+
+	text, err := jsptr.Copy([]byte(`{"a":[1,{"b":true}]}`), "/a", "/a/-")
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"a":[1,{"b":true},[1,{"b":true}]]}`), text)
+}
+
+func TestSetRollback(t *testing.T) {
+	doc := map[string]any{"a": map[string]any{}}
+	_, err := jsptr.Set(doc, "/a/b/c/1/d", "v", jsptr.WithParents(true))
+	require.Error(t, err)
+	require.Equal(t, map[string]any{"a": map[string]any{}}, doc)
+}
@@ -0,0 +1,128 @@
+package ndjson
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// Projector maps newline-delimited JSON records to rows of delimited
+// text (CSV or TSV), with one column per pointer.
+//
+// Strings are written as is, and numbers are written exactly as they
+// appear in the input. Objects and arrays are written as JSON.
+type Projector struct {
+	pointers    []*jsptr.Pointer
+	comma       rune
+	header      bool
+	nullValue   string
+	missing     string
+	skipInvalid bool
+}
+
+// NewProjector creates a new Projector that writes the values at the
+// locations specified by pointers as columns
+func NewProjector(pointers []*jsptr.Pointer, options ...ProjectOption) *Projector {
+	p := &Projector{
+		pointers: pointers,
+		comma:    ',',
+	}
+	for _, option := range options {
+		switch option.Ident() {
+		case identComma{}:
+			p.comma = option.Value().(rune)
+		case identHeader{}:
+			p.header = option.Value().(bool)
+		case identNullValue{}:
+			p.nullValue = option.Value().(string)
+		case identMissingValue{}:
+			p.missing = option.Value().(string)
+		case identSkipInvalid{}:
+			p.skipInvalid = option.Value().(bool)
+		}
+	}
+	return p
+}
+
+// Project reads newline-delimited JSON from src, and writes a row for
+// each record to dst
+func (p *Projector) Project(dst io.Writer, src io.Reader) error {
+	w := csv.NewWriter(dst)
+	w.Comma = p.comma
+
+	if p.header {
+		row := make([]string, len(p.pointers))
+		for i, ptr := range p.pointers {
+			row[i] = ptr.Pattern()
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+
+	for rec, err := range Extract(src, p.pointers, jsptr.WithNumberMode(jsptr.NumberJSONNumber)) {
+		if err != nil {
+			return err
+		}
+		if rec.Err != nil {
+			if p.skipInvalid {
+				continue
+			}
+			return rec.Err
+		}
+
+		row, err := p.row(rec)
+		if err != nil {
+			return err
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("failed to write line %d: %w", rec.Line, err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	return nil
+}
+
+func (p *Projector) row(rec *Record) ([]string, error) {
+	row := make([]string, len(rec.Results))
+	for i, result := range rec.Results {
+		if result.Err != nil {
+			row[i] = p.missing
+			continue
+		}
+
+		cell, err := p.cell(result.Value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: failed to format value at '%s': %w", rec.Line, p.pointers[i].Pattern(), err)
+		}
+		row[i] = cell
+	}
+	return row, nil
+}
+
+func (p *Projector) cell(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return p.nullValue, nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+}
@@ -0,0 +1,64 @@
+package ndjson_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr/ndjson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjector(t *testing.T) {
+	const src = `{"id": 1, "user": {"name": "alice, a."}, "score": 1.50, "tags": ["x"], "ok": true}
+{"id": 12345678901234567890, "user": {"name": null}, "ok": false}
+broken
+{"id": 3, "user": "nobody"}`
+
+	pointers := mustPointers(t, "/id", "/user/name", "/score", "/tags", "/ok")
+
+	testcases := []struct {
+		Name    string
+		Options []ndjson.ProjectOption
+		Want    string
+		Error   bool
+	}{
+		{
+			Name:    "CSV",
+			Options: []ndjson.ProjectOption{ndjson.WithHeader(true), ndjson.WithSkipInvalid(true)},
+			Want: `/id,/user/name,/score,/tags,/ok
+1,"alice, a.",1.50,"[""x""]",true
+12345678901234567890,,,,false
+3,,,,
+`,
+		},
+		{
+			Name: "TSV with placeholders",
+			Options: []ndjson.ProjectOption{
+				ndjson.WithComma('\t'),
+				ndjson.WithNullValue("NULL"),
+				ndjson.WithMissingValue("-"),
+				ndjson.WithSkipInvalid(true),
+			},
+			Want: "1\talice, a.\t1.50\t\"[\"\"x\"\"]\"\ttrue\n" +
+				"12345678901234567890\tNULL\t-\t-\tfalse\n" +
+				"3\t-\t-\t-\t-\n",
+		},
+		{
+			Name:  "invalid lines",
+			Error: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var out strings.Builder
+			err := ndjson.NewProjector(pointers, tc.Options...).Project(&out, strings.NewReader(src))
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Want, out.String())
+		})
+	}
+}
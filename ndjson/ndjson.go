@@ -0,0 +1,82 @@
+// Package ndjson extracts values from newline-delimited JSON (also known
+// as JSON Lines) streams, by applying JSON pointers to each record.
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// Record holds the values extracted from a single line of the stream
+type Record struct {
+	// Line is the 1-based line number of the record
+	Line int
+	// Results holds the outcome of evaluating each pointer against the
+	// record, in the same order as the pointers were given
+	Results []jsptr.Result
+	// Err is set if the line does not contain valid JSON, in which case
+	// Results is nil
+	Err error
+}
+
+// Values returns the values in Results. Values of pointers that could not
+// be resolved are nil.
+func (r *Record) Values() []any {
+	values := make([]any, len(r.Results))
+	for i, result := range r.Results {
+		values[i] = result.Value
+	}
+	return values
+}
+
+// Extract reads newline-delimited JSON from src, and yields a Record for
+// each line holding the results of evaluating the pointers against it.
+// Empty lines are skipped. Lines are read one at a time, so streams of
+// any size can be processed.
+//
+// Problems with individual lines are reported in the Record, and do not
+// stop the iteration. Errors reading from src are yielded as the second
+// value, after which the iteration stops.
+func Extract(src io.Reader, pointers []*jsptr.Pointer, options ...jsptr.RetrieveOption) iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		rdr := bufio.NewReader(src)
+		for lineno := 1; ; lineno++ {
+			line, err := rdr.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				yield(nil, fmt.Errorf("failed to read line %d: %w", lineno, err))
+				return
+			}
+
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				if !yield(extractRecord(lineno, line, pointers, options), nil) {
+					return
+				}
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func extractRecord(lineno int, line []byte, pointers []*jsptr.Pointer, options []jsptr.RetrieveOption) *Record {
+	rec := &Record{Line: lineno}
+	results, err := jsptr.RetrieveMulti(line, pointers, options...)
+	if err != nil {
+		rec.Err = fmt.Errorf("line %d: %w", lineno, err)
+		return rec
+	}
+
+	rec.Results = make([]jsptr.Result, len(pointers))
+	for i, ptr := range pointers {
+		rec.Results[i] = results[ptr.Pattern()]
+	}
+	return rec
+}
@@ -0,0 +1,105 @@
+package ndjson_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/lestrrat-go/jsptr/ndjson"
+	"github.com/stretchr/testify/require"
+)
+
+func mustPointers(t *testing.T, specs ...string) []*jsptr.Pointer {
+	t.Helper()
+	pointers := make([]*jsptr.Pointer, len(specs))
+	for i, spec := range specs {
+		ptr, err := jsptr.New(spec)
+		require.NoError(t, err)
+		pointers[i] = ptr
+	}
+	return pointers
+}
+
+func TestExtract(t *testing.T) {
+	const src = `{"level": "info", "msg": "started", "ctx": {"pid": 1}}
+{"level": "error", "msg": "failed"}
+
+not json
+{"level": "debug", "msg": "done", "ctx": {"pid": 2}}`
+
+	pointers := mustPointers(t, "/level", "/ctx/pid")
+
+	var records []*ndjson.Record
+	for rec, err := range ndjson.Extract(strings.NewReader(src), pointers) {
+		require.NoError(t, err)
+		records = append(records, rec)
+	}
+	require.Len(t, records, 4)
+
+	require.Equal(t, 1, records[0].Line)
+	require.Equal(t, []any{"info", 1.0}, records[0].Values())
+
+	require.Equal(t, 2, records[1].Line)
+	require.Equal(t, "error", records[1].Results[0].Value)
+	require.ErrorIs(t, records[1].Results[1].Err, jsptr.ErrNotFound)
+
+	require.Equal(t, 4, records[2].Line)
+	require.Error(t, records[2].Err)
+	require.Nil(t, records[2].Results)
+
+	require.Equal(t, 5, records[3].Line)
+	require.Equal(t, []any{"debug", 2.0}, records[3].Values())
+}
+
+func TestExtractOptions(t *testing.T) {
+	const src = "{\"id\": 9007199254740993}\r\n"
+
+	var values []any
+	for rec, err := range ndjson.Extract(strings.NewReader(src), mustPointers(t, "/id"), jsptr.WithNumberMode(jsptr.NumberJSONNumber)) {
+		require.NoError(t, err)
+		values = append(values, rec.Values()...)
+	}
+	require.Equal(t, []any{json.Number("9007199254740993")}, values)
+}
+
+type brokenReader struct {
+	r io.Reader
+}
+
+func (b *brokenReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestExtractReadError(t *testing.T) {
+	src := &brokenReader{r: strings.NewReader("{\"a\": 1}\n{\"a\": 2")}
+
+	var lines []int
+	var readErr error
+	for rec, err := range ndjson.Extract(src, mustPointers(t, "/a")) {
+		if err != nil {
+			readErr = err
+			continue
+		}
+		lines = append(lines, rec.Line)
+	}
+	require.Equal(t, []int{1}, lines)
+	require.Error(t, readErr)
+}
+
+func TestExtractStop(t *testing.T) {
+	src := strings.NewReader("{}\n{}\n{}\n")
+
+	var count int
+	for range ndjson.Extract(src, mustPointers(t, "")) {
+		count++
+		break
+	}
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,52 @@
+package ndjson
+
+import "github.com/lestrrat-go/option"
+
+// ProjectOption is an option that can be passed to NewProjector
+type ProjectOption interface {
+	option.Interface
+	projectOption()
+}
+
+type projectOption struct {
+	option.Interface
+}
+
+func (*projectOption) projectOption() {}
+
+type identComma struct{}
+type identHeader struct{}
+type identMissingValue struct{}
+type identNullValue struct{}
+type identSkipInvalid struct{}
+
+// WithHeader specifies whether a header row containing the pointers
+// should be written before the records. The default is false.
+func WithHeader(v bool) ProjectOption {
+	return &projectOption{option.New(identHeader{}, v)}
+}
+
+// WithComma specifies the field delimiter. The default is ','. Use '\t'
+// to produce TSV.
+func WithComma(v rune) ProjectOption {
+	return &projectOption{option.New(identComma{}, v)}
+}
+
+// WithNullValue specifies the text written for JSON null values. The
+// default is an empty string.
+func WithNullValue(v string) ProjectOption {
+	return &projectOption{option.New(identNullValue{}, v)}
+}
+
+// WithMissingValue specifies the text written for pointers that cannot be
+// resolved against a record. The default is an empty string.
+func WithMissingValue(v string) ProjectOption {
+	return &projectOption{option.New(identMissingValue{}, v)}
+}
+
+// WithSkipInvalid specifies that lines that do not contain valid JSON
+// should be skipped, instead of aborting the projection. The default
+// is false.
+func WithSkipInvalid(v bool) ProjectOption {
+	return &projectOption{option.New(identSkipInvalid{}, v)}
+}
@@ -0,0 +1,612 @@
+package jsptr
+
+import (
+	"context"
+
+	"github.com/lestrrat-go/option"
+)
+
+// Option is the base interface for all options accepted by this package
+type Option = option.Interface
+
+// RetrieveOption is an option that can be passed to retrieval functions
+// such as (*Pointer).Retrieve and (*Document).Retrieve. Retrieval options
+// can also be passed to the functions that retrieve values from HTTP
+// bodies
+type RetrieveOption interface {
+	HTTPOption
+	retrieveOption()
+}
+
+type retrieveOption struct {
+	Option
+}
+
+func (*retrieveOption) retrieveOption() {}
+func (*retrieveOption) httpOption()     {}
+
+// HTTPOption is an option that can be passed to the functions that read
+// HTTP bodies, such as RetrieveHTTPBody
+type HTTPOption interface {
+	Option
+	httpOption()
+}
+
+type httpOption struct {
+	Option
+}
+
+func (*httpOption) httpOption() {}
+
+// NewOption is an option that can be passed to New
+type NewOption interface {
+	Option
+	newOption()
+}
+
+type newOption struct {
+	Option
+}
+
+func (*newOption) newOption() {}
+
+// WalkOption is an option that can be passed to Walk
+type WalkOption interface {
+	Option
+	walkOption()
+}
+
+type walkOption struct {
+	Option
+}
+
+func (*walkOption) walkOption() {}
+
+// ConversionOption is an option that controls how JSON values are
+// converted to Go values. It can be passed to retrieval functions as
+// well as to Walk and Flatten
+type ConversionOption interface {
+	RetrieveOption
+	WalkOption
+}
+
+type conversionOption struct {
+	Option
+}
+
+func (*conversionOption) retrieveOption() {}
+func (*conversionOption) httpOption()     {}
+func (*conversionOption) walkOption()     {}
+
+// ParseOption is an option that can be passed to ParseJSON
+type ParseOption interface {
+	Option
+	parseOption()
+}
+
+// InputOption is an option that controls how JSON documents are read.
+// It can be passed to retrieval functions as well as to ParseJSON
+type InputOption interface {
+	RetrieveOption
+	ParseOption
+}
+
+type inputOption struct {
+	Option
+}
+
+func (*inputOption) retrieveOption() {}
+func (*inputOption) httpOption()     {}
+func (*inputOption) parseOption()    {}
+
+// SetOption is an option that can be passed to Set and SetCopy
+type SetOption interface {
+	Option
+	setOption()
+}
+
+type setOption struct {
+	Option
+}
+
+func (*setOption) setOption() {}
+
+// RedactorOption is an option that can be passed to NewRedactor
+type RedactorOption interface {
+	Option
+	redactorOption()
+}
+
+type redactorOption struct {
+	Option
+}
+
+func (*redactorOption) redactorOption() {}
+
+// MergeOption is an option that can be passed to Merge
+type MergeOption interface {
+	Option
+	mergeOption()
+}
+
+type mergeOption struct {
+	Option
+}
+
+func (*mergeOption) mergeOption() {}
+
+type identContainers struct{}
+type identExtensions struct{}
+type identNumberMode struct{}
+
+// NumberMode specifies how JSON numbers are converted to Go values
+// when retrieving values from JSON documents
+type NumberMode int
+
+const (
+	// NumberFloat64 converts numbers to float64, except for integers
+	// beyond ±2^53, which a float64 cannot represent exactly. Those are
+	// converted to int64, or to uint64 if they are too large for an
+	// int64. This is the default
+	NumberFloat64 NumberMode = iota
+	// NumberJSONNumber converts numbers to json.Number, preserving
+	// the original textual representation
+	NumberJSONNumber
+	// NumberInt64 converts integral numbers that fit in an int64 to int64,
+	// and all other numbers to float64
+	NumberInt64
+	// NumberBigFloat converts numbers to *big.Float, with enough precision
+	// to represent every digit of the original number
+	NumberBigFloat
+)
+
+// WithNumberMode specifies how JSON numbers are materialized when they
+// are retrieved from JSON documents, including numbers found inside of
+// retrieved objects and arrays. Values retrieved from Go data structures
+// are not affected.
+//
+// NumberJSONNumber matches the behavior of (*json.Decoder).UseNumber, so
+// that code written for it can consume the retrieved values unchanged.
+// When passed to Walk or Flatten, the option applies to the values
+// reported for JSON documents.
+func WithNumberMode(v NumberMode) ConversionOption {
+	return &conversionOption{option.New(identNumberMode{}, v)}
+}
+
+type identOrderedObjects struct{}
+
+// WithOrderedObjects specifies that JSON objects retrieved from JSON
+// documents should be represented as *OrderedMap instead of
+// map[string]any, so that the order of their members is preserved
+func WithOrderedObjects(v bool) ConversionOption {
+	return &conversionOption{option.New(identOrderedObjects{}, v)}
+}
+
+// WithExtensions enables non-standard extension tokens in the pointer
+// created by New. The following tokens are recognized:
+//
+//   - "*" matches every member of an object, or every element of an array
+//   - "**" matches zero or more levels of objects and arrays at any depth,
+//     so that "/**/email" matches every member named "email" in the document
+//
+// Pointers containing extension tokens may match more than one value.
+// Use (*Pointer).RetrieveAll to retrieve all of them. Note that when
+// extensions are enabled, object members whose names collide with
+// extension tokens can no longer be addressed.
+func WithExtensions(v bool) NewOption {
+	return &newOption{option.New(identExtensions{}, v)}
+}
+
+type identSlices struct{}
+
+// WithSlices enables slice tokens in the pointer created by New. A slice
+// token has the form "low:high", and refers to the sub-array containing
+// the elements from index low up to, but not including, index high.
+// Either bound may be omitted, so that "/items/:10" refers to the first
+// ten elements of "/items". Bounds beyond the end of the array are
+// clamped to its length.
+//
+// The remaining tokens of the pointer are evaluated against the
+// sub-array, so "/items/1:4/0" refers to "/items/1". Slice tokens can be
+// combined with the tokens enabled by WithExtensions, but they do not
+// require them.
+//
+// RetrieveAll reports the elements of the sub-array under their indices
+// in the original array: "/items/1:3" matches "/items/1" and "/items/2",
+// rather than a single sub-array.
+func WithSlices(v bool) NewOption {
+	return &newOption{option.New(identSlices{}, v)}
+}
+
+type identFilters struct{}
+
+// WithFilters enables filter tokens in the pointer created by New. A
+// filter token has the form "[member=literal]", and matches every
+// element of an array that is an object with a member named "member"
+// whose value equals the literal. The literal is interpreted as JSON if
+// possible, and as a string otherwise, so "/users/[id=42]/email" and
+// "/users/[name=alice]/email" both work as expected.
+//
+// Like the tokens enabled by WithExtensions, filter tokens may match
+// more than one value: (*Pointer).Retrieve retrieves the first of them,
+// and (*Pointer).RetrieveAll retrieves all of them.
+func WithFilters(v bool) NewOption {
+	return &newOption{option.New(identFilters{}, v)}
+}
+
+type identUnions struct{}
+
+// WithUnions enables union tokens in the pointer created by New. A union
+// token has the form "{a,b,c}", and lists alternative tokens that are
+// tried in the order they are listed. For example, "/config/{prod,staging}/url"
+// refers to "/config/prod/url" if it exists, and to "/config/staging/url"
+// otherwise. Alternatives cannot contain commas.
+//
+// (*Pointer).Retrieve retrieves the value of the first alternative that
+// exists, and (*Pointer).RetrieveAll retrieves the values of all of them.
+func WithUnions(v bool) NewOption {
+	return &newOption{option.New(identUnions{}, v)}
+}
+
+type identMaxDepth struct{}
+type identMaxTokenLength struct{}
+
+// WithMaxDepth specifies the maximum number of reference tokens that a
+// pointer created by New may have. Longer pointers are rejected with an
+// error matching ErrLimitExceeded. The default is 0, which means that
+// there is no limit.
+//
+// Use this, along with WithMaxTokenLength, when compiling pointers that
+// come from untrusted sources.
+func WithMaxDepth(v int) NewOption {
+	return &newOption{option.New(identMaxDepth{}, v)}
+}
+
+// WithMaxTokenLength specifies the maximum length in bytes of each
+// (escaped) reference token of a pointer created by New. Pointers with
+// longer tokens are rejected with an error matching ErrLimitExceeded.
+// The default is 0, which means that there is no limit.
+func WithMaxTokenLength(v int) NewOption {
+	return &newOption{option.New(identMaxTokenLength{}, v)}
+}
+
+// WithContainers specifies that Walk should report objects and arrays
+// in addition to leaf values. Containers are reported before their members.
+func WithContainers(v bool) WalkOption {
+	return &walkOption{option.New(identContainers{}, v)}
+}
+
+type identParents struct{}
+
+// WithParents specifies that Set and SetCopy should create the objects
+// and arrays that lead to the location when they do not exist, in the
+// same way as "mkdir -p" creates directories. A missing container is
+// created as an array if the token that follows it is "-" or an array
+// index, and as an object otherwise. Null values along the way are
+// replaced as if they were missing.
+//
+// As arrays cannot have holes, an array element can only be created by
+// appending it, using "-" or the length of the array as the token.
+func WithParents(v bool) SetOption {
+	return &setOption{option.New(identParents{}, v)}
+}
+
+type identRedaction struct{}
+
+// redaction associates a mask with a pointer pattern
+type redaction struct {
+	pattern string
+	mask    Mask
+}
+
+// WithRedaction specifies that the values matched by the pointer
+// `pattern` are replaced with the value computed by mask. The pattern may
+// contain the extension tokens described in WithExtensions, such as
+// "/**/password". It may be specified multiple times, in which case the
+// patterns are applied in order.
+func WithRedaction(pattern string, mask Mask) RedactorOption {
+	return &redactorOption{option.New(identRedaction{}, redaction{pattern: pattern, mask: mask})}
+}
+
+type identMergeRule struct{}
+
+// mergeRule associates a merge strategy with a pointer pattern
+type mergeRule struct {
+	pattern  string
+	strategy MergeStrategy
+}
+
+// WithMergeRule specifies the strategy that Merge uses for the locations
+// matched by the pointer `pattern`. The pattern may contain the extension
+// tokens described in WithExtensions, such as "/services/*/env". It may
+// be specified multiple times, in which case the first rule whose pattern
+// matches a location is used.
+func WithMergeRule(pattern string, strategy MergeStrategy) MergeOption {
+	return &mergeOption{option.New(identMergeRule{}, mergeRule{pattern: pattern, strategy: strategy})}
+}
+
+type identCaseInsensitive struct{}
+
+// WithCaseInsensitiveFields specifies that struct fields should be matched
+// case-insensitively when no field matches a token exactly, the same way
+// encoding/json matches object keys to struct fields. For example, the
+// token "num" would match a field named "Num". Exact matches always take
+// precedence.
+func WithCaseInsensitiveFields(v bool) RetrieveOption {
+	return &retrieveOption{option.New(identCaseInsensitive{}, v)}
+}
+
+type identTagName struct{}
+
+// WithTagName specifies the struct tag that is consulted to determine the
+// names of struct fields, such as "yaml" or "mapstructure". Only the name
+// portion of the tag (the part before the first comma) is used. Fields
+// without the tag are named after the Go field, and fields whose tag is
+// "-" are ignored. The default is "json".
+func WithTagName(v string) RetrieveOption {
+	return &retrieveOption{option.New(identTagName{}, v)}
+}
+
+type identQuotedFields struct{}
+
+// WithQuotedFields specifies that struct fields tagged with the ",string"
+// option (e.g. `json:"id,string"`) should be presented the way
+// encoding/json encodes them: as a string containing the JSON encoding of
+// the field value. This makes retrieving from a struct return the same
+// values as retrieving from its JSON encoding.
+//
+// In addition, when a string value cannot be assigned to a destination
+// of a numeric or boolean type, the string is decoded as the quoted
+// representation of such a value, regardless of whether the value came
+// from a struct or from a JSON document.
+func WithQuotedFields(v bool) RetrieveOption {
+	return &retrieveOption{option.New(identQuotedFields{}, v)}
+}
+
+type identStopEarly struct{}
+
+// WithStopEarly specifies that JSON documents given as []byte, string or
+// io.Reader should only be read up to the end of the value at the pointer
+// location. Only the parts of the document that lead to the value are
+// scanned, and the value itself is the only part that is fully parsed,
+// which makes retrieving values near the beginning of large documents
+// considerably faster.
+//
+// As the rest of the document is never looked at, errors in it are not
+// reported. For io.Reader targets, the reader is left positioned
+// somewhere after the value.
+func WithStopEarly(v bool) RetrieveOption {
+	return &retrieveOption{option.New(identStopEarly{}, v)}
+}
+
+type identMaxBodySize struct{}
+
+// WithMaxBodySize specifies the maximum number of bytes that
+// RetrieveHTTPBody and RetrieveHTTPRequestBody read from a body.
+// Larger bodies are rejected. The default is DefaultMaxBodySize.
+func WithMaxBodySize(v int64) HTTPOption {
+	return &httpOption{option.New(identMaxBodySize{}, v)}
+}
+
+type identMaxDocumentSize struct{}
+
+// WithMaxDocumentSize specifies the maximum size in bytes of the JSON
+// documents that values are retrieved from, when they are given as
+// []byte, string or io.Reader, or read using RetrieveFile. Larger
+// documents are rejected with an error matching ErrLimitExceeded, and
+// readers are not read past the limit. The default is 0, which means
+// that there is no limit.
+//
+// When passed to ParseJSON, the size of the parsed document is limited.
+func WithMaxDocumentSize(v int64) InputOption {
+	return &inputOption{option.New(identMaxDocumentSize{}, v)}
+}
+
+type identTrace struct{}
+
+// WithTrace specifies a function that is called for every step taken
+// while following a pointer, whether it succeeds or fails. This is
+// useful for debugging pointers that cannot be resolved, as the last
+// step shows where the traversal stopped and why.
+//
+// Steps taken by custom Source implementations are not reported.
+func WithTrace(fn TraceFunc) RetrieveOption {
+	return &retrieveOption{option.New(identTrace{}, fn)}
+}
+
+type identConverters struct{}
+
+// WithConverters specifies a registry of converters that is consulted
+// when retrieving values. If a converter is registered for the type of
+// the destination, the value is retrieved as a generic value and handed
+// to the converter, instead of being assigned directly.
+//
+// Only the destination itself is looked up: fields of a destination
+// struct are populated as usual, even if their types have converters.
+func WithConverters(c *Converters) RetrieveOption {
+	return &retrieveOption{option.New(identConverters{}, c)}
+}
+
+type identDuplicateKeys struct{}
+
+// WithDuplicateKeys specifies how objects with duplicate member names
+// in JSON documents are handled. See DuplicateKeyPolicy for the
+// available policies.
+//
+// The policy is applied when a document is read, so for parsed documents
+// such as *Document it must be passed to ParseJSON. Retrieving values from
+// a parsed document using a policy other than the one it was parsed with
+// is an error, as the duplicates can no longer be detected.
+//
+// Any policy other than DuplicateKeysUnchecked requires the entire
+// document to be parsed before the pointer is followed, so WithStopEarly
+// and streaming from readers no longer avoid reading the whole document.
+func WithDuplicateKeys(v DuplicateKeyPolicy) InputOption {
+	return &inputOption{option.New(identDuplicateKeys{}, v)}
+}
+
+type identAssigner struct{}
+
+// WithAssigner specifies the Assigner used to assign retrieved values to
+// their destinations, overriding the one set using SetAssigner. Passing
+// nil uses the Assigner set using SetAssigner, or the default one.
+func WithAssigner(a Assigner) RetrieveOption {
+	return &retrieveOption{option.New(identAssigner{}, a)}
+}
+
+type identLazyValues struct{}
+
+// WithLazyValues specifies that objects and arrays retrieved into an *any
+// from JSON bytes or strings are assigned as a *LazyValue, instead of
+// being converted to map[string]any and []any right away. This avoids
+// converting large values of which only a few members are needed, such
+// as the entire document retrieved using the empty pointer.
+//
+// As with WithStopEarly, the document is only scanned up to the end of
+// the retrieved value, and syntax errors within the value are only
+// reported once it is accessed.
+func WithLazyValues(v bool) RetrieveOption {
+	return &retrieveOption{option.New(identLazyValues{}, v)}
+}
+
+type identParallelism struct{}
+
+// WithParallelism specifies the maximum number of goroutines used by
+// RetrieveMulti to evaluate pointers against a JSON document given as
+// bytes, a string or a *Document. Other targets, such as custom Sources
+// that may not be safe for concurrent use, are always evaluated by a
+// single goroutine. The default is to evaluate pointers one at a time.
+//
+// Functions passed using options such as WithTrace may be called from
+// multiple goroutines at once.
+func WithParallelism(n int) RetrieveOption {
+	return &retrieveOption{option.New(identParallelism{}, n)}
+}
+
+type identStructCache struct{}
+
+// WithStructCache specifies whether the field information of the struct
+// types that are traversed is cached. Passing false computes the field
+// information anew, without storing it in the cache of the package or
+// in the pointer, which avoids retaining types that are created
+// dynamically at the cost of slower retrievals. The default is true.
+func WithStructCache(v bool) RetrieveOption {
+	return &retrieveOption{option.New(identStructCache{}, v)}
+}
+
+type identZeroCopyStrings struct{}
+
+// WithZeroCopyStrings specifies that strings retrieved into a *string
+// from JSON bytes share their memory with the bytes of the document,
+// instead of being copied. This avoids an allocation per string, which
+// can make up most of the cost of extracting strings from documents.
+//
+// This is unsafe: the retrieved strings are only valid for as long as
+// the JSON bytes are not modified. Modifying or reusing the buffer that
+// holds the document, for example by returning it to a sync.Pool or
+// reading the next document into it, silently changes the contents of
+// the strings. Retained strings also keep the whole buffer alive.
+//
+// Strings that contain escape sequences are always copied, as they must
+// be unescaped. The option applies to []byte targets, Documents, and
+// json.RawMessage values found in Go values. String and io.Reader
+// targets are copied before they are parsed, so strings retrieved from
+// them never alias memory owned by the caller.
+//
+// Documents keep a private copy of their bytes, which is never modified.
+// Strings retrieved from Documents share their memory with that copy,
+// including strings that had to be unescaped, and remain valid even if
+// the bytes passed to ParseJSON are modified.
+func WithZeroCopyStrings(v bool) RetrieveOption {
+	return &retrieveOption{option.New(identZeroCopyStrings{}, v)}
+}
+
+type identBackend struct{}
+
+// WithBackend specifies the Backend that is used to decode JSON documents
+// given as []byte or string, instead of the one set using SetBackend or
+// the built-in parser. It has no effect if WithOrderedObjects(true) is
+// also specified, as backends cannot preserve the order of object members.
+func WithBackend(v Backend) RetrieveOption {
+	return &retrieveOption{option.New(identBackend{}, v)}
+}
+
+// retrieveConfig holds the settings that affect a single retrieval
+type retrieveConfig struct {
+	numberMode      NumberMode
+	orderedObjects  bool
+	caseInsensitive bool
+	tagName         string
+	quotedFields    bool
+	stopEarly       bool
+	maxDocumentSize int64
+	trace           TraceFunc
+	converters      *Converters
+	duplicateKeys   DuplicateKeyPolicy
+	assigner        Assigner
+	lazyValues      bool
+	parallelism     int
+	noStructCache   bool
+	backend         Backend
+	zeroCopyStrings bool
+	// prefix holds the tokens that lead to the value being traversed,
+	// when it was reached through another value
+	prefix []string
+	// ctx is only set by RetrieveContext
+	ctx context.Context
+}
+
+// structTag returns the name of the struct tag used to name struct fields
+func (cfg *retrieveConfig) structTag() string {
+	if cfg.tagName == "" {
+		return "json"
+	}
+	return cfg.tagName
+}
+
+var defaultRetrieveConfig = &retrieveConfig{}
+
+func newRetrieveConfig(options []RetrieveOption) *retrieveConfig {
+	if len(options) == 0 {
+		return defaultRetrieveConfig
+	}
+
+	var cfg retrieveConfig
+	for _, option := range options {
+		switch option.Ident() {
+		case identNumberMode{}:
+			cfg.numberMode = option.Value().(NumberMode)
+		case identOrderedObjects{}:
+			cfg.orderedObjects = option.Value().(bool)
+		case identCaseInsensitive{}:
+			cfg.caseInsensitive = option.Value().(bool)
+		case identTagName{}:
+			cfg.tagName = option.Value().(string)
+		case identQuotedFields{}:
+			cfg.quotedFields = option.Value().(bool)
+		case identStopEarly{}:
+			cfg.stopEarly = option.Value().(bool)
+		case identMaxDocumentSize{}:
+			cfg.maxDocumentSize = option.Value().(int64)
+		case identZeroCopyStrings{}:
+			cfg.zeroCopyStrings = option.Value().(bool)
+		case identBackend{}:
+			cfg.backend, _ = option.Value().(Backend)
+		case identTrace{}:
+			cfg.trace = option.Value().(TraceFunc)
+		case identConverters{}:
+			cfg.converters = option.Value().(*Converters)
+		case identDuplicateKeys{}:
+			cfg.duplicateKeys = option.Value().(DuplicateKeyPolicy)
+		case identAssigner{}:
+			cfg.assigner, _ = option.Value().(Assigner)
+		case identLazyValues{}:
+			cfg.lazyValues = option.Value().(bool)
+		case identParallelism{}:
+			cfg.parallelism = option.Value().(int)
+		case identStructCache{}:
+			cfg.noStructCache = !option.Value().(bool)
+		}
+	}
+	return &cfg
+}
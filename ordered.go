@@ -0,0 +1,111 @@
+package jsptr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// OrderedMap is an object representation that preserves the order in
+// which its members were added. It is used instead of map[string]any
+// when objects are retrieved using the WithOrderedObjects option.
+//
+// OrderedMap can be used as a target for JSON pointers, and it is
+// encoded to and decoded from JSON with its member order intact.
+type OrderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewOrderedMap creates a new empty OrderedMap
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: make(map[string]any)}
+}
+
+// Len returns the number of members in the map
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the member names in the order they were added
+func (m *OrderedMap) Keys() []string {
+	return append([]string(nil), m.keys...)
+}
+
+// Get returns the value associated with key
+func (m *OrderedMap) Get(key string) (any, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Set associates value with key. If the key already exists, its value is
+// replaced, and it keeps its original position
+func (m *OrderedMap) Set(key string, value any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes key from the map
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// clone returns a shallow copy of m
+func (m *OrderedMap) clone() *OrderedMap {
+	return &OrderedMap{keys: slices.Clone(m.keys), values: maps.Clone(m.values)}
+}
+
+// MarshalJSON encodes the map as a JSON object, with members in order
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode member '%s': %w", key, err)
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into the map, preserving the order
+// of its members. Nested objects are decoded as *OrderedMap as well
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	v, err := materializeJSON(data, &retrieveConfig{orderedObjects: true})
+	if err != nil {
+		return err
+	}
+	om, ok := v.(*OrderedMap)
+	if !ok {
+		return fmt.Errorf("cannot decode %T into OrderedMap", v)
+	}
+	*m = *om
+	return nil
+}
@@ -0,0 +1,72 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap(t *testing.T) {
+	const src = `{"zeta": 1, "alpha": {"y": true, "x": false}, "mid": [{"b": 1, "a": 2}]}`
+
+	t.Run("WithOrderedObjects", func(t *testing.T) {
+		ptr, err := jsptr.New("")
+		require.NoError(t, err)
+
+		var result any
+		require.NoError(t, ptr.Retrieve(&result, []byte(src), jsptr.WithOrderedObjects(true)))
+
+		om, ok := result.(*jsptr.OrderedMap)
+		require.True(t, ok, "expected *jsptr.OrderedMap, got %T", result)
+		require.Equal(t, []string{"zeta", "alpha", "mid"}, om.Keys())
+
+		alpha, ok := om.Get("alpha")
+		require.True(t, ok)
+		require.Equal(t, []string{"y", "x"}, alpha.(*jsptr.OrderedMap).Keys())
+
+		buf, err := json.Marshal(om)
+		require.NoError(t, err)
+		require.Equal(t, `{"zeta":1,"alpha":{"y":true,"x":false},"mid":[{"b":1,"a":2}]}`, string(buf))
+
+		// The result can be used as a target
+		nested, err := jsptr.New("/mid/0/a")
+		require.NoError(t, err)
+		var f float64
+		require.NoError(t, nested.Retrieve(&f, om))
+		require.Equal(t, 2.0, f)
+	})
+	t.Run("Set, Delete", func(t *testing.T) {
+		om := jsptr.NewOrderedMap()
+		om.Set("b", 1)
+		om.Set("a", 2)
+		om.Set("b", 3)
+		require.Equal(t, []string{"b", "a"}, om.Keys())
+		require.Equal(t, 2, om.Len())
+
+		v, ok := om.Get("b")
+		require.True(t, ok)
+		require.Equal(t, 3, v)
+
+		om.Delete("b")
+		require.Equal(t, []string{"a"}, om.Keys())
+		_, ok = om.Get("b")
+		require.False(t, ok)
+	})
+	t.Run("UnmarshalJSON", func(t *testing.T) {
+		var om jsptr.OrderedMap
+		require.NoError(t, json.Unmarshal([]byte(src), &om))
+		require.Equal(t, []string{"zeta", "alpha", "mid"}, om.Keys())
+
+		require.Error(t, json.Unmarshal([]byte(`[1, 2]`), &om))
+	})
+	t.Run("retrieve into OrderedMap value", func(t *testing.T) {
+		ptr, err := jsptr.New("/alpha")
+		require.NoError(t, err)
+
+		var om jsptr.OrderedMap
+		require.NoError(t, ptr.Retrieve(&om, []byte(src)))
+		require.Equal(t, []string{"y", "x"}, om.Keys())
+	})
+}
@@ -0,0 +1,228 @@
+package jsptr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/valyala/fastjson"
+)
+
+// ErrTestFailed is the error that is returned (possibly wrapped) by Test,
+// and by ApplyPatch when a "test" operation fails, when the value at the
+// location is not equal to the expected value. Use errors.Is to check
+// for it.
+var ErrTestFailed = errors.New("test failed")
+
+// Operation is a single operation of a JSON Patch (RFC 6902).
+//
+// The "add", "remove", "replace", "move", "copy" and "test" operations
+// are supported
+type Operation struct {
+	// Op is the name of the operation
+	Op string
+	// Path is the JSON pointer to the location that the operation applies to
+	Path string
+	// From is the JSON pointer to the location that the value is taken
+	// from, for the "move" and "copy" operations
+	From string
+	// Value is the value to add, to replace the existing value with, or
+	// to compare the existing value to
+	Value any
+}
+
+// MarshalJSON encodes the operation as a JSON Patch operation object
+func (op Operation) MarshalJSON() ([]byte, error) {
+	type withValue struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}
+	type remove struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+	}
+	type moveOrCopy struct {
+		Op   string `json:"op"`
+		From string `json:"from"`
+		Path string `json:"path"`
+	}
+
+	switch op.Op {
+	case "remove":
+		return json.Marshal(remove{Op: op.Op, Path: op.Path})
+	case "move", "copy":
+		return json.Marshal(moveOrCopy{Op: op.Op, From: op.From, Path: op.Path})
+	}
+	return json.Marshal(withValue{Op: op.Op, Path: op.Path, Value: op.Value})
+}
+
+// Patch is a JSON Patch document (RFC 6902): a sequence of operations
+// that are applied in order
+type Patch []Operation
+
+// DecodePatch decodes a JSON Patch document.
+//
+// Values are materialized as if they were retrieved with the given
+// options, so that for example WithOrderedObjects(true) can be used to
+// decode objects as *OrderedMap.
+func DecodePatch(data []byte, options ...RetrieveOption) (Patch, error) {
+	var ops []struct {
+		Op    *string         `json:"op"`
+		Path  *string         `json:"path"`
+		From  *string         `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("failed to decode patch: %w", err)
+	}
+
+	cfg := newRetrieveConfig(options)
+	patch := make(Patch, len(ops))
+	for i, op := range ops {
+		if op.Op == nil {
+			return nil, fmt.Errorf("operation %d: missing \"op\"", i)
+		}
+		if op.Path == nil {
+			return nil, fmt.Errorf("operation %d: missing \"path\"", i)
+		}
+		patch[i] = Operation{Op: *op.Op, Path: *op.Path}
+
+		switch *op.Op {
+		case "add", "replace", "test":
+			// A null value is present, and is not the same as a missing one
+			if op.Value == nil {
+				return nil, fmt.Errorf("operation %d: missing \"value\"", i)
+			}
+			v, err := materializeJSON(op.Value, cfg)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+			patch[i].Value = v
+		case "move", "copy":
+			if op.From == nil {
+				return nil, fmt.Errorf("operation %d: missing \"from\"", i)
+			}
+			patch[i].From = *op.From
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unsupported operation %q", i, *op.Op)
+		}
+	}
+	return patch, nil
+}
+
+// ApplyPatch applies the operations of patch to doc in order, and
+// returns the resulting document. The same types of documents as those
+// accepted by Set can be used, and the same caveats apply.
+//
+// The patch is applied atomically: if one of the operations fails, the
+// changes made by the previous ones are rolled back, and doc is left as
+// it was. Any sequence of changes, such as a batch of Set and Delete
+// calls, can be made atomic by expressing it as a patch.
+func ApplyPatch(doc any, patch Patch) (any, error) {
+	return edit(doc, func(ed editor, root any) (any, error) {
+		for i, op := range patch {
+			var err error
+			if root, err = ed.apply(root, op); err != nil {
+				return nil, fmt.Errorf("operation %d (%s '%s') failed: %w", i, op.Op, op.Path, err)
+			}
+		}
+		return root, nil
+	})
+}
+
+func (ed editor) apply(root any, op Operation) (any, error) {
+	tokens, err := parseTokens(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return ed.mutate(root, tokens, editAdd, op.Value)
+	case "replace":
+		return ed.mutate(root, tokens, editReplace, op.Value)
+	case "remove":
+		return ed.mutate(root, tokens, editRemove, nil)
+	case "test":
+		v, err := ed.get(root, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if err := testValue(v, op.Value); err != nil {
+			return nil, err
+		}
+		return root, nil
+	case "move", "copy":
+		from, err := parseTokens(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			return ed.move(root, from, tokens)
+		}
+		return ed.copyValue(root, from, tokens)
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op.Op)
+	}
+}
+
+// Test reports whether the value at the location specified by the JSON
+// pointer `spec` in doc is equal to expected, and returns an error
+// matching ErrTestFailed if it is not, as the RFC 6902 "test" operation
+// does. This can be used to check that a document has not changed before
+// modifying it.
+//
+// doc can be any target accepted by (*Pointer).Retrieve. Values are
+// compared by their JSON representation: numbers are compared by value,
+// and the order of object members is not significant.
+func Test(doc any, spec string, expected any) error {
+	ptr, err := New(spec)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := ptr.Retrieve(&v, doc, WithNumberMode(NumberJSONNumber)); err != nil {
+		return fmt.Errorf("failed to test '%s': %w", spec, err)
+	}
+	if err := testValue(v, expected); err != nil {
+		return fmt.Errorf("failed to test '%s': %w", spec, err)
+	}
+	return nil
+}
+
+// testValue compares the value v taken from a document to expected
+func testValue(v, expected any) error {
+	actual, err := comparableValue(v)
+	if err != nil {
+		return err
+	}
+	want, err := comparableValue(expected)
+	if err != nil {
+		return err
+	}
+	if !equalValues(actual, want) {
+		return fmt.Errorf("value does not match the expected value: %w", ErrTestFailed)
+	}
+	return nil
+}
+
+// comparableValue converts v to the generic representation of its JSON
+// encoding, with exact numbers. Unlike genericValue, []byte and string
+// values are not treated as JSON text
+func comparableValue(v any) (any, error) {
+	if jv, ok := v.(*fastjson.Value); ok {
+		var result any
+		if err := (jsonSource{}).assignFromValue(&result, jv, editValuesConfig); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T: %w", v, err)
+	}
+	return materializeJSON(buf, editValuesConfig)
+}
@@ -0,0 +1,328 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	// Examples from RFC 6902, Appendix A
+	testcases := []struct {
+		Name     string
+		Doc      string
+		Patch    string
+		Expected string
+		Error    bool
+	}{
+		{
+			Name:     "adding an object member",
+			Doc:      `{"foo": "bar"}`,
+			Patch:    `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			Expected: `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			Name:     "adding an array element",
+			Doc:      `{"foo": ["bar", "baz"]}`,
+			Patch:    `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			Expected: `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			Name:     "removing an object member",
+			Doc:      `{"baz": "qux", "foo": "bar"}`,
+			Patch:    `[{"op": "remove", "path": "/baz"}]`,
+			Expected: `{"foo": "bar"}`,
+		},
+		{
+			Name:     "removing an array element",
+			Doc:      `{"foo": ["bar", "qux", "baz"]}`,
+			Patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+			Expected: `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			Name:     "replacing a value",
+			Doc:      `{"baz": "qux", "foo": "bar"}`,
+			Patch:    `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			Expected: `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			Name:     "adding a nested member object",
+			Doc:      `{"foo": "bar"}`,
+			Patch:    `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			Expected: `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			Name:  "adding to a nonexistent target",
+			Doc:   `{"foo": "bar"}`,
+			Patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			Error: true,
+		},
+		{
+			Name:     "~ escape ordering",
+			Doc:      `{"/": 9, "~1": 10}`,
+			Patch:    `[{"op": "replace", "path": "/~01", "value": 11}]`,
+			Expected: `{"/": 9, "~1": 11}`,
+		},
+		{
+			Name:  "invalid array index",
+			Doc:   `{"foo": ["bar"]}`,
+			Patch: `[{"op": "add", "path": "/foo/1e0", "value": "bar"}]`,
+			Error: true,
+		},
+		{
+			Name:     "adding an array value",
+			Doc:      `{"foo": ["bar"]}`,
+			Patch:    `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			Expected: `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+		{
+			Name:     "replacing the root",
+			Doc:      `{"foo": "bar"}`,
+			Patch:    `[{"op": "replace", "path": "", "value": null}]`,
+			Expected: `null`,
+		},
+		{
+			Name:  "replacing a nonexistent value",
+			Doc:   `{"foo": "bar"}`,
+			Patch: `[{"op": "replace", "path": "/baz", "value": 1}]`,
+			Error: true,
+		},
+		{
+			Name:     "moving a value",
+			Doc:      `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			Patch:    `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			Expected: `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			Name:     "moving an array element",
+			Doc:      `{"foo": ["all", "grass", "cows", "eat"]}`,
+			Patch:    `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			Expected: `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			Name:     "moving a value to its own location",
+			Doc:      `{"foo": {"bar": 1}}`,
+			Patch:    `[{"op": "move", "from": "/foo", "path": "/foo"}]`,
+			Expected: `{"foo": {"bar": 1}}`,
+		},
+		{
+			Name:  "moving a value into one of its children",
+			Doc:   `{"foo": {"bar": 1}}`,
+			Patch: `[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`,
+			Error: true,
+		},
+		{
+			Name:  "moving a nonexistent value",
+			Doc:   `{"foo": 1}`,
+			Patch: `[{"op": "move", "from": "/bar", "path": "/baz"}]`,
+			Error: true,
+		},
+		{
+			Name:     "copying a value",
+			Doc:      `{"foo": {"bar": [1]}}`,
+			Patch:    `[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "add", "path": "/baz/bar/-", "value": 2}]`,
+			Expected: `{"foo": {"bar": [1]}, "baz": {"bar": [1, 2]}}`,
+		},
+		{
+			Name:     "copying a value into one of its children",
+			Doc:      `{"foo": {"bar": 1}}`,
+			Patch:    `[{"op": "copy", "from": "/foo", "path": "/foo/baz"}]`,
+			Expected: `{"foo": {"bar": 1, "baz": {"bar": 1}}}`,
+		},
+		{
+			Name:     "testing a value: success",
+			Doc:      `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			Patch:    `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`,
+			Expected: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			Name:  "testing a value: error",
+			Doc:   `{"baz": "qux"}`,
+			Patch: `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			Error: true,
+		},
+		{
+			Name:     "comparing strings and numbers",
+			Doc:      `{"/": 9, "~1": 10}`,
+			Patch:    `[{"op": "test", "path": "/~01", "value": 10}]`,
+			Expected: `{"/": 9, "~1": 10}`,
+		},
+		{
+			Name:  "comparing strings and numbers: error",
+			Doc:   `{"/": 9, "~1": 10}`,
+			Patch: `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			Error: true,
+		},
+		{
+			Name:     "testing objects regardless of member order",
+			Doc:      `{"a": {"x": 1, "y": [true, null]}}`,
+			Patch:    `[{"op": "test", "path": "/a", "value": {"y": [true, null], "x": 1e0}}, {"op": "remove", "path": "/a/x"}]`,
+			Expected: `{"a": {"y": [true, null]}}`,
+		},
+		{
+			Name:  "adding beyond the end of an array",
+			Doc:   `{"foo": ["bar"]}`,
+			Patch: `[{"op": "add", "path": "/foo/2", "value": 1}]`,
+			Error: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			patch, err := jsptr.DecodePatch([]byte(tc.Patch))
+			require.NoError(t, err)
+
+			result, err := jsptr.ApplyPatch([]byte(tc.Doc), patch)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tc.Expected, string(result.([]byte)))
+
+			// Generic documents produce the same results
+			result, err = jsptr.ApplyPatch(decodeJSON(t, tc.Doc), patch)
+			require.NoError(t, err)
+			require.Equal(t, decodeJSON(t, tc.Expected), result)
+		})
+	}
+}
+
+func TestDecodePatch(t *testing.T) {
+	t.Run("invalid patches", func(t *testing.T) {
+		for _, src := range []string{
+			`{"op": "add", "path": "/a", "value": 1}`,
+			`[{"path": "/a", "value": 1}]`,
+			`[{"op": "add", "value": 1}]`,
+			`[{"op": "add", "path": "/a"}]`,
+			`[{"op": "frobnicate", "path": "/a"}]`,
+			`[{"op": "move", "path": "/a"}]`,
+			`[{"op": "copy", "path": "/a"}]`,
+			`[{"op": "test", "path": "/a"}]`,
+		} {
+			_, err := jsptr.DecodePatch([]byte(src))
+			require.Error(t, err, src)
+		}
+	})
+	t.Run("values", func(t *testing.T) {
+		patch, err := jsptr.DecodePatch([]byte(`[{"op": "add", "path": "/a", "value": {"z": 1, "a": null}}]`),
+			jsptr.WithOrderedObjects(true), jsptr.WithNumberMode(jsptr.NumberJSONNumber))
+		require.NoError(t, err)
+		om, ok := patch[0].Value.(*jsptr.OrderedMap)
+		require.True(t, ok)
+		require.Equal(t, []string{"z", "a"}, om.Keys())
+		z, _ := om.Get("z")
+		require.Equal(t, json.Number("1"), z)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		const src = `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"op":"replace","path":"/c","value":[1]},{"op":"move","from":"/d","path":"/e"},{"op":"copy","from":"/f","path":"/g"},{"op":"test","path":"/h","value":"x"}]`
+		patch, err := jsptr.DecodePatch([]byte(src))
+		require.NoError(t, err)
+		buf, err := json.Marshal(patch)
+		require.NoError(t, err)
+		require.Equal(t, src, string(buf))
+	})
+}
+
+func TestTest(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+
+	testcases := []struct {
+		Name     string
+		Doc      any
+		Spec     string
+		Expected any
+		Error    error
+	}{
+		{Name: "JSON text", Doc: `{"a": {"b": 1.0, "c": "x"}}`, Spec: "/a", Expected: map[string]any{"c": "x", "b": 1}},
+		{Name: "exact numbers", Doc: `{"a": 12345678901234567890}`, Spec: "/a", Expected: json.Number("12345678901234567890")},
+		{Name: "different numbers", Doc: `{"a": 12345678901234567890}`, Spec: "/a", Expected: json.Number("12345678901234567891"), Error: jsptr.ErrTestFailed},
+		{Name: "Go values", Doc: item{Name: "a", Count: 2, Tags: []string{"x"}}, Spec: "", Expected: map[string]any{"name": "a", "count": 2.0, "tags": []any{"x"}}},
+		{Name: "string", Doc: map[string]any{"a": "[1]"}, Spec: "/a", Expected: "[1]"},
+		{Name: "string is not JSON text", Doc: map[string]any{"a": "[1]"}, Spec: "/a", Expected: []any{1}, Error: jsptr.ErrTestFailed},
+		{Name: "null", Doc: `{"a": null}`, Spec: "/a", Expected: nil},
+		{Name: "missing", Doc: `{"a": null}`, Spec: "/b", Expected: nil, Error: jsptr.ErrNotFound},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := jsptr.Test(tc.Doc, tc.Spec, tc.Expected)
+			if tc.Error != nil {
+				require.ErrorIs(t, err, tc.Error)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	newDoc := func() map[string]any {
+		om := jsptr.NewOrderedMap()
+		om.Set("x", 1.0)
+		om.Set("y", 2.0)
+		return map[string]any{
+			"a":  map[string]any{"b": []any{"c", "d", "e"}},
+			"om": om,
+		}
+	}
+
+	patch := jsptr.Patch{
+		{Op: "add", Path: "/a/b/1", Value: "inserted"},
+		{Op: "remove", Path: "/a/b/0"},
+		{Op: "move", From: "/om/x", Path: "/a/x"},
+		{Op: "copy", From: "/a", Path: "/om/a"},
+		{Op: "replace", Path: "/a/b/0", Value: "replaced"},
+		{Op: "add", Path: "/new", Value: 1.0},
+		{Op: "test", Path: "/new", Value: 2.0},
+	}
+
+	t.Run("generic values", func(t *testing.T) {
+		doc := newDoc()
+		arr := doc["a"].(map[string]any)["b"].([]any)
+		_, err := jsptr.ApplyPatch(doc, patch)
+		require.ErrorIs(t, err, jsptr.ErrTestFailed)
+		require.Equal(t, newDoc(), doc)
+		require.Equal(t, []any{"c", "d", "e"}, arr)
+	})
+	t.Run("pointer", func(t *testing.T) {
+		doc := newDoc()
+		ptr := &doc
+		_, err := jsptr.ApplyPatch(ptr, patch)
+		require.Error(t, err)
+		require.Equal(t, newDoc(), doc)
+
+		// The resulting document must have the type of the original one
+		_, err = jsptr.ApplyPatch(ptr, jsptr.Patch{
+			{Op: "remove", Path: "/a"},
+			{Op: "replace", Path: "", Value: []any{}},
+		})
+		require.Error(t, err)
+		require.Equal(t, newDoc(), doc)
+	})
+	t.Run("JSON text", func(t *testing.T) {
+		const src = `{"a":{"b":["c","d","e"]},"om":{"x":1,"y":2}}`
+		doc := []byte(src)
+		result, err := jsptr.ApplyPatch(doc, patch)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Equal(t, src, string(doc))
+	})
+	t.Run("success", func(t *testing.T) {
+		doc := newDoc()
+		result, err := jsptr.ApplyPatch(doc, patch[:len(patch)-1])
+		require.NoError(t, err)
+		buf, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"a": {"b": ["replaced", "d", "e"], "x": 1},
+			"om": {"y": 2, "a": {"b": ["inserted", "d", "e"], "x": 1}},
+			"new": 1
+		}`, string(buf))
+	})
+}
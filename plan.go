@@ -0,0 +1,211 @@
+package jsptr
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// accessPlan is a pointer compiled against a Go type. It holds the steps
+// that follow the leading tokens of the pointer through values of that
+// type using reflection alone, so that struct fields do not have to be
+// looked up by name, nor map keys converted, at every retrieval.
+//
+// A plan stops at the first value whose type is not known in advance,
+// such as an interface, or that requires special handling, such as a
+// Source or a json.Marshaler. The rest of the pointer is then evaluated
+// as usual
+//
+// Consecutive struct fields are accessed by a single step holding the
+// full index chain, so that a path made of nested struct fields is
+// followed by a single call to reflect.Value.FieldByIndexErr
+type accessPlan struct {
+	steps []planStep
+	// tokens is the number of tokens of the pointer that are consumed
+	// by the steps
+	tokens int
+}
+
+// planStep is a single step of an accessPlan
+type planStep struct {
+	kind reflect.Kind
+	// field is the index chain of the struct field to access, which may
+	// go through several levels of nested structs
+	field []int
+	// key is the key of the map element to access
+	key reflect.Value
+	// index is the index of the slice or array element to access
+	index int
+}
+
+// planKey identifies the plans of a pointer. Plans depend on the type
+// they are compiled against, and on the options that affect how struct
+// fields are found
+type planKey struct {
+	typ             reflect.Type
+	tag             string
+	caseInsensitive bool
+	quotedFields    bool
+}
+
+var (
+	orderedMapType = reflect.TypeFor[*OrderedMap]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// retrievePlanned retrieves the value pointed by p from target using the
+// access plan compiled for the type of target. It reports whether the
+// retrieval was handled: if the plan cannot be followed, for example
+// because a value along the way is missing, the caller must evaluate the
+// pointer as usual, which reports the error
+func (p *Pointer) retrievePlanned(dst, target any, cfg *retrieveConfig) (bool, error) {
+	// Traced retrievals must report every step, and plans must not
+	// be cached if caching was disabled
+	if cfg.trace != nil || cfg.noStructCache || len(p.tokens) == 0 {
+		return false, nil
+	}
+	rv := reflect.ValueOf(target)
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Map:
+	default:
+		return false, nil
+	}
+
+	plan := p.planFor(rv.Type(), cfg)
+	if len(plan.steps) == 0 {
+		return false, nil
+	}
+	v, ok := plan.follow(rv)
+	if !ok {
+		return false, nil
+	}
+
+	n := plan.tokens
+	return true, valueSource{data: v.Interface()}.retrieveTokens(dst, p.tokens[n:], cfg.within(p.tokens[:n]))
+}
+
+// planFor returns the plan of p for values of type t, compiling it the
+// first time it is needed
+func (p *Pointer) planFor(t reflect.Type, cfg *retrieveConfig) *accessPlan {
+	key := planKey{
+		typ:             t,
+		tag:             cfg.structTag(),
+		caseInsensitive: cfg.caseInsensitive,
+		quotedFields:    cfg.quotedFields,
+	}
+	if plan, ok := p.plans.Load(key); ok {
+		observeCache("plan", true)
+		return plan.(*accessPlan)
+	}
+	observeCache("plan", false)
+
+	plan, _ := p.plans.LoadOrStore(key, compilePlan(t, p.tokens, cfg))
+	return plan.(*accessPlan)
+}
+
+// compilePlan compiles the steps that follow tokens through values of
+// type t, for as long as the types of the values along the way allow it
+func compilePlan(t reflect.Type, tokens []string, cfg *retrieveConfig) *accessPlan {
+	plan := &accessPlan{}
+	for _, token := range tokens {
+		if !isPlannable(t) {
+			break
+		}
+		// FieldByIndexErr follows a single pointer to a struct at every
+		// level, so a field within a value of such a type can be accessed
+		// by extending the index chain of the previous struct step
+		chained := t.Kind() == reflect.Struct || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		var step planStep
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := getStructInfo(t, cfg).lookup(token, cfg.caseInsensitive)
+			if !ok || (field.quoted && cfg.quotedFields) {
+				return plan
+			}
+			t = t.FieldByIndex(field.index).Type
+			if n := len(plan.steps); chained && n > 0 && plan.steps[n-1].kind == reflect.Struct {
+				plan.steps[n-1].field = slices.Concat(plan.steps[n-1].field, field.index)
+				plan.tokens++
+				continue
+			}
+			step = planStep{kind: reflect.Struct, field: field.index}
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return plan
+			}
+			step = planStep{kind: reflect.Map, key: reflect.ValueOf(token).Convert(t.Key())}
+			t = t.Elem()
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 {
+				return plan
+			}
+			step = planStep{kind: t.Kind(), index: index}
+			t = t.Elem()
+		default:
+			return plan
+		}
+		plan.steps = append(plan.steps, step)
+		plan.tokens++
+	}
+	return plan
+}
+
+// isPlannable reports whether the children of values of type t can be
+// accessed by a plan, which is the case if they are always accessed in
+// the same way regardless of the actual value
+func isPlannable(t reflect.Type) bool {
+	switch {
+	case t.Kind() == reflect.Interface:
+		return false
+	case t == orderedMapType, t == rawMessageType, t == reflect.PointerTo(rawMessageType):
+		return false
+	case t.Implements(jsonMarshalerType), t.Implements(sourceType), t.Implements(getterType):
+		return false
+	case t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(sourceType):
+		return false
+	case isRegisteredType(t):
+		return false
+	}
+	return true
+}
+
+// follow applies the steps of the plan to v. It reports false if a step
+// cannot be applied, for example because a pointer is nil or a map key
+// is missing
+func (plan *accessPlan) follow(v reflect.Value) (reflect.Value, bool) {
+	for _, step := range plan.steps {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+
+		switch step.kind {
+		case reflect.Struct:
+			field, err := v.FieldByIndexErr(step.field)
+			if err != nil || !field.CanInterface() {
+				return reflect.Value{}, false
+			}
+			v = field
+		case reflect.Map:
+			elem := v.MapIndex(step.key)
+			if !elem.IsValid() {
+				return reflect.Value{}, false
+			}
+			v = elem
+		default:
+			if step.index >= v.Len() {
+				return reflect.Value{}, false
+			}
+			v = v.Index(step.index)
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,149 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+type planAddress struct {
+	City string `json:"city"`
+}
+
+type planBase struct {
+	ID int `json:"id"`
+}
+
+type planLocation struct {
+	Address  planAddress  `json:"address"`
+	Fallback *planAddress `json:"fallback"`
+}
+
+type planOffice struct {
+	*planLocation
+	Name string `json:"name"`
+}
+
+type planUser struct {
+	planBase
+	Name      string                  `json:"name"`
+	Address   *planAddress            `json:"address"`
+	Emails    []string                `json:"emails"`
+	Labels    map[string]string       `json:"labels"`
+	Extra     map[string]any          `json:"extra"`
+	Raw       json.RawMessage         `json:"raw"`
+	Created   time.Time               `json:"created"`
+	Count     int                     `json:"count,string"`
+	Neighbors map[string]*planAddress `json:"neighbors"`
+	Office    planOffice              `json:"office"`
+	Remote    *planOffice             `json:"remote"`
+}
+
+func TestAccessPlans(t *testing.T) {
+	user := &planUser{
+		planBase: planBase{ID: 7},
+		Name:     "alice",
+		Address:  &planAddress{City: "Tokyo"},
+		Emails:   []string{"a@example.com", "b@example.com"},
+		Labels:   map[string]string{"team": "core"},
+		Extra:    map[string]any{"nested": map[string]any{"value": 1.5}},
+		Raw:      json.RawMessage(`{"a": [1, 2]}`),
+		Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Count:    3,
+		Neighbors: map[string]*planAddress{
+			"left":  {City: "Osaka"},
+			"right": nil,
+		},
+		Office: planOffice{
+			planLocation: &planLocation{Address: planAddress{City: "Kyoto"}},
+			Name:         "hq",
+		},
+		Remote: &planOffice{Name: "home"},
+	}
+
+	testcases := []struct {
+		Spec     string
+		Options  []jsptr.RetrieveOption
+		Expected any
+		Error    string
+	}{
+		{Spec: "/id", Expected: 7},
+		{Spec: "/name", Expected: "alice"},
+		{Spec: "/NAME", Options: []jsptr.RetrieveOption{jsptr.WithCaseInsensitiveFields(true)}, Expected: "alice"},
+		{Spec: "/NAME", Error: "field 'NAME' not found in struct jsptr_test.planUser"},
+		{Spec: "/address/city", Expected: "Tokyo"},
+		{Spec: "/emails/1", Expected: "b@example.com"},
+		{Spec: "/emails/2", Error: "array index 2 out of bounds"},
+		{Spec: "/labels/team", Expected: "core"},
+		{Spec: "/labels/owner", Error: "property 'owner' not found"},
+		{Spec: "/extra/nested/value", Expected: 1.5},
+		{Spec: "/raw/a/1", Expected: float64(2)},
+		{Spec: "/created", Expected: user.Created},
+		{Spec: "/count", Expected: 3},
+		{Spec: "/count", Options: []jsptr.RetrieveOption{jsptr.WithQuotedFields(true)}, Expected: "3"},
+		{Spec: "/neighbors/left/city", Expected: "Osaka"},
+		{Spec: "/neighbors/right/city", Error: "cannot index into nil *jsptr_test.planAddress with 'city'"},
+		{Spec: "/office/name", Expected: "hq"},
+		{Spec: "/office/address/city", Expected: "Kyoto"},
+		{Spec: "/office/fallback/city", Error: "cannot index into nil *jsptr_test.planAddress with 'city'"},
+		{Spec: "/remote/name", Expected: "home"},
+		{Spec: "/remote/address/city", Error: "indirection through nil pointer to embedded struct field planLocation"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Spec, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Spec)
+			require.NoError(t, err)
+
+			// The first retrieval compiles the plan, and the following
+			// ones use it. Values and pointers to them have separate plans
+			for range 3 {
+				for _, target := range []any{user, *user} {
+					var v any
+					err := ptr.Retrieve(&v, target, tc.Options...)
+					if tc.Error != "" {
+						require.ErrorContains(t, err, tc.Error)
+						continue
+					}
+					require.NoError(t, err)
+					if expected, ok := tc.Expected.(int); ok {
+						require.EqualValues(t, expected, v)
+						continue
+					}
+					require.Equal(t, tc.Expected, v)
+				}
+			}
+		})
+	}
+
+	t.Run("Plans are cached", func(t *testing.T) {
+		m := newRecordingMetrics()
+		jsptr.SetMetrics(m)
+		defer jsptr.SetMetrics(nil)
+
+		ptr := jsptr.MustNew("/address/city")
+		var city string
+		for range 3 {
+			require.NoError(t, ptr.Retrieve(&city, user))
+			require.Equal(t, "Tokyo", city)
+		}
+		require.Equal(t, 1, m.cache["plan:false"])
+		require.Equal(t, 2, m.cache["plan:true"])
+
+		require.NoError(t, ptr.Retrieve(&city, user, jsptr.WithTagName("json")))
+		require.Equal(t, 3, m.cache["plan:true"])
+		require.NoError(t, ptr.Retrieve(&city, user, jsptr.WithCaseInsensitiveFields(true)))
+		require.Equal(t, 2, m.cache["plan:false"])
+	})
+	t.Run("Errors report the full location", func(t *testing.T) {
+		var v any
+		err := jsptr.MustNew("/extra/nested/missing").Retrieve(&v, user)
+		var perr *jsptr.Error
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, "/extra/nested/missing", perr.Pattern)
+		require.Equal(t, "/extra/nested", perr.Prefix)
+	})
+}
@@ -0,0 +1,121 @@
+package jsptr
+
+import (
+	"strings"
+)
+
+// PointerSet is a set of JSON pointers, stored in a trie of reference
+// tokens, so that checking whether a pointer is in the set, or is below
+// one of the pointers in the set, takes time proportional to the number of
+// its tokens, regardless of the size of the set. This makes it suitable
+// for allowlists and denylists with many entries.
+//
+// Pointers are compared by their unescaped reference tokens, so that
+// differently spelled but equivalent pointers are the same.
+//
+// The zero value is an empty set that is ready to use. A PointerSet may be
+// read concurrently, but not while pointers are being added to it.
+type PointerSet struct {
+	root pointerNode
+	size int
+}
+
+type pointerNode struct {
+	children map[string]*pointerNode
+	// member is true if the pointer that leads to the node is in the set
+	member bool
+}
+
+// NewPointerSet creates a PointerSet containing the given pointers
+func NewPointerSet(pointers ...string) (*PointerSet, error) {
+	var s PointerSet
+	for _, spec := range pointers {
+		if err := s.Add(spec); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// Add adds the pointer `spec` to the set
+func (s *PointerSet) Add(spec string) error {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return err
+	}
+
+	node := &s.root
+	for _, token := range tokens {
+		next, ok := node.children[token]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*pointerNode)
+			}
+			next = &pointerNode{}
+			node.children[token] = next
+		}
+		node = next
+	}
+	if !node.member {
+		node.member = true
+		s.size++
+	}
+	return nil
+}
+
+// Len returns the number of pointers in the set
+func (s *PointerSet) Len() int {
+	return s.size
+}
+
+// Match reports whether the pointer `candidate` is in the set. Invalid
+// pointers are never in the set.
+func (s *PointerSet) Match(candidate string) bool {
+	node, _ := s.walk(candidate, false)
+	return node != nil && node.member
+}
+
+// Covers reports whether the pointer `candidate`, or one of the pointers
+// to the values that contain its location, is in the set. For example, a
+// set containing "/a" covers "/a" and "/a/b", but not "/ab".
+func (s *PointerSet) Covers(candidate string) bool {
+	_, covered := s.walk(candidate, true)
+	return covered
+}
+
+// walk follows the tokens of spec in the trie, and returns the node that
+// it leads to, or nil if there is none. If stopAtMember is true, the walk
+// stops at the first node that is a member of the set, and the second
+// return value reports whether there was one
+func (s *PointerSet) walk(spec string, stopAtMember bool) (*pointerNode, bool) {
+	node := &s.root
+	if spec != "" && spec[0] != '/' {
+		return nil, false
+	}
+	for {
+		if stopAtMember && node.member {
+			return node, true
+		}
+		if spec == "" {
+			return node, false
+		}
+
+		// spec starts with the '/' that precedes the next token
+		spec = spec[1:]
+		var token string
+		if i := strings.IndexByte(spec, '/'); i >= 0 {
+			token, spec = spec[:i], spec[i:]
+		} else {
+			token, spec = spec, ""
+		}
+		if strings.IndexByte(token, '~') >= 0 {
+			token = unescapeToken(token)
+		}
+
+		next, ok := node.children[token]
+		if !ok {
+			return nil, false
+		}
+		node = next
+	}
+}
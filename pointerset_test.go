@@ -0,0 +1,69 @@
+package jsptr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerSet(t *testing.T) {
+	set, err := jsptr.NewPointerSet("/a", "/b/c", "/x~1y/z", "/t~0", "/a")
+	require.NoError(t, err)
+	require.Equal(t, 4, set.Len())
+
+	testcases := []struct {
+		Candidate string
+		Match     bool
+		Covers    bool
+	}{
+		{Candidate: "/a", Match: true, Covers: true},
+		{Candidate: "/a/b/c", Covers: true},
+		{Candidate: "/ab"},
+		{Candidate: "/b"},
+		{Candidate: "/b/c", Match: true, Covers: true},
+		{Candidate: "/b/c/0", Covers: true},
+		{Candidate: "/b/d"},
+		{Candidate: "/x~1y/z", Match: true, Covers: true},
+		{Candidate: "/x~1y/z/w", Covers: true},
+		{Candidate: "/x/y/z"},
+		{Candidate: "/t~0", Match: true, Covers: true},
+		{Candidate: "/t~0/~1", Covers: true},
+		{Candidate: "/t"},
+		{Candidate: ""},
+		{Candidate: "a"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Candidate, func(t *testing.T) {
+			require.Equal(t, tc.Match, set.Match(tc.Candidate), "Match")
+			require.Equal(t, tc.Covers, set.Covers(tc.Candidate), "Covers")
+		})
+	}
+
+	t.Run("root", func(t *testing.T) {
+		var set jsptr.PointerSet
+		require.Equal(t, 0, set.Len())
+		require.False(t, set.Covers("/a"))
+		require.NoError(t, set.Add(""))
+		require.True(t, set.Match(""))
+		require.False(t, set.Match("/a"))
+		require.True(t, set.Covers("/a"))
+	})
+	t.Run("invalid pointers", func(t *testing.T) {
+		_, err := jsptr.NewPointerSet("a")
+		require.Error(t, err)
+	})
+	t.Run("large sets", func(t *testing.T) {
+		var set jsptr.PointerSet
+		for i := range 10000 {
+			require.NoError(t, set.Add(fmt.Sprintf("/users/%d/email", i)))
+		}
+		require.Equal(t, 10000, set.Len())
+		require.True(t, set.Match("/users/9999/email"))
+		require.False(t, set.Match("/users/10000/email"))
+		require.Zero(t, testing.AllocsPerRun(100, func() {
+			set.Covers("/users/1234/email/domain")
+		}))
+	})
+}
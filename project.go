@@ -0,0 +1,79 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Project returns a new document that only contains the values at the
+// locations specified by the given JSON pointers, each at the same
+// location as in doc. This can be used to implement sparse fieldsets,
+// where clients select the parts of a resource that they are interested
+// in.
+//
+// doc may be JSON text as []byte or string, a *Document, or Go values,
+// which are projected through their JSON representation. The result is
+// made of map[string]any and []any. Array elements keep their indices, so
+// elements that precede a selected element without being selected
+// themselves are represented as null.
+//
+// Pointers whose location does not exist in doc are ignored. If no
+// pointer is given, or none of them exist, the result is an empty object
+// or array, depending on the kind of the document.
+func Project(doc any, pointers ...string) (any, error) {
+	root, err := genericValue(doc, defaultRetrieveConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var result any
+	switch root.(type) {
+	case map[string]any:
+		result = map[string]any{}
+	case []any:
+		result = []any{}
+	}
+	for _, spec := range pointers {
+		tokens, err := parseTokens(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := navigate(root, tokens, defaultRetrieveConfig); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to project '%s': %w", spec, err)
+		}
+		result = project(result, root, tokens)
+	}
+	return result, nil
+}
+
+// project copies the value at the location specified by tokens within
+// src, which must exist, to the same location within dst, creating the
+// containers that lead to it, and returns the resulting dst
+func project(dst, src any, tokens []string) any {
+	if len(tokens) == 0 {
+		return src
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	switch src := src.(type) {
+	case map[string]any:
+		m, ok := dst.(map[string]any)
+		if !ok {
+			m = make(map[string]any)
+		}
+		m[token] = project(m[token], src[token], rest)
+		return m
+	case []any:
+		index, _ := parseIndex(token, len(src))
+		arr, _ := dst.([]any)
+		for len(arr) <= index {
+			arr = append(arr, nil)
+		}
+		arr[index] = project(arr[index], src[index], rest)
+		return arr
+	}
+	return src
+}
@@ -0,0 +1,107 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProject(t *testing.T) {
+	const doc = `{
+		"id": 1,
+		"name": "a",
+		"owner": {"id": 2, "name": "b", "email": "b@example.com"},
+		"items": [{"id": 3, "price": 10}, {"id": 4, "price": 20}, {"id": 5, "price": 30}]
+	}`
+
+	testcases := []struct {
+		Name     string
+		Doc      any
+		Pointers []string
+		Expected any
+		Error    bool
+	}{
+		{
+			Name:     "members",
+			Doc:      doc,
+			Pointers: []string{"/id", "/owner/name"},
+			Expected: map[string]any{"id": 1.0, "owner": map[string]any{"name": "b"}},
+		},
+		{
+			Name:     "array elements keep their indices",
+			Doc:      doc,
+			Pointers: []string{"/items/1/id", "/items/0/price"},
+			Expected: map[string]any{"items": []any{map[string]any{"price": 10.0}, map[string]any{"id": 4.0}}},
+		},
+		{
+			Name:     "skipped array elements",
+			Doc:      doc,
+			Pointers: []string{"/items/2/id"},
+			Expected: map[string]any{"items": []any{nil, nil, map[string]any{"id": 5.0}}},
+		},
+		{
+			Name:     "overlapping pointers",
+			Doc:      doc,
+			Pointers: []string{"/owner/id", "/owner", "/owner/name"},
+			Expected: map[string]any{"owner": map[string]any{"id": 2.0, "name": "b", "email": "b@example.com"}},
+		},
+		{
+			Name:     "missing locations",
+			Doc:      doc,
+			Pointers: []string{"/missing", "/items/10", "/name"},
+			Expected: map[string]any{"name": "a"},
+		},
+		{
+			Name:     "no pointers",
+			Doc:      []byte(`[1, 2]`),
+			Expected: []any{},
+		},
+		{
+			Name:     "whole document",
+			Doc:      []byte(`[1, 2]`),
+			Pointers: []string{""},
+			Expected: []any{1.0, 2.0},
+		},
+		{
+			Name: "Go values",
+			Doc: struct {
+				ID     int    `json:"id"`
+				Secret string `json:"secret"`
+			}{ID: 1, Secret: "s"},
+			Pointers: []string{"/id"},
+			Expected: map[string]any{"id": 1.0},
+		},
+		{
+			Name:     "invalid pointer",
+			Doc:      doc,
+			Pointers: []string{"id"},
+			Error:    true,
+		},
+		{
+			Name:     "scalar",
+			Doc:      doc,
+			Pointers: []string{"/id/x"},
+			Error:    true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := jsptr.Project(tc.Doc, tc.Pointers...)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, result)
+		})
+	}
+
+	t.Run("doc is not modified", func(t *testing.T) {
+		src := map[string]any{"a": map[string]any{"b": 1.0, "c": 2.0}}
+		result, err := jsptr.Project(src, "/a/b")
+		require.NoError(t, err)
+		result.(map[string]any)["a"].(map[string]any)["d"] = 3.0
+		require.Equal(t, map[string]any{"a": map[string]any{"b": 1.0, "c": 2.0}}, src)
+	})
+}
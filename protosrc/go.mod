@@ -0,0 +1,20 @@
+module github.com/lestrrat-go/jsptr/protosrc
+
+go 1.24.4
+
+require (
+	github.com/lestrrat-go/jsptr v0.0.0
+	github.com/stretchr/testify v1.10.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/lestrrat-go/blackmagic v1.0.4 // indirect
+	github.com/lestrrat-go/option v1.0.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/valyala/fastjson v1.6.4 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/lestrrat-go/jsptr => ../
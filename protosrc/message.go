@@ -0,0 +1,349 @@
+package protosrc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/lestrrat-go/jsptr"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Message is a jsptr.Source backed by an arbitrary proto.Message.
+//
+// Fields are addressed by their protojson names (e.g. "displayName" for
+// a field declared as display_name), although the original field names
+// are accepted as well, just like protojson.Unmarshal does. Repeated
+// fields are treated as JSON arrays, and map fields as JSON objects whose
+// member names are the string representations of the map keys.
+// Fields that track presence, such as message fields, optional fields and
+// members of oneofs, are reported as missing (see jsptr.ErrNotFound) when
+// they are not set. Fields holding google.protobuf.Struct, Value and
+// ListValue messages are traversed like JSON values.
+//
+// Messages, including those found in repeated and map fields, are
+// converted using their protojson representation before being assigned
+// to destinations, unless the destination is of the same message type.
+// Scalar values are assigned as Go values, with enums represented by
+// the names of their values.
+type Message struct {
+	m proto.Message
+}
+
+// NewMessage creates a new Source for the given message
+func NewMessage(m proto.Message) *Message {
+	return &Message{m: m}
+}
+
+// RetrieveJSONPointer retrieves the value at the location specified by
+// ptrspec, and assigns it to dst
+func (s *Message) RetrieveJSONPointer(dst any, ptrspec string) error {
+	tokens, err := parseTokens(ptrspec)
+	if err != nil {
+		return err
+	}
+
+	current := protoNode{v: protoreflect.ValueOfMessage(s.m.ProtoReflect())}
+	for i, token := range tokens {
+		// JSON-like well-known types are delegated to the structpb navigator
+		if v, ok := current.structValue(); ok {
+			found, err := navigateValue(v, tokens[i:])
+			if err != nil {
+				return err
+			}
+			return assignValue(dst, found)
+		}
+
+		next, err := current.child(token)
+		if err != nil {
+			return err
+		}
+		current = next
+	}
+	return current.assign(dst)
+}
+
+// protoNode is a value reached while navigating a message
+type protoNode struct {
+	v protoreflect.Value
+	// fd describes the field that v was read from. It is nil for the
+	// root message
+	fd protoreflect.FieldDescriptor
+	// elem is true if v is an element of the repeated field fd, rather
+	// than the list itself
+	elem bool
+}
+
+type nodeKind int
+
+const (
+	nodeScalar nodeKind = iota
+	nodeMessage
+	nodeList
+	nodeMap
+)
+
+func (n protoNode) kind() nodeKind {
+	switch {
+	case n.fd == nil:
+		return nodeMessage
+	case n.fd.IsList() && !n.elem:
+		return nodeList
+	case n.fd.IsMap():
+		return nodeMap
+	case n.fd.Kind() == protoreflect.MessageKind || n.fd.Kind() == protoreflect.GroupKind:
+		return nodeMessage
+	default:
+		return nodeScalar
+	}
+}
+
+// structValue returns n as a structpb.Value, if n holds one of the
+// google.protobuf.Struct, Value or ListValue messages
+func (n protoNode) structValue() (*structpb.Value, bool) {
+	if n.kind() != nodeMessage {
+		return nil, false
+	}
+	msg := n.v.Message().Interface()
+	switch m := msg.(type) {
+	case *structpb.Value:
+		return m, true
+	case *structpb.Struct:
+		return structpb.NewStructValue(m), true
+	case *structpb.ListValue:
+		return structpb.NewListValue(m), true
+	}
+
+	// Messages that are not backed by the generated types, such as those
+	// created with dynamicpb, are converted to them
+	var v *structpb.Value
+	switch msg.ProtoReflect().Descriptor().FullName() {
+	case "google.protobuf.Value":
+		v = &structpb.Value{}
+		if !convertMessage(msg, v) {
+			return nil, false
+		}
+	case "google.protobuf.Struct":
+		var s structpb.Struct
+		if !convertMessage(msg, &s) {
+			return nil, false
+		}
+		v = structpb.NewStructValue(&s)
+	case "google.protobuf.ListValue":
+		var l structpb.ListValue
+		if !convertMessage(msg, &l) {
+			return nil, false
+		}
+		v = structpb.NewListValue(&l)
+	default:
+		return nil, false
+	}
+	return v, true
+}
+
+// convertMessage copies src into dst, which must be a message of the same
+// type, by round-tripping it through the wire format
+func convertMessage(src, dst proto.Message) bool {
+	buf, err := proto.Marshal(src)
+	if err != nil {
+		return false
+	}
+	return proto.Unmarshal(buf, dst) == nil
+}
+
+// child returns the node referred to by token within n
+func (n protoNode) child(token string) (protoNode, error) {
+	switch n.kind() {
+	case nodeMessage:
+		msg := n.v.Message()
+		fields := msg.Descriptor().Fields()
+		fd := fields.ByJSONName(token)
+		if fd == nil {
+			fd = fields.ByTextName(token)
+		}
+		if fd == nil {
+			return protoNode{}, fmt.Errorf("field '%s' not found in message %s: %w", token, msg.Descriptor().FullName(), jsptr.ErrNotFound)
+		}
+		if fd.HasPresence() && !msg.Has(fd) {
+			return protoNode{}, fmt.Errorf("field '%s' is not set in message %s: %w", token, msg.Descriptor().FullName(), jsptr.ErrNotFound)
+		}
+		return protoNode{v: msg.Get(fd), fd: fd}, nil
+	case nodeList:
+		list := n.v.List()
+		index, err := parseIndex(token, list.Len())
+		if err != nil {
+			return protoNode{}, err
+		}
+		return protoNode{v: list.Get(index), fd: n.fd, elem: true}, nil
+	case nodeMap:
+		key, err := parseMapKey(token, n.fd.MapKey())
+		if err != nil {
+			return protoNode{}, err
+		}
+		m := n.v.Map()
+		if !m.Has(key) {
+			return protoNode{}, fmt.Errorf("key '%s' not found: %w", token, jsptr.ErrNotFound)
+		}
+		return protoNode{v: m.Get(key), fd: n.fd.MapValue()}, nil
+	default:
+		return protoNode{}, fmt.Errorf("cannot index into scalar field '%s' with '%s'", n.fd.FullName(), token)
+	}
+}
+
+// parseMapKey converts token into a key of the map whose keys are
+// described by fd
+func parseMapKey(token string, fd protoreflect.FieldDescriptor) (protoreflect.MapKey, error) {
+	var v protoreflect.Value
+	var err error
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		v = protoreflect.ValueOfString(token)
+	case protoreflect.BoolKind:
+		var b bool
+		b, err = strconv.ParseBool(token)
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var i int64
+		i, err = strconv.ParseInt(token, 10, 32)
+		v = protoreflect.ValueOfInt32(int32(i))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var i int64
+		i, err = strconv.ParseInt(token, 10, 64)
+		v = protoreflect.ValueOfInt64(i)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var u uint64
+		u, err = strconv.ParseUint(token, 10, 32)
+		v = protoreflect.ValueOfUint32(uint32(u))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var u uint64
+		u, err = strconv.ParseUint(token, 10, 64)
+		v = protoreflect.ValueOfUint64(u)
+	default:
+		return protoreflect.MapKey{}, fmt.Errorf("unsupported map key kind %s", fd.Kind())
+	}
+	if err != nil {
+		return protoreflect.MapKey{}, fmt.Errorf("invalid %s map key '%s': %w", fd.Kind(), token, jsptr.ErrNotFound)
+	}
+	return v.MapKey(), nil
+}
+
+// assign assigns the value of n to dst
+func (n protoNode) assign(dst any) error {
+	if v, ok := n.structValue(); ok {
+		return assignValue(dst, v)
+	}
+	if n.kind() == nodeMessage && assignMessage(dst, n.v.Message().Interface()) {
+		return nil
+	}
+
+	v, err := n.export()
+	if err != nil {
+		return err
+	}
+	return jsptr.Assign(dst, v)
+}
+
+// assignMessage assigns msg to dst, if dst can hold a message of the same
+// type. It reports whether the assignment was done
+func assignMessage(dst any, msg proto.Message) bool {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false
+	}
+
+	// A pointer to a variable of the message type receives the message
+	// itself
+	if rv.Elem().Type() == reflect.TypeOf(msg) {
+		rv.Elem().Set(reflect.ValueOf(msg))
+		return true
+	}
+
+	// Otherwise, a message of the same type (or a pointer to a variable
+	// that can hold one) receives a copy
+	target, ok := dst.(proto.Message)
+	allocated := false
+	if !ok {
+		typ := rv.Elem().Type()
+		if typ.Kind() != reflect.Ptr {
+			return false
+		}
+		if target, ok = reflect.New(typ.Elem()).Interface().(proto.Message); !ok {
+			return false
+		}
+		allocated = true
+	}
+	if target.ProtoReflect().Descriptor().FullName() != msg.ProtoReflect().Descriptor().FullName() {
+		return false
+	}
+
+	proto.Reset(target)
+	if !convertMessage(msg, target) {
+		return false
+	}
+	if allocated {
+		rv.Elem().Set(reflect.ValueOf(target))
+	}
+	return true
+}
+
+// export converts n to a Go value
+func (n protoNode) export() (any, error) {
+	switch n.kind() {
+	case nodeMessage:
+		if v, ok := n.structValue(); ok {
+			return v.AsInterface(), nil
+		}
+		buf, err := protojson.Marshal(n.v.Message().Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
+		var v any
+		if err := root.Retrieve(&v, buf); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case nodeList:
+		list := n.v.List()
+		values := make([]any, list.Len())
+		for i := range values {
+			v, err := protoNode{v: list.Get(i), fd: n.fd, elem: true}.export()
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	case nodeMap:
+		values := make(map[string]any, n.v.Map().Len())
+		var err error
+		n.v.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			var v any
+			v, err = protoNode{v: value, fd: n.fd.MapValue()}.export()
+			if err != nil {
+				return false
+			}
+			values[key.String()] = v
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	if n.fd.Kind() == protoreflect.EnumKind {
+		// Like protojson, google.protobuf.NullValue is represented as null
+		if n.fd.Enum().FullName() == "google.protobuf.NullValue" {
+			return nil, nil
+		}
+		number := n.v.Enum()
+		if value := n.fd.Enum().Values().ByNumber(number); value != nil {
+			return string(value.Name()), nil
+		}
+		return int32(number), nil
+	}
+	return n.v.Interface(), nil
+}
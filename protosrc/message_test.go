@@ -0,0 +1,258 @@
+package protosrc_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/lestrrat-go/jsptr/protosrc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// userDescriptor builds the descriptor for the following message:
+//
+//	enum Role { ROLE_UNSPECIFIED = 0; ROLE_ADMIN = 1; }
+//	message Address { string city = 1; }
+//	message User {
+//	  string display_name = 1;
+//	  int64 id = 2;
+//	  repeated Address addresses = 3;
+//	  map<string, string> labels = 4;
+//	  map<int32, Address> by_code = 5;
+//	  Address primary = 6;
+//	  Role role = 7;
+//	  google.protobuf.Struct extra = 8;
+//	  google.protobuf.Timestamp created = 9;
+//	  optional string nickname = 10;
+//	}
+func userDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return f
+	}
+	mapEntry := func(name string, key descriptorpb.FieldDescriptorProto_Type, value descriptorpb.FieldDescriptorProto_Type, valueTypeName string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name: proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("key", 1, key, ""),
+				field("value", 2, value, valueTypeName),
+			},
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+	}
+
+	const (
+		typeString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		typeInt32   = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		typeInt64   = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		typeMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		typeEnum    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	)
+
+	nickname := field("nickname", 10, typeString, "")
+	nickname.Proto3Optional = proto.Bool(true)
+	nickname.OneofIndex = proto.Int32(0)
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("jsptr_test/user.proto"),
+		Package:    proto.String("jsptr.test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/struct.proto", "google/protobuf/timestamp.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Role"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("ROLE_UNSPECIFIED"), Number: proto.Int32(0)},
+					{Name: proto.String("ROLE_ADMIN"), Number: proto.Int32(1)},
+				},
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("city", 1, typeString, "")},
+			},
+			{
+				Name: proto.String("User"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("display_name", 1, typeString, ""),
+					field("id", 2, typeInt64, ""),
+					repeated(field("addresses", 3, typeMessage, ".jsptr.test.Address")),
+					repeated(field("labels", 4, typeMessage, ".jsptr.test.User.LabelsEntry")),
+					repeated(field("by_code", 5, typeMessage, ".jsptr.test.User.ByCodeEntry")),
+					field("primary", 6, typeMessage, ".jsptr.test.Address"),
+					field("role", 7, typeEnum, ".jsptr.test.Role"),
+					field("extra", 8, typeMessage, ".google.protobuf.Struct"),
+					field("created", 9, typeMessage, ".google.protobuf.Timestamp"),
+					nickname,
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					mapEntry("LabelsEntry", typeString, typeString, ""),
+					mapEntry("ByCodeEntry", typeInt32, typeMessage, ".jsptr.test.Address"),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("_nickname")}},
+			},
+		},
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	return fd.Messages().ByName("User")
+}
+
+func TestMessage(t *testing.T) {
+	// Make sure that the well-known types are linked in
+	_ = timestamppb.Now()
+
+	user := dynamicpb.NewMessage(userDescriptor(t))
+	require.NoError(t, protojson.Unmarshal([]byte(`{
+		"displayName": "Alice",
+		"id": "42",
+		"addresses": [{"city": "Tokyo"}, {"city": "Osaka"}],
+		"labels": {"team": "core"},
+		"byCode": {"81": {"city": "Kyoto"}},
+		"role": "ROLE_ADMIN",
+		"extra": {"tags": ["a", "b"], "score": 1.5},
+		"created": "2024-01-02T03:04:05Z"
+	}`), user))
+	source := protosrc.NewMessage(user)
+
+	testcases := []struct {
+		Pointer  string
+		Want     any
+		Error    bool
+		NotFound bool
+	}{
+		{Pointer: "/displayName", Want: "Alice"},
+		{Pointer: "/display_name", Want: "Alice"},
+		{Pointer: "/id", Want: int64(42)},
+		{Pointer: "/addresses/1/city", Want: "Osaka"},
+		{Pointer: "/addresses/0", Want: map[string]any{"city": "Tokyo"}},
+		{Pointer: "/addresses", Want: []any{map[string]any{"city": "Tokyo"}, map[string]any{"city": "Osaka"}}},
+		{Pointer: "/labels/team", Want: "core"},
+		{Pointer: "/byCode/81/city", Want: "Kyoto"},
+		{Pointer: "/role", Want: "ROLE_ADMIN"},
+		{Pointer: "/extra/tags/1", Want: "b"},
+		{Pointer: "/extra/score", Want: 1.5},
+		{Pointer: "/created", Want: "2024-01-02T03:04:05Z"},
+		{Pointer: "/unknown", Error: true, NotFound: true},
+		{Pointer: "/primary/city", Error: true, NotFound: true},
+		{Pointer: "/nickname", Error: true, NotFound: true},
+		{Pointer: "/addresses/2", Error: true, NotFound: true},
+		{Pointer: "/labels/missing", Error: true, NotFound: true},
+		{Pointer: "/byCode/abc", Error: true, NotFound: true},
+		{Pointer: "/extra/missing", Error: true, NotFound: true},
+		{Pointer: "/id/x", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			err = ptr.Retrieve(&v, source)
+			if tc.Error {
+				require.Error(t, err)
+				if tc.NotFound {
+					require.ErrorIs(t, err, jsptr.ErrNotFound)
+				} else {
+					require.NotErrorIs(t, err, jsptr.ErrNotFound)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Want, v)
+		})
+	}
+
+	t.Run("same pointers against the protojson representation", func(t *testing.T) {
+		buf, err := protojson.Marshal(user)
+		require.NoError(t, err)
+
+		for _, spec := range []string{"/displayName", "/addresses/1/city", "/byCode/81/city", "/role", "/extra/tags/0", "/created"} {
+			ptr, err := jsptr.New(spec)
+			require.NoError(t, err)
+
+			var fromMessage, fromJSON any
+			require.NoError(t, ptr.Retrieve(&fromMessage, source), spec)
+			require.NoError(t, ptr.Retrieve(&fromJSON, buf), spec)
+			require.Equal(t, fromJSON, fromMessage, spec)
+		}
+	})
+
+	t.Run("message destinations", func(t *testing.T) {
+		ptr, err := jsptr.New("/extra")
+		require.NoError(t, err)
+
+		var extra *structpb.Struct
+		require.NoError(t, ptr.Retrieve(&extra, source))
+		require.Equal(t, []any{"a", "b"}, extra.AsMap()["tags"])
+
+		ptr, err = jsptr.New("/created")
+		require.NoError(t, err)
+
+		var created timestamppb.Timestamp
+		require.NoError(t, ptr.Retrieve(&created, source))
+		require.Equal(t, int64(1704164645), created.GetSeconds())
+
+		ptr, err = jsptr.New("/addresses/0")
+		require.NoError(t, err)
+
+		var address struct {
+			City string `json:"city"`
+		}
+		require.NoError(t, ptr.Retrieve(&address, source))
+		require.Equal(t, "Tokyo", address.City)
+	})
+}
+
+func TestMessageGenerated(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name: proto.String("example.proto"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Example")},
+		},
+	}
+	source := protosrc.NewMessage(file)
+
+	ptr, err := jsptr.New("/messageType/0/name")
+	require.NoError(t, err)
+
+	var name string
+	require.NoError(t, ptr.Retrieve(&name, source))
+	require.Equal(t, "Example", name)
+
+	ptr, err = jsptr.New("/messageType/0")
+	require.NoError(t, err)
+
+	var msg *descriptorpb.DescriptorProto
+	require.NoError(t, ptr.Retrieve(&msg, source))
+	require.Same(t, file.GetMessageType()[0], msg)
+
+	ptr, err = jsptr.New("/package")
+	require.NoError(t, err)
+	require.ErrorIs(t, ptr.Retrieve(&name, source), jsptr.ErrNotFound, "unset proto2 fields are missing")
+}
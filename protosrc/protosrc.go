@@ -0,0 +1,34 @@
+// Package protosrc provides jsptr.Source implementations for protocol
+// buffer values, so that JSON pointers can be resolved against them
+// without converting them to map[string]any first.
+package protosrc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lestrrat-go/jsptr"
+)
+
+// root is used to convert JSON encoded values
+var root = jsptr.MustNew("")
+
+// parseTokens parses a pointer specification into its reference tokens
+func parseTokens(ptrspec string) ([]string, error) {
+	ptr, err := jsptr.New(ptrspec)
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Tokens(), nil
+}
+
+func parseIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index '%s'", token)
+	}
+	if index < 0 || index >= length {
+		return 0, fmt.Errorf("array index %d out of bounds: %w", index, jsptr.ErrNotFound)
+	}
+	return index, nil
+}
@@ -0,0 +1,94 @@
+package protosrc
+
+import (
+	"fmt"
+
+	"github.com/lestrrat-go/jsptr"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Value is a jsptr.Source backed by a structpb.Value. Struct values are
+// treated as JSON objects, and ListValue values as JSON arrays.
+type Value struct {
+	v *structpb.Value
+}
+
+// NewValue creates a new Source for the given structpb.Value
+func NewValue(v *structpb.Value) *Value {
+	return &Value{v: v}
+}
+
+// NewStruct creates a new Source for the given structpb.Struct
+func NewStruct(s *structpb.Struct) *Value {
+	return &Value{v: structpb.NewStructValue(s)}
+}
+
+// NewList creates a new Source for the given structpb.ListValue
+func NewList(l *structpb.ListValue) *Value {
+	return &Value{v: structpb.NewListValue(l)}
+}
+
+// RetrieveJSONPointer retrieves the value at the location specified by
+// ptrspec, and assigns it to dst.
+//
+// If dst is a **structpb.Value, the value is assigned as is. The same
+// applies to **structpb.Struct and **structpb.ListValue, if the value
+// is an object or an array, respectively. Otherwise the value is
+// converted using (*structpb.Value).AsInterface before being assigned.
+func (s *Value) RetrieveJSONPointer(dst any, ptrspec string) error {
+	tokens, err := parseTokens(ptrspec)
+	if err != nil {
+		return err
+	}
+
+	v, err := navigateValue(s.v, tokens)
+	if err != nil {
+		return err
+	}
+	return assignValue(dst, v)
+}
+
+// navigateValue follows tokens starting from v
+func navigateValue(v *structpb.Value, tokens []string) (*structpb.Value, error) {
+	current := v
+	for _, token := range tokens {
+		switch kind := current.GetKind().(type) {
+		case *structpb.Value_StructValue:
+			next, ok := kind.StructValue.GetFields()[token]
+			if !ok {
+				return nil, fmt.Errorf("property '%s' not found: %w", token, jsptr.ErrNotFound)
+			}
+			current = next
+		case *structpb.Value_ListValue:
+			values := kind.ListValue.GetValues()
+			index, err := parseIndex(token, len(values))
+			if err != nil {
+				return nil, err
+			}
+			current = values[index]
+		default:
+			return nil, fmt.Errorf("cannot index into scalar value %T with '%s'", current.AsInterface(), token)
+		}
+	}
+	return current, nil
+}
+
+// assignValue assigns v to dst, either as is or converted to a Go value
+func assignValue(dst any, v *structpb.Value) error {
+	switch dst := dst.(type) {
+	case **structpb.Value:
+		*dst = v
+		return nil
+	case **structpb.Struct:
+		if s := v.GetStructValue(); s != nil {
+			*dst = s
+			return nil
+		}
+	case **structpb.ListValue:
+		if l := v.GetListValue(); l != nil {
+			*dst = l
+			return nil
+		}
+	}
+	return jsptr.Assign(dst, v.AsInterface())
+}
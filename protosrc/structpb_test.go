@@ -0,0 +1,102 @@
+package protosrc_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/lestrrat-go/jsptr/protosrc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestStructValue(t *testing.T) {
+	payload, err := structpb.NewStruct(map[string]any{
+		"user": map[string]any{
+			"name":  "alice",
+			"roles": []any{"admin", "dev"},
+			"age":   30,
+		},
+		"a/b":    true,
+		"absent": nil,
+	})
+	require.NoError(t, err)
+	source := protosrc.NewStruct(payload)
+
+	testcases := []struct {
+		Pointer  string
+		Want     any
+		Error    bool
+		NotFound bool
+	}{
+		{Pointer: "/user/name", Want: "alice"},
+		{Pointer: "/user/roles/1", Want: "dev"},
+		{Pointer: "/user/age", Want: 30.0},
+		{Pointer: "/user/roles", Want: []any{"admin", "dev"}},
+		{Pointer: "/a~1b", Want: true},
+		{Pointer: "/absent", Want: nil},
+		{Pointer: "/user/email", Error: true, NotFound: true},
+		{Pointer: "/user/roles/2", Error: true, NotFound: true},
+		{Pointer: "/user/roles/x", Error: true},
+		{Pointer: "/user/name/first", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var v any
+			err = ptr.Retrieve(&v, source)
+			if tc.Error {
+				require.Error(t, err)
+				if tc.NotFound {
+					require.ErrorIs(t, err, jsptr.ErrNotFound)
+				} else {
+					require.NotErrorIs(t, err, jsptr.ErrNotFound)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Want, v)
+		})
+	}
+
+	t.Run("typed destinations", func(t *testing.T) {
+		ptr, err := jsptr.New("/user")
+		require.NoError(t, err)
+
+		var user struct {
+			Name  string   `json:"name"`
+			Roles []string `json:"roles"`
+		}
+		require.NoError(t, ptr.Retrieve(&user, source))
+		require.Equal(t, "alice", user.Name)
+		require.Equal(t, []string{"admin", "dev"}, user.Roles)
+
+		var s *structpb.Struct
+		require.NoError(t, ptr.Retrieve(&s, source))
+		require.True(t, proto.Equal(payload.GetFields()["user"].GetStructValue(), s))
+	})
+
+	t.Run("nested in Go values", func(t *testing.T) {
+		ptr, err := jsptr.New("/payload/user/roles/0")
+		require.NoError(t, err)
+
+		var v string
+		require.NoError(t, ptr.Retrieve(&v, map[string]any{"payload": source}))
+		require.Equal(t, "admin", v)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		list, err := structpb.NewList([]any{1, "two"})
+		require.NoError(t, err)
+
+		ptr, err := jsptr.New("/1")
+		require.NoError(t, err)
+
+		var v string
+		require.NoError(t, ptr.Retrieve(&v, protosrc.NewList(list)))
+		require.Equal(t, "two", v)
+	})
+}
@@ -0,0 +1,54 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Prune removes the values at the locations specified by the given JSON
+// pointers from doc, and returns the resulting document. This is the
+// inverse of Project, and can be used to strip internal fields from a
+// document before returning it to clients.
+//
+// All pointers refer to locations in doc as it was before anything was
+// removed: pruning "/items/0" and "/items/1" removes the first two
+// elements of the array. Pointers whose location does not exist in doc
+// are ignored.
+//
+// The same types of documents as those accepted by Set can be used, and
+// the same caveats apply.
+func Prune(doc any, pointers ...string) (any, error) {
+	paths := make([][]string, 0, len(pointers))
+	for _, spec := range pointers {
+		tokens, err := parseTokens(spec)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, tokens)
+	}
+
+	// Removing array elements shifts the ones that follow, so locations
+	// are removed from the last to the first, and descendants are removed
+	// before their ancestors
+	slices.SortFunc(paths, func(a, b []string) int {
+		return -comparePaths(a, b)
+	})
+	paths = slices.CompactFunc(paths, func(a, b []string) bool {
+		return comparePaths(a, b) == 0
+	})
+
+	return edit(doc, func(ed editor, root any) (any, error) {
+		for _, tokens := range paths {
+			result, err := ed.mutate(root, tokens, editRemove, nil)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to prune '%s': %w", joinTokens(tokens), err)
+			}
+			root = result
+		}
+		return root, nil
+	})
+}
@@ -0,0 +1,73 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrune(t *testing.T) {
+	testcases := []struct {
+		Name     string
+		Doc      string
+		Pointers []string
+		Expected string
+		Error    bool
+	}{
+		{
+			Name:     "members",
+			Doc:      `{"id": 1, "password": "x", "owner": {"name": "a", "token": "y"}}`,
+			Pointers: []string{"/password", "/owner/token"},
+			Expected: `{"id": 1, "owner": {"name": "a"}}`,
+		},
+		{
+			Name:     "array elements refer to the original document",
+			Doc:      `{"items": [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11]}`,
+			Pointers: []string{"/items/2", "/items/10", "/items/0", "/items/9", "/items/2"},
+			Expected: `{"items": [1, 3, 4, 5, 6, 7, 8, 11]}`,
+		},
+		{
+			Name:     "ancestors and descendants",
+			Doc:      `{"a": {"b": {"c": 1}}, "d": [{"e": 1}, {"e": 2}]}`,
+			Pointers: []string{"/a", "/a/b/c", "/d/0/e", "/d/0"},
+			Expected: `{"d": [{"e": 2}]}`,
+		},
+		{
+			Name:     "missing locations",
+			Doc:      `{"a": 1, "b": [1]}`,
+			Pointers: []string{"/x", "/b/5", "/a"},
+			Expected: `{"b": [1]}`,
+		},
+		{
+			Name:     "root",
+			Doc:      `{"a": 1}`,
+			Pointers: []string{""},
+			Error:    true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := jsptr.Prune(tc.Doc, tc.Pointers...)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tc.Expected, result.(string))
+
+			// Generic documents produce the same results
+			doc := decodeJSON(t, tc.Doc)
+			result, err = jsptr.Prune(doc, tc.Pointers...)
+			require.NoError(t, err)
+			require.Equal(t, decodeJSON(t, tc.Expected), result)
+		})
+	}
+
+	t.Run("failures leave the document unchanged", func(t *testing.T) {
+		doc := map[string]any{"a": 1.0, "b": "x"}
+		_, err := jsptr.Prune(doc, "/a", "/b/c")
+		require.Error(t, err)
+		require.Equal(t, map[string]any{"a": 1.0, "b": "x"}, doc)
+	})
+}
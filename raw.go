@@ -0,0 +1,368 @@
+package jsptr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/valyala/fastjson"
+)
+
+// RetrieveRaw returns the JSON encoded bytes of the value at the JSON
+// pointer location.
+//
+// If the target is JSON bytes (a []byte, string, or a *Document), the
+// bytes of the addressed subtree are returned exactly as they appear in
+// the original document, including any whitespace within the subtree.
+// For all other targets the retrieved value is encoded using encoding/json.
+//
+// Retrieving into a *json.RawMessage using Retrieve has the same effect
+// when the target is JSON bytes.
+func (p *Pointer) RetrieveRaw(target any) ([]byte, error) {
+	return p.AppendRaw(nil, target)
+}
+
+// AppendRaw is like RetrieveRaw, but appends the bytes of the value to dst
+// and returns the extended buffer. Reusing dst across calls avoids
+// allocating a new buffer for every value.
+func (p *Pointer) AppendRaw(dst []byte, target any) ([]byte, error) {
+	var data []byte
+	switch v := target.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case *Document:
+		return v.appendRaw(dst, p.tokens)
+	default:
+		var value any
+		if err := p.Retrieve(&value, target); err != nil {
+			return nil, err
+		}
+		return appendEncoded(dst, value)
+	}
+
+	if err := fastjson.ValidateBytes(data); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	raw, err := locateRaw(data, p.tokens, defaultRetrieveConfig)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, raw...), nil
+}
+
+// RetrieveRaw returns the bytes of the value at the location specified by
+// the JSON pointer `spec`, exactly as they appear in the document
+func (d *Document) RetrieveRaw(spec string) ([]byte, error) {
+	return d.AppendRaw(nil, spec)
+}
+
+// AppendRaw appends the bytes of the value at the location specified by
+// the JSON pointer `spec` to dst, and returns the extended buffer
+func (d *Document) AppendRaw(dst []byte, spec string) ([]byte, error) {
+	tokens, err := parseTokens(spec)
+	if err != nil {
+		return nil, err
+	}
+	return d.appendRaw(dst, tokens)
+}
+
+func (d *Document) appendRaw(dst []byte, tokens []string) ([]byte, error) {
+	raw, err := locateRaw(d.src.data, tokens, defaultRetrieveConfig)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, raw...), nil
+}
+
+// maxPooledBuffer is the largest buffer that is returned to bufferPool,
+// so that a single large value does not keep its memory alive
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers that values are encoded into before they
+// are appended to the caller's buffer
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// appendEncoded appends the JSON encoding of v to dst, in the same form
+// as json.Marshal
+func appendEncoded(dst []byte, v any) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode terminates the value with a newline, which json.Marshal does not
+	return append(dst, bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...), nil
+}
+
+// locateRaw finds the value addressed by tokens in data, and returns the
+// slice of data that holds it. data must be valid JSON.
+func locateRaw(data []byte, tokens []string, cfg *retrieveConfig) ([]byte, error) {
+	sc := rawScanner{data: data}
+	sc.skipWhitespace()
+	for i, token := range tokens {
+		kind := sc.jsonKind()
+		var err error
+		switch kind {
+		case KindObject:
+			err = sc.seekMember(token)
+		case KindArray:
+			err = sc.seekElement(token)
+		default:
+			err = fmt.Errorf("cannot index into %s with '%s'", sc.kind(), token)
+		}
+		if cfg.trace != nil {
+			cfg.traceStep(tokens, i, kind, err)
+		}
+		if err != nil {
+			return nil, cfg.stepError(tokens, i, err)
+		}
+	}
+
+	start := sc.pos
+	if err := sc.skipValue(); err != nil {
+		return nil, err
+	}
+	return data[start:sc.pos], nil
+}
+
+// rawScanner is a minimal JSON scanner that is used to find the location
+// of values without decoding the document
+type rawScanner struct {
+	data []byte
+	pos  int
+}
+
+func (sc *rawScanner) peek() byte {
+	if sc.pos >= len(sc.data) {
+		return 0
+	}
+	return sc.data[sc.pos]
+}
+
+func (sc *rawScanner) skipWhitespace() {
+	for sc.pos < len(sc.data) {
+		switch sc.data[sc.pos] {
+		case ' ', '\t', '\r', '\n':
+			sc.pos++
+		default:
+			return
+		}
+	}
+}
+
+// kind returns the name of the type of the value at the current position,
+// using the same names as fastjson
+func (sc *rawScanner) kind() string {
+	switch c := sc.peek(); {
+	case c == '{':
+		return "object"
+	case c == '[':
+		return "array"
+	case c == '"':
+		return "string"
+	case c == 't':
+		return "true"
+	case c == 'f':
+		return "false"
+	case c == 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// jsonKind returns the Kind of the value at the current position
+func (sc *rawScanner) jsonKind() Kind {
+	sc.skipWhitespace()
+	switch c := sc.peek(); {
+	case c == '{':
+		return KindObject
+	case c == '[':
+		return KindArray
+	case c == '"':
+		return KindString
+	case c == 't' || c == 'f':
+		return KindBool
+	case c == 'n':
+		return KindNull
+	case c == '-' || (c >= '0' && c <= '9'):
+		return KindNumber
+	default:
+		return KindInvalid
+	}
+}
+
+// expect consumes the byte c, which must appear after optional whitespace
+func (sc *rawScanner) expect(c byte) error {
+	sc.skipWhitespace()
+	if sc.peek() != c {
+		return fmt.Errorf("unexpected character at offset %d: expected '%c'", sc.pos, c)
+	}
+	sc.pos++
+	sc.skipWhitespace()
+	return nil
+}
+
+// seekMember moves the position to the value of the object member
+// named token. The current position must be at the opening brace
+func (sc *rawScanner) seekMember(token string) error {
+	if err := sc.expect('{'); err != nil {
+		return err
+	}
+	if sc.peek() == '}' {
+		return errNotFound("property '%s' not found", token)
+	}
+	for {
+		key, err := sc.readString()
+		if err != nil {
+			return err
+		}
+		if err := sc.expect(':'); err != nil {
+			return err
+		}
+		if key == token {
+			return nil
+		}
+		if err := sc.skipValue(); err != nil {
+			return err
+		}
+		sc.skipWhitespace()
+		if sc.peek() == '}' {
+			return errNotFound("property '%s' not found", token)
+		}
+		if err := sc.expect(','); err != nil {
+			return err
+		}
+	}
+}
+
+// seekElement moves the position to the array element whose index is
+// token. The current position must be at the opening bracket
+func (sc *rawScanner) seekElement(token string) error {
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return fmt.Errorf("invalid array index '%s'", token)
+	}
+	if index < 0 {
+		return errNotFound("array index %d out of bounds", index)
+	}
+
+	if err := sc.expect('['); err != nil {
+		return err
+	}
+	if sc.peek() == ']' {
+		return errNotFound("array index %d out of bounds", index)
+	}
+	for i := 0; ; i++ {
+		if i == index {
+			return nil
+		}
+		if err := sc.skipValue(); err != nil {
+			return err
+		}
+		sc.skipWhitespace()
+		if sc.peek() == ']' {
+			return errNotFound("array index %d out of bounds", index)
+		}
+		if err := sc.expect(','); err != nil {
+			return err
+		}
+	}
+}
+
+// readString consumes a string, and returns its unescaped contents
+func (sc *rawScanner) readString() (string, error) {
+	start := sc.pos
+	escaped, err := sc.skipString()
+	if err != nil {
+		return "", err
+	}
+	if !escaped {
+		return string(sc.data[start+1 : sc.pos-1]), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(sc.data[start:sc.pos], &s); err != nil {
+		return "", fmt.Errorf("failed to unescape string at offset %d: %w", start, err)
+	}
+	return s, nil
+}
+
+// skipString consumes a string, and reports if it contained escape sequences.
+// The current position must be at the opening quote
+func (sc *rawScanner) skipString() (bool, error) {
+	start := sc.pos
+	if sc.peek() != '"' {
+		return false, fmt.Errorf("unexpected character at offset %d: expected string", sc.pos)
+	}
+	var escaped bool
+	for sc.pos++; sc.pos < len(sc.data); sc.pos++ {
+		switch sc.data[sc.pos] {
+		case '\\':
+			escaped = true
+			sc.pos++
+		case '"':
+			sc.pos++
+			return escaped, nil
+		}
+	}
+	return false, fmt.Errorf("unterminated string starting at offset %d", start)
+}
+
+// skipValue consumes the value at the current position
+func (sc *rawScanner) skipValue() error {
+	switch sc.peek() {
+	case '"':
+		_, err := sc.skipString()
+		return err
+	case '{', '[':
+		depth := 0
+		for sc.pos < len(sc.data) {
+			switch sc.data[sc.pos] {
+			case '"':
+				if _, err := sc.skipString(); err != nil {
+					return err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					sc.pos++
+					return nil
+				}
+			}
+			sc.pos++
+		}
+		return fmt.Errorf("unexpected end of JSON input")
+	case 0:
+		return fmt.Errorf("unexpected end of JSON input")
+	default:
+		// numbers, true, false, and null
+		start := sc.pos
+		for sc.pos < len(sc.data) {
+			switch sc.data[sc.pos] {
+			case ',', '}', ']', ' ', '\t', '\r', '\n':
+				return nil
+			}
+			sc.pos++
+		}
+		if sc.pos == start {
+			return fmt.Errorf("unexpected end of JSON input")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerRetrieveRaw(t *testing.T) {
+	const src = `{
+		"data": {"user": {"name":  "alice", "tags": [ "a", "b" ]}},
+		"esc\"aped": 1,
+		"a/b": {"c": null},
+		"list": [10, {"x": "y"}, [1, 2]],
+		"str": "with \"quotes\" and ]}"
+	}`
+
+	tests := []struct {
+		name     string
+		pointer  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "root",
+			pointer:  "",
+			expected: src,
+		},
+		{
+			name:     "object subtree keeps original formatting",
+			pointer:  "/data/user",
+			expected: `{"name":  "alice", "tags": [ "a", "b" ]}`,
+		},
+		{
+			name:     "array subtree",
+			pointer:  "/data/user/tags",
+			expected: `[ "a", "b" ]`,
+		},
+		{
+			name:     "array element",
+			pointer:  "/list/1",
+			expected: `{"x": "y"}`,
+		},
+		{
+			name:     "number",
+			pointer:  "/list/0",
+			expected: `10`,
+		},
+		{
+			name:     "escaped key in document",
+			pointer:  `/esc"aped`,
+			expected: `1`,
+		},
+		{
+			name:     "escaped key in pointer",
+			pointer:  "/a~1b/c",
+			expected: `null`,
+		},
+		{
+			name:     "string containing delimiters",
+			pointer:  "/str",
+			expected: `"with \"quotes\" and ]}"`,
+		},
+		{
+			name:    "missing property",
+			pointer: "/data/nobody",
+			wantErr: true,
+		},
+		{
+			name:    "out of bounds",
+			pointer: "/list/3",
+			wantErr: true,
+		},
+		{
+			name:    "index into scalar",
+			pointer: "/list/0/foo",
+			wantErr: true,
+		},
+	}
+
+	doc, err := jsptr.ParseJSON([]byte(src))
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr, err := jsptr.New(tt.pointer)
+			require.NoError(t, err)
+
+			raw, err := ptr.RetrieveRaw([]byte(src))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(raw))
+
+			var msg json.RawMessage
+			require.NoError(t, ptr.Retrieve(&msg, src), "Retrieve into json.RawMessage")
+			require.Equal(t, tt.expected, string(msg))
+
+			raw, err = doc.RetrieveRaw(tt.pointer)
+			require.NoError(t, err, "Document.RetrieveRaw")
+			require.Equal(t, tt.expected, string(raw))
+		})
+	}
+
+	t.Run("non-JSON target", func(t *testing.T) {
+		ptr, err := jsptr.New("/foo")
+		require.NoError(t, err)
+
+		raw, err := ptr.RetrieveRaw(map[string]any{"foo": map[string]any{"bar": 1}})
+		require.NoError(t, err)
+		require.Equal(t, `{"bar":1}`, string(raw))
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		ptr, err := jsptr.New("/foo")
+		require.NoError(t, err)
+
+		_, err = ptr.RetrieveRaw([]byte(`{"foo": }`))
+		require.Error(t, err)
+	})
+	t.Run("AppendRaw", func(t *testing.T) {
+		const src = `{"foo": {"bar": [1, 2]}, "baz": "qux"}`
+		doc, err := jsptr.ParseJSON([]byte(src))
+		require.NoError(t, err)
+
+		targets := map[string]any{
+			"bytes":    []byte(src),
+			"string":   src,
+			"document": doc,
+			"value":    map[string]any{"foo": map[string]any{"bar": []any{1, 2}}, "baz": "qux"},
+		}
+		for name, target := range targets {
+			t.Run(name, func(t *testing.T) {
+				buf := []byte("[")
+				buf, err := jsptr.MustNew("/foo/bar").AppendRaw(buf, target)
+				require.NoError(t, err)
+				buf = append(buf, ',')
+				buf, err = jsptr.MustNew("/baz").AppendRaw(buf, target)
+				require.NoError(t, err)
+				buf = append(buf, ']')
+
+				var v []any
+				require.NoError(t, json.Unmarshal(buf, &v))
+				require.Equal(t, []any{[]any{float64(1), float64(2)}, "qux"}, v)
+
+				_, err = jsptr.MustNew("/missing").AppendRaw(buf, target)
+				require.Error(t, err)
+			})
+		}
+
+		buf, err := doc.AppendRaw([]byte("x="), "/baz")
+		require.NoError(t, err)
+		require.Equal(t, `x="qux"`, string(buf))
+	})
+	t.Run("Document.MarshalJSON", func(t *testing.T) {
+		const src = `{"b": 1, "a": [true, null]}`
+		doc, err := jsptr.ParseJSON([]byte(src))
+		require.NoError(t, err)
+
+		require.Equal(t, "doc="+src, string(doc.AppendTo([]byte("doc="))))
+
+		buf, err := json.Marshal(map[string]any{"doc": doc})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"doc": `+src+`}`, string(buf))
+
+		// The returned bytes must not alias the document
+		buf, err = doc.MarshalJSON()
+		require.NoError(t, err)
+		buf[0] = '['
+		require.Equal(t, src, string(doc.Bytes()))
+	})
+}
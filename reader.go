@@ -0,0 +1,12 @@
+package jsptr
+
+// retrieveFromJSONPrefix retrieves the value at the location specified by
+// tokens from data, without looking at the part of data that follows the
+// value. Only the value itself is fully parsed
+func retrieveFromJSONPrefix(dst any, data []byte, tokens []string, cfg *retrieveConfig) error {
+	raw, err := locateRaw(data, tokens, cfg)
+	if err != nil {
+		return err
+	}
+	return retrieveFromJSON(dst, raw, nil, cfg)
+}
@@ -0,0 +1,174 @@
+//go:build go1.27 && goexperiment.jsonv2
+
+package jsptr
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// retrieveFromReader retrieves the value at the location specified by
+// tokens from the JSON document read from r. The document is read using
+// the streaming decoder of encoding/json/jsontext, and values that are
+// not on the path to the location are skipped without being decoded or
+// buffered. Only the addressed value itself is held in memory.
+func retrieveFromReader(dst any, r io.Reader, tokens []string, cfg *retrieveConfig) error {
+	dec := jsontext.NewDecoder(r)
+	if err := seekStream(dec, tokens, cfg); err != nil {
+		return err
+	}
+
+	raw, err := dec.ReadValue()
+	if err != nil {
+		return streamError(err)
+	}
+	// The value is only valid until the next read from dec
+	raw = raw.Clone()
+
+	// Unless told otherwise, read the rest of the document, so that
+	// malformed documents are reported in the same way as they are
+	// for other JSON targets
+	if !cfg.stopEarly {
+		if err := drainStream(dec); err != nil {
+			return err
+		}
+	}
+	return retrieveFromJSON(dst, raw, nil, cfg)
+}
+
+// seekStream advances dec to the value at the location specified by
+// tokens. Upon success, the next value read from dec is that value
+func seekStream(dec *jsontext.Decoder, tokens []string, cfg *retrieveConfig) error {
+	for i, token := range tokens {
+		t, err := dec.ReadToken()
+		if err != nil {
+			return streamError(err)
+		}
+
+		var kind Kind
+		switch k := t.Kind(); k {
+		case jsontext.KindBeginObject:
+			kind = KindObject
+			err = seekStreamMember(dec, token)
+		case jsontext.KindBeginArray:
+			kind = KindArray
+			err = seekStreamElement(dec, token)
+		default:
+			kind = streamKind(k)
+			err = fmt.Errorf("cannot index into %s with '%s'", k, token)
+		}
+		if cfg.trace != nil {
+			cfg.traceStep(tokens, i, kind, err)
+		}
+		if err != nil {
+			return cfg.stepError(tokens, i, err)
+		}
+	}
+	return nil
+}
+
+// seekStreamMember advances dec, which must be positioned inside of an
+// object, to the value of the member named name
+func seekStreamMember(dec *jsontext.Decoder, name string) error {
+	for dec.PeekKind() != jsontext.KindEndObject {
+		t, err := dec.ReadToken()
+		if err != nil {
+			return streamError(err)
+		}
+		if t.String() == name {
+			return nil
+		}
+		if err := dec.SkipValue(); err != nil {
+			return streamError(err)
+		}
+	}
+	return errNotFound("property '%s' not found", name)
+}
+
+// seekStreamElement advances dec, which must be positioned inside of an
+// array, to the element at the index specified by token
+func seekStreamElement(dec *jsontext.Decoder, token string) error {
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return fmt.Errorf("invalid array index '%s'", token)
+	}
+	if index < 0 {
+		return errNotFound("array index %d out of bounds", index)
+	}
+
+	for i := 0; dec.PeekKind() != jsontext.KindEndArray; i++ {
+		if i == index {
+			return nil
+		}
+		if err := dec.SkipValue(); err != nil {
+			return streamError(err)
+		}
+	}
+	return errNotFound("array index %d out of bounds", index)
+}
+
+// drainStream reads the remainder of the document from dec, and verifies
+// that the document ends properly
+func drainStream(dec *jsontext.Decoder) error {
+	for dec.StackDepth() > 0 {
+		if _, err := dec.ReadToken(); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return streamError(err)
+		}
+	}
+
+	if _, err := dec.ReadToken(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("unexpected data after top-level value")
+		}
+		return streamError(err)
+	}
+	return nil
+}
+
+// streamError wraps an error returned by a jsontext.Decoder. Malformed
+// documents are reported as syntax errors, as they are by encoding/json
+func streamError(err error) error {
+	var serr *jsontext.SyntacticError
+	if errors.As(err, &serr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("failed to read JSON: %w", &streamSyntaxError{err: err})
+	}
+	return fmt.Errorf("failed to read JSON: %w", err)
+}
+
+// streamSyntaxError matches errSyntax, so that ErrorClass recognizes the
+// errors returned by jsontext
+type streamSyntaxError struct {
+	err error
+}
+
+func (e *streamSyntaxError) Error() string {
+	return e.err.Error()
+}
+
+func (e *streamSyntaxError) Unwrap() error {
+	return e.err
+}
+
+func (e *streamSyntaxError) Is(target error) bool {
+	return target == errSyntax
+}
+
+// streamKind returns the Kind of a scalar token read from a decoder
+func streamKind(k jsontext.Kind) Kind {
+	switch k {
+	case jsontext.KindString:
+		return KindString
+	case jsontext.KindNumber:
+		return KindNumber
+	case jsontext.KindTrue, jsontext.KindFalse:
+		return KindBool
+	default:
+		return KindNull
+	}
+}
@@ -0,0 +1,186 @@
+//go:build !go1.27 || !goexperiment.jsonv2
+
+package jsptr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// retrieveFromReader retrieves the value at the location specified by
+// tokens from the JSON document read from r. The document is read as a
+// stream of tokens, and values that are not on the path to the location
+// are skipped without being buffered. Only the addressed value itself is
+// held in memory.
+func retrieveFromReader(dst any, r io.Reader, tokens []string, cfg *retrieveConfig) error {
+	dec := json.NewDecoder(r)
+	if err := seekStream(dec, tokens, cfg); err != nil {
+		return err
+	}
+
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return fmt.Errorf("failed to read JSON: %w", err)
+	}
+
+	// Unless told otherwise, read the rest of the document, so that
+	// malformed documents are reported in the same way as they are
+	// for other JSON targets
+	if !cfg.stopEarly {
+		if err := drainStream(dec, len(tokens)); err != nil {
+			return err
+		}
+	}
+	return retrieveFromJSON(dst, raw, nil, cfg)
+}
+
+// seekStream advances dec to the value at the location specified by
+// tokens. Upon success, the next value read from dec is that value
+func seekStream(dec *json.Decoder, tokens []string, cfg *retrieveConfig) error {
+	for i, token := range tokens {
+		t, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+
+		var kind Kind
+		switch t {
+		case json.Delim('{'):
+			kind = KindObject
+			err = seekStreamMember(dec, token)
+		case json.Delim('['):
+			kind = KindArray
+			err = seekStreamElement(dec, token)
+		default:
+			kind = streamTokenKind(t)
+			err = fmt.Errorf("cannot index into %s with '%s'", streamTokenType(t), token)
+		}
+		if cfg.trace != nil {
+			cfg.traceStep(tokens, i, kind, err)
+		}
+		if err != nil {
+			return cfg.stepError(tokens, i, err)
+		}
+	}
+	return nil
+}
+
+// seekStreamMember advances dec, which must be positioned inside of an
+// object, to the value of the member named name
+func seekStreamMember(dec *json.Decoder, name string) error {
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+		if key, ok := t.(string); ok && key == name {
+			return nil
+		}
+		if err := skipStreamValue(dec); err != nil {
+			return err
+		}
+	}
+	return errNotFound("property '%s' not found", name)
+}
+
+// seekStreamElement advances dec, which must be positioned inside of an
+// array, to the element at the index specified by token
+func seekStreamElement(dec *json.Decoder, token string) error {
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return fmt.Errorf("invalid array index '%s'", token)
+	}
+	if index < 0 {
+		return errNotFound("array index %d out of bounds", index)
+	}
+
+	for i := 0; dec.More(); i++ {
+		if i == index {
+			return nil
+		}
+		if err := skipStreamValue(dec); err != nil {
+			return err
+		}
+	}
+	return errNotFound("array index %d out of bounds", index)
+}
+
+// skipStreamValue reads the next value from dec and discards it
+func skipStreamValue(dec *json.Decoder) error {
+	var depth int
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// drainStream reads the remaining tokens from dec, which must be nested
+// depth containers deep, and verifies that the document ends properly
+func drainStream(dec *json.Decoder, depth int) error {
+	for depth > 0 {
+		t, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("unexpected data after top-level value")
+		}
+		return fmt.Errorf("failed to read JSON: %w", err)
+	}
+	return nil
+}
+
+// streamTokenType returns the name of the JSON type of the scalar token t,
+// using the same names as the other JSON targets
+func streamTokenType(t json.Token) string {
+	switch t := t.(type) {
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return "null"
+	}
+}
+
+// streamTokenKind returns the Kind of a scalar token read from a decoder
+func streamTokenKind(t json.Token) Kind {
+	switch t.(type) {
+	case string:
+		return KindString
+	case float64, json.Number:
+		return KindNumber
+	case bool:
+		return KindBool
+	default:
+		return KindNull
+	}
+}
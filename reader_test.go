@@ -0,0 +1,152 @@
+package jsptr_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerRetrieveFromReader(t *testing.T) {
+	const src = `{
+		"skipped": {"deep": [1, [2, {"x": "}"}]], "s": "]"},
+		"items": [{"id": 1}, {"id": 2, "tags": ["a", "b"]}],
+		"a/b": {"m~n": true},
+		"big": 12345678901234567890,
+		"null": null
+	}`
+
+	testcases := []struct {
+		Pointer  string
+		Error    bool
+		NotFound bool
+	}{
+		{Pointer: ""},
+		{Pointer: "/items/1/tags/0"},
+		{Pointer: "/items/1"},
+		{Pointer: "/a~1b/m~0n"},
+		{Pointer: "/big"},
+		{Pointer: "/null"},
+		{Pointer: "/skipped/deep/1/1/x"},
+		{Pointer: "/missing", Error: true, NotFound: true},
+		{Pointer: "/items/2", Error: true, NotFound: true},
+		{Pointer: "/items/-1", Error: true, NotFound: true},
+		{Pointer: "/items/x", Error: true},
+		{Pointer: "/items/0/id/x", Error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			ptr, err := jsptr.New(tc.Pointer)
+			require.NoError(t, err)
+
+			var fromReader, fromBytes any
+			err = ptr.Retrieve(&fromReader, strings.NewReader(src))
+			bytesErr := ptr.Retrieve(&fromBytes, []byte(src))
+			if tc.Error {
+				require.Error(t, err)
+				require.EqualError(t, err, bytesErr.Error(), "errors should match those of JSON bytes")
+				if tc.NotFound {
+					require.ErrorIs(t, err, jsptr.ErrNotFound)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, bytesErr)
+			require.Equal(t, fromBytes, fromReader)
+		})
+	}
+
+	t.Run("options and typed destinations", func(t *testing.T) {
+		ptr, err := jsptr.New("/big")
+		require.NoError(t, err)
+
+		var n uint64
+		require.NoError(t, ptr.Retrieve(&n, bytes.NewBufferString(src)))
+		require.Equal(t, uint64(12345678901234567890), n)
+
+		ptr, err = jsptr.New("/items/1")
+		require.NoError(t, err)
+
+		var item struct {
+			ID   int      `json:"id"`
+			Tags []string `json:"tags"`
+		}
+		require.NoError(t, ptr.Retrieve(&item, strings.NewReader(src)))
+		require.Equal(t, 2, item.ID)
+		require.Equal(t, []string{"a", "b"}, item.Tags)
+	})
+
+	t.Run("malformed documents", func(t *testing.T) {
+		ptr, err := jsptr.New("/a")
+		require.NoError(t, err)
+
+		var v any
+		require.Error(t, ptr.Retrieve(&v, strings.NewReader(`{"a": 1, "b": }`)))
+		require.Error(t, ptr.Retrieve(&v, strings.NewReader(`{"a": 1`)))
+		require.Error(t, ptr.Retrieve(&v, strings.NewReader(``)))
+		require.Error(t, ptr.Retrieve(&v, strings.NewReader(`{"a": 1} {}`)))
+	})
+
+	t.Run("extension tokens", func(t *testing.T) {
+		ptr, err := jsptr.New("/items/*/id", jsptr.WithExtensions(true))
+		require.NoError(t, err)
+
+		matches, err := ptr.RetrieveAll(strings.NewReader(src))
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+	})
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestPointerRetrieveStopEarly(t *testing.T) {
+	// Everything after the value is malformed, and is never looked at
+	const src = `{"header": {"id": 42, "tags": ["a"]}, "body": [{"oops"`
+
+	ptr, err := jsptr.New("/header/id")
+	require.NoError(t, err)
+
+	targets := map[string]func() any{
+		"bytes":  func() any { return []byte(src) },
+		"string": func() any { return src },
+		"reader": func() any { return io.MultiReader(strings.NewReader(src), failingReader{}) },
+	}
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			var v int
+			require.Error(t, ptr.Retrieve(&v, target()), "the whole document is read by default")
+			require.NoError(t, ptr.Retrieve(&v, target(), jsptr.WithStopEarly(true)))
+			require.Equal(t, 42, v)
+		})
+	}
+
+	t.Run("containers", func(t *testing.T) {
+		ptr, err := jsptr.New("/header")
+		require.NoError(t, err)
+
+		var v any
+		require.NoError(t, ptr.Retrieve(&v, src, jsptr.WithStopEarly(true)))
+		require.Equal(t, map[string]any{"id": 42.0, "tags": []any{"a"}}, v)
+	})
+
+	t.Run("errors before the value are reported", func(t *testing.T) {
+		ptr, err := jsptr.New("/body/0")
+		require.NoError(t, err)
+
+		var v any
+		require.Error(t, ptr.Retrieve(&v, src, jsptr.WithStopEarly(true)))
+
+		ptr, err = jsptr.New("/missing")
+		require.NoError(t, err)
+		require.ErrorIs(t, ptr.Retrieve(&v, `{"a": 1}`, jsptr.WithStopEarly(true)), jsptr.ErrNotFound)
+	})
+}
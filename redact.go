@@ -0,0 +1,181 @@
+package jsptr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+// Mask computes the value that replaces a redacted value v. v is made of
+// the generic values that JSON documents are retrieved as, and the value
+// returned by Mask must be encodable as JSON.
+type Mask func(v any) any
+
+// FixedMask returns a Mask that replaces every value with s
+func FixedMask(s string) Mask {
+	return func(any) any {
+		return s
+	}
+}
+
+// HashMask returns a Mask that replaces values with the hex-encoded
+// SHA-256 hash of their contents, prefixed with "sha256:". Strings are
+// hashed as they are, and other values are hashed by their JSON encoding.
+// Equal values are replaced with equal hashes, so that redacted values
+// can still be correlated.
+//
+// Note that values that can be guessed, such as short numbers, can be
+// recovered from their hashes.
+func HashMask() Mask {
+	return func(v any) any {
+		sum := sha256.Sum256([]byte(maskText(v)))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// PartialMask returns a Mask that replaces values with a string in which
+// all but the last n characters are replaced with '*', as is common for
+// card numbers and API keys. Strings are masked as they are, and other
+// values are masked by their JSON encoding. Values that are not longer
+// than n characters are masked entirely.
+func PartialMask(n int) Mask {
+	return func(v any) any {
+		runes := []rune(maskText(v))
+		if len(runes) <= n {
+			return strings.Repeat("*", len(runes))
+		}
+		for i := range len(runes) - n {
+			runes[i] = '*'
+		}
+		return string(runes)
+	}
+}
+
+// maskText returns the text of v that masks are computed from
+func maskText(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(buf)
+}
+
+// Redactor replaces the values at configured locations of documents, such
+// as passwords and personal data, so that the documents can be logged.
+// A Redactor is safe for concurrent use.
+type Redactor struct {
+	rules []redactRule
+}
+
+type redactRule struct {
+	ptr  *Pointer
+	mask Mask
+}
+
+// NewRedactor creates a Redactor that applies the redactions specified
+// using WithRedaction
+func NewRedactor(options ...RedactorOption) (*Redactor, error) {
+	var r Redactor
+	for _, option := range options {
+		switch option.Ident() {
+		case identRedaction{}:
+			v := option.Value().(redaction)
+			if v.mask == nil {
+				return nil, fmt.Errorf("redaction of '%s' has no mask", v.pattern)
+			}
+			ptr, err := New(v.pattern, WithExtensions(true))
+			if err != nil {
+				return nil, fmt.Errorf("invalid redaction pattern '%s': %w", v.pattern, err)
+			}
+			r.rules = append(r.rules, redactRule{ptr: ptr, mask: v.mask})
+		}
+	}
+	return &r, nil
+}
+
+// Redact returns a redacted copy of doc, which is left untouched. doc may
+// be JSON text as []byte or string, a *Document, or Go values, which are
+// redacted through their JSON representation. The result is made of
+// map[string]any and []any.
+func (r *Redactor) Redact(doc any) (any, error) {
+	root, err := genericValue(doc, defaultRetrieveConfig)
+	if err != nil {
+		return nil, err
+	}
+	return edit(root, r.redact)
+}
+
+// RedactJSON returns a redacted copy of the JSON text data. The parts of
+// the document that are not redacted are written back as they were,
+// including the order of object members.
+func (r *Redactor) RedactJSON(data []byte) ([]byte, error) {
+	result, err := edit(data, r.redact)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+func (r *Redactor) redact(ed editor, root any) (any, error) {
+	for _, rule := range r.rules {
+		node := root
+		if ed.arena != nil {
+			node = jsonNode{root.(*fastjson.Value)}
+		}
+
+		var matches []Match
+		err := expandConcrete(node, "", rule.ptr.expandSegments(), defaultRetrieveConfig, func(ptr string, node any) error {
+			v, err := exportNode(node, defaultRetrieveConfig)
+			if err != nil {
+				return err
+			}
+			matches = append(matches, Match{Pointer: ptr, Value: v})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Matches are ordered so that values come before the values
+		// within them, which no longer exist once the former are redacted
+		redacted := make(map[string]struct{})
+		for _, m := range matches {
+			if isRedacted(redacted, m.Pointer) {
+				continue
+			}
+			tokens, err := parseTokens(m.Pointer)
+			if err != nil {
+				return nil, err
+			}
+			result, err := ed.mutate(root, tokens, editReplace, rule.mask(m.Value))
+			if err != nil {
+				return nil, fmt.Errorf("failed to redact '%s': %w", m.Pointer, err)
+			}
+			root = result
+			redacted[m.Pointer] = struct{}{}
+		}
+	}
+	return root, nil
+}
+
+// isRedacted reports whether ptr, or the pointer of one of the values
+// that contain it, is in redacted
+func isRedacted(redacted map[string]struct{}, ptr string) bool {
+	for {
+		if _, ok := redacted[ptr]; ok {
+			return true
+		}
+		i := strings.LastIndexByte(ptr, '/')
+		if i < 0 {
+			return false
+		}
+		ptr = ptr[:i]
+	}
+}
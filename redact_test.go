@@ -0,0 +1,81 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasks(t *testing.T) {
+	testcases := []struct {
+		Name     string
+		Mask     jsptr.Mask
+		Value    any
+		Expected any
+	}{
+		{Name: "fixed", Mask: jsptr.FixedMask("[REDACTED]"), Value: map[string]any{"a": 1.0}, Expected: "[REDACTED]"},
+		{Name: "hash string", Mask: jsptr.HashMask(), Value: "secret", Expected: "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"},
+		{Name: "hash number", Mask: jsptr.HashMask(), Value: 1.0, Expected: "sha256:6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b"},
+		{Name: "partial", Mask: jsptr.PartialMask(4), Value: "4111111111111111", Expected: "************1111"},
+		{Name: "partial multibyte", Mask: jsptr.PartialMask(2), Value: "日本語です", Expected: "***です"},
+		{Name: "partial short", Mask: jsptr.PartialMask(4), Value: "abc", Expected: "***"},
+		{Name: "partial number", Mask: jsptr.PartialMask(2), Value: 12345.0, Expected: "***45"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Expected, tc.Mask(tc.Value))
+		})
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r, err := jsptr.NewRedactor(
+		jsptr.WithRedaction("/**/password", jsptr.FixedMask("***")),
+		jsptr.WithRedaction("/cards/*/number", jsptr.PartialMask(4)),
+		jsptr.WithRedaction("/user/email", jsptr.HashMask()),
+	)
+	require.NoError(t, err)
+
+	const doc = `{"user":{"name":"a","email":"secret","password":"p1"},"cards":[{"number":"4111111111111111","password":{"password":"p2"}}],"missing":null}`
+
+	t.Run("RedactJSON", func(t *testing.T) {
+		buf, err := r.RedactJSON([]byte(doc))
+		require.NoError(t, err)
+		require.Equal(t, `{"user":{"name":"a","email":"sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b","password":"***"},"cards":[{"number":"************1111","password":"***"}],"missing":null}`, string(buf))
+	})
+	t.Run("Redact", func(t *testing.T) {
+		src := decodeJSON(t, doc)
+		result, err := r.Redact(src)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"user": map[string]any{
+				"name":     "a",
+				"email":    "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b",
+				"password": "***",
+			},
+			"cards":   []any{map[string]any{"number": "************1111", "password": "***"}},
+			"missing": nil,
+		}, result)
+		require.Equal(t, decodeJSON(t, doc), src)
+	})
+	t.Run("Go values", func(t *testing.T) {
+		type login struct {
+			User     string `json:"user"`
+			Password string `json:"password"`
+		}
+		result, err := r.Redact(login{User: "a", Password: "b"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"user": "a", "password": "***"}, result)
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := r.RedactJSON([]byte(`{"password":`))
+		require.Error(t, err)
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jsptr.NewRedactor(jsptr.WithRedaction("password", jsptr.FixedMask("")))
+		require.Error(t, err)
+		_, err = jsptr.NewRedactor(jsptr.WithRedaction("/password", nil))
+		require.Error(t, err)
+	})
+}
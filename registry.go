@@ -0,0 +1,118 @@
+package jsptr
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// SourceFactory creates a Source for a value of the type it was
+// registered for using RegisterSource
+type SourceFactory func(v any) (Source, error)
+
+var (
+	registryMu sync.Mutex
+	// sourceRegistry is replaced as a whole whenever a factory is
+	// registered, so that lookups do not need to take a lock
+	sourceRegistry atomic.Pointer[map[reflect.Type]SourceFactory]
+)
+
+// RegisterSource registers fn as the factory of the Source that is used
+// to evaluate pointers against values of type t. This allows container
+// types that cannot implement Source themselves, such as ordered maps or
+// immutable collections from other packages, to be used as targets, and
+// to appear anywhere within other values, without wrapping them by hand.
+//
+// fn receives values of type t. If t is not a pointer type, the factory is
+// also used for non-nil pointers to t, which are dereferenced first. A
+// factory registered for the exact type of a value takes precedence.
+//
+// Registering a factory for a type that already has one replaces it, and
+// a nil fn removes the registration. Access to struct fields of type t may
+// already be planned by pointers that have been used before, so factories
+// should be registered during initialization.
+func RegisterSource(t reflect.Type, fn SourceFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry := make(map[reflect.Type]SourceFactory)
+	if cur := sourceRegistry.Load(); cur != nil {
+		maps.Copy(registry, *cur)
+	}
+	if fn == nil {
+		delete(registry, t)
+	} else {
+		registry[t] = fn
+	}
+	sourceRegistry.Store(&registry)
+}
+
+// lookupSourceFactory returns the factory registered for the value v, along
+// with the value that must be passed to it
+func lookupSourceFactory(v any) (SourceFactory, any, bool) {
+	registry := sourceRegistry.Load()
+	if registry == nil || len(*registry) == 0 || v == nil {
+		return nil, nil, false
+	}
+
+	t := reflect.TypeOf(v)
+	if fn, ok := (*registry)[t]; ok {
+		return fn, v, true
+	}
+	if t.Kind() != reflect.Ptr {
+		return nil, nil, false
+	}
+	fn, ok := (*registry)[t.Elem()]
+	if !ok {
+		return nil, nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return nil, nil, false
+	}
+	return fn, rv.Elem().Interface(), true
+}
+
+// registeredSource creates a Source for v using the factory registered
+// for its type
+func registeredSource(v any) (Source, bool, error) {
+	fn, arg, ok := lookupSourceFactory(v)
+	if !ok {
+		return nil, false, nil
+	}
+	source, err := fn(arg)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to create source for %T: %w", v, err)
+	}
+	return source, true, nil
+}
+
+// isRegisteredType reports whether a factory is registered for values of
+// type t, or for the types that t points to
+func isRegisteredType(t reflect.Type) bool {
+	registry := sourceRegistry.Load()
+	if registry == nil || len(*registry) == 0 {
+		return false
+	}
+	for {
+		if _, ok := (*registry)[t]; ok {
+			return true
+		}
+		if t.Kind() != reflect.Ptr {
+			return false
+		}
+		t = t.Elem()
+	}
+}
+
+// failedSource is the Source of values whose registered factory failed.
+// The failure is reported when the value is accessed
+type failedSource struct {
+	err error
+}
+
+func (s failedSource) RetrieveJSONPointer(any, string) error {
+	return s.err
+}
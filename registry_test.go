@@ -0,0 +1,84 @@
+package jsptr_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+// frozenList is a container that does not expose its elements, and does
+// not implement jsptr.Source
+type frozenList struct {
+	items []any
+}
+
+type frozenListSource struct {
+	items []any
+}
+
+func (s frozenListSource) RetrieveJSONPointer(dst any, ptrspec string) error {
+	return jsptr.MustNew(ptrspec).Retrieve(dst, s.items)
+}
+
+type frozenHolder struct {
+	List frozenList  `json:"list"`
+	Ptr  *frozenList `json:"ptr"`
+}
+
+func TestRegisterSource(t *testing.T) {
+	listType := reflect.TypeFor[frozenList]()
+	jsptr.RegisterSource(listType, func(v any) (jsptr.Source, error) {
+		list := v.(frozenList)
+		if list.items == nil {
+			return nil, errors.New("list is not initialized")
+		}
+		return frozenListSource{items: list.items}, nil
+	})
+	t.Cleanup(func() { jsptr.RegisterSource(listType, nil) })
+
+	list := frozenList{items: []any{"a", map[string]any{"b": 1}}}
+	holder := &frozenHolder{List: list, Ptr: &list}
+
+	testcases := []struct {
+		Name     string
+		Spec     string
+		Target   any
+		Expected any
+		Error    string
+	}{
+		{Name: "value", Spec: "/0", Target: list, Expected: "a"},
+		{Name: "pointer", Spec: "/1/b", Target: &list, Expected: 1},
+		{Name: "nested in map", Spec: "/list/1/b", Target: map[string]any{"list": list}, Expected: 1},
+		{Name: "struct field", Spec: "/list/0", Target: holder, Expected: "a"},
+		{Name: "pointer field", Spec: "/ptr/1/b", Target: holder, Expected: 1},
+		{Name: "missing element", Spec: "/list/2", Target: holder, Error: "array index 2 out of bounds"},
+		{Name: "factory error", Spec: "/0", Target: frozenList{}, Error: "list is not initialized"},
+		{Name: "nested factory error", Spec: "/list/0", Target: &frozenHolder{}, Error: "list is not initialized"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// Struct fields are accessed using plans after the first
+			// retrieval, which must not bypass the registered factory
+			for range 2 {
+				var v any
+				err := jsptr.MustNew(tc.Spec).Retrieve(&v, tc.Target)
+				if tc.Error != "" {
+					require.ErrorContains(t, err, tc.Error)
+					continue
+				}
+				require.NoError(t, err)
+				require.EqualValues(t, tc.Expected, v)
+			}
+		})
+	}
+
+	t.Run("Unregistered types use reflection", func(t *testing.T) {
+		jsptr.RegisterSource(listType, nil)
+		var v any
+		require.Error(t, jsptr.MustNew("/0").Retrieve(&v, list))
+	})
+}
@@ -0,0 +1,93 @@
+package jsptr
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Rewrite moves the values of doc according to mapping, which maps the
+// JSON pointers of the locations that values are moved from to the
+// locations that they are moved to, and returns the resulting document.
+// Everything below a location moves along with it, so that
+// {"/old/loc": "/new/loc"} moves "/old/loc/a" to "/new/loc/a". The rest of
+// the document is left as it is.
+//
+// All pointers refer to locations in doc as it was before anything was
+// moved, so that values can be swapped, and mappings of values nested in
+// other mapped values take precedence over those of the containing
+// values. Locations that do not exist are ignored. The objects and arrays
+// that lead to the new locations are created as needed, as described in
+// WithParents. Mapping the empty pointer moves the whole document.
+//
+// The same types of documents as those accepted by Set can be used, and
+// the same caveats apply.
+func Rewrite(doc any, mapping map[string]string) (any, error) {
+	type rewrite struct {
+		from, to []string
+		value    any
+		found    bool
+	}
+
+	rewrites := make([]*rewrite, 0, len(mapping))
+	for _, from := range slices.Sorted(maps.Keys(mapping)) {
+		fromTokens, err := parseTokens(from)
+		if err != nil {
+			return nil, err
+		}
+		toTokens, err := parseTokens(mapping[from])
+		if err != nil {
+			return nil, err
+		}
+		rewrites = append(rewrites, &rewrite{from: fromTokens, to: toTokens})
+	}
+
+	return edit(doc, func(ed editor, root any) (any, error) {
+		// Take all values out of the document first, removing them
+		// from the last to the first, and nested values before the
+		// values that contain them
+		slices.SortFunc(rewrites, func(a, b *rewrite) int {
+			return -comparePaths(a.from, b.from)
+		})
+		for _, rw := range rewrites {
+			v, err := ed.get(root, rw.from)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					rw.found = false
+					continue
+				}
+				return nil, fmt.Errorf("failed to move '%s': %w", joinTokens(rw.from), err)
+			}
+			rw.value, rw.found = v, true
+
+			if len(rw.from) == 0 {
+				root = nil
+				if ed.arena != nil {
+					root = ed.arena.NewNull()
+				}
+				continue
+			}
+			if root, err = ed.mutate(root, rw.from, editRemove, nil); err != nil {
+				return nil, fmt.Errorf("failed to move '%s': %w", joinTokens(rw.from), err)
+			}
+		}
+
+		// Then put them back, creating containers before their members
+		slices.SortFunc(rewrites, func(a, b *rewrite) int {
+			return comparePaths(a.to, b.to)
+		})
+		for _, rw := range rewrites {
+			if !rw.found {
+				continue
+			}
+			to := slices.Clone(rw.to)
+			root = ed.makeParents(root, to)
+			var err error
+			if root, err = ed.put(root, to, editSet, rw.value); err != nil {
+				return nil, fmt.Errorf("failed to move '%s' to '%s': %w", joinTokens(rw.from), joinTokens(rw.to), err)
+			}
+		}
+		return root, nil
+	})
+}
@@ -0,0 +1,101 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	testcases := []struct {
+		Name     string
+		Doc      string
+		Mapping  map[string]string
+		Expected string
+		Error    bool
+	}{
+		{
+			Name:     "subtrees",
+			Doc:      `{"spec": {"image": "a", "ports": [80]}, "name": "x"}`,
+			Mapping:  map[string]string{"/spec": "/template/spec"},
+			Expected: `{"template": {"spec": {"image": "a", "ports": [80]}}, "name": "x"}`,
+		},
+		{
+			Name:     "swapped values",
+			Doc:      `{"a": 1, "b": 2}`,
+			Mapping:  map[string]string{"/a": "/b", "/b": "/a"},
+			Expected: `{"a": 2, "b": 1}`,
+		},
+		{
+			Name:     "nested mappings",
+			Doc:      `{"old": {"keep": 1, "special": 2}}`,
+			Mapping:  map[string]string{"/old": "/new", "/old/special": "/special"},
+			Expected: `{"new": {"keep": 1}, "special": 2}`,
+		},
+		{
+			Name:     "into the moved value",
+			Doc:      `{"a": {"x": 1}, "b": 2}`,
+			Mapping:  map[string]string{"/a": "/c", "/b": "/c/b"},
+			Expected: `{"c": {"x": 1, "b": 2}}`,
+		},
+		{
+			Name:     "array elements",
+			Doc:      `{"items": ["a", "b", "c"]}`,
+			Mapping:  map[string]string{"/items/0": "/first", "/items/2": "/last"},
+			Expected: `{"items": ["b"], "first": "a", "last": "c"}`,
+		},
+		{
+			Name:     "missing locations",
+			Doc:      `{"a": 1}`,
+			Mapping:  map[string]string{"/missing": "/b"},
+			Expected: `{"a": 1}`,
+		},
+		{
+			Name:     "whole document",
+			Doc:      `{"a": 1}`,
+			Mapping:  map[string]string{"": "/data"},
+			Expected: `{"data": {"a": 1}}`,
+		},
+		{
+			Name:     "to the root",
+			Doc:      `{"data": {"a": 1}, "meta": {}}`,
+			Mapping:  map[string]string{"/data": ""},
+			Expected: `{"a": 1}`,
+		},
+		{
+			Name:    "scalar parent",
+			Doc:     `{"a": 1, "b": 2}`,
+			Mapping: map[string]string{"/a": "/b/c"},
+			Error:   true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := jsptr.Rewrite([]byte(tc.Doc), tc.Mapping)
+			if tc.Error {
+				require.Error(t, err)
+
+				// Generic documents are left untouched
+				doc := decodeJSON(t, tc.Doc)
+				_, err = jsptr.Rewrite(doc, tc.Mapping)
+				require.Error(t, err)
+				require.Equal(t, decodeJSON(t, tc.Doc), doc)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tc.Expected, string(result.([]byte)))
+
+			// Generic documents produce the same results
+			result, err = jsptr.Rewrite(decodeJSON(t, tc.Doc), tc.Mapping)
+			require.NoError(t, err)
+			require.Equal(t, decodeJSON(t, tc.Expected), result)
+		})
+	}
+
+	t.Run("member order", func(t *testing.T) {
+		result, err := jsptr.Rewrite(`{"z":1,"a":{"b":2},"m":3}`, map[string]string{"/a/b": "/a/c"})
+		require.NoError(t, err)
+		require.Equal(t, `{"z":1,"a":{"c":2},"m":3}`, result)
+	})
+}
@@ -0,0 +1,135 @@
+package jsptr
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SafeDocument is a JSON document that can be queried and updated from
+// multiple goroutines at once, such as a configuration document that is
+// read by request handlers while a watcher applies updates to it.
+//
+// Reads may run concurrently, while updates are serialized and wait for
+// ongoing reads to complete. Every update produces a new version of the
+// document: an update that fails leaves the document as it was, and
+// values retrieved from earlier versions are never modified.
+//
+// SafeDocument implements the Source interface, so it can also be passed
+// as the target of (*Pointer).Retrieve
+type SafeDocument struct {
+	mu      sync.RWMutex
+	doc     *Document
+	options []ParseOption
+}
+
+// NewSafeDocument parses the given JSON bytes and returns a SafeDocument.
+// The options are used to parse every version of the document, including
+// those produced by updates.
+func NewSafeDocument(data []byte, options ...ParseOption) (*SafeDocument, error) {
+	doc, err := ParseJSON(data, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeDocument{doc: doc, options: options}, nil
+}
+
+// Bytes returns the JSON bytes of the current version of the document.
+// The bytes must not be modified.
+func (d *SafeDocument) Bytes() []byte {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.doc.Bytes()
+}
+
+// Retrieve retrieves the value at the location specified by the JSON
+// pointer `spec`, and assigns it to `dst`
+func (d *SafeDocument) Retrieve(dst any, spec string, options ...RetrieveOption) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.doc.Retrieve(dst, spec, options...)
+}
+
+// Get returns the value at the location specified by the JSON pointer
+// `spec`. See (*Document).Get for details.
+func (d *SafeDocument) Get(spec string, options ...RetrieveOption) (any, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.doc.Get(spec, options...)
+}
+
+func (d *SafeDocument) RetrieveJSONPointer(dst any, ptrspec string) error {
+	return d.Retrieve(dst, ptrspec)
+}
+
+func (d *SafeDocument) retrieveTokens(dst any, tokens []string, cfg *retrieveConfig) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.doc.retrieveTokens(dst, tokens, cfg)
+}
+
+// Set sets the value at the location specified by the JSON pointer
+// `spec`. See the package level Set function for details.
+func (d *SafeDocument) Set(spec string, value any, options ...SetOption) error {
+	return d.Update(func(data []byte) ([]byte, error) {
+		result, err := Set(data, spec, value, options...)
+		if err != nil {
+			return nil, err
+		}
+		return result.([]byte), nil
+	})
+}
+
+// Delete removes the value at the location specified by the JSON pointer
+// `spec`. See the package level Delete function for details.
+func (d *SafeDocument) Delete(spec string) error {
+	return d.Update(func(data []byte) ([]byte, error) {
+		result, err := Delete(data, spec)
+		if err != nil {
+			return nil, err
+		}
+		return result.([]byte), nil
+	})
+}
+
+// ApplyPatch applies the operations of patch to the document. Either all
+// of the operations are applied, or none of them are.
+func (d *SafeDocument) ApplyPatch(patch Patch) error {
+	return d.Update(func(data []byte) ([]byte, error) {
+		result, err := ApplyPatch(data, patch)
+		if err != nil {
+			return nil, err
+		}
+		return result.([]byte), nil
+	})
+}
+
+// Replace replaces the whole document with the given JSON bytes, such
+// as the new contents of a configuration file that has been modified.
+// data must not be modified afterwards.
+func (d *SafeDocument) Replace(data []byte) error {
+	return d.Update(func([]byte) ([]byte, error) {
+		return data, nil
+	})
+}
+
+// Update replaces the document with the JSON bytes returned by fn, which
+// is called with the bytes of the current version of the document. fn
+// must not modify the bytes it is given, nor those it returns once it
+// has returned. Updates are serialized, so fn
+// sees the result of the previous update. If fn returns an error, or
+// bytes that are not valid JSON, the document is left unchanged.
+func (d *SafeDocument) Update(fn func(data []byte) ([]byte, error)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data, err := fn(d.doc.Bytes())
+	if err != nil {
+		return err
+	}
+	doc, err := ParseJSON(data, d.options...)
+	if err != nil {
+		return fmt.Errorf("failed to parse updated document: %w", err)
+	}
+	d.doc = doc
+	return nil
+}
@@ -0,0 +1,81 @@
+package jsptr_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeDocument(t *testing.T) {
+	doc, err := jsptr.NewSafeDocument([]byte(`{"db": {"host": "localhost", "port": 5432}, "features": ["a"]}`))
+	require.NoError(t, err)
+
+	t.Run("updates", func(t *testing.T) {
+		require.NoError(t, doc.Set("/db/host", "db.example.com"))
+		require.NoError(t, doc.Delete("/db/port"))
+		require.NoError(t, doc.ApplyPatch(jsptr.Patch{{Op: "add", Path: "/features/-", Value: "b"}}))
+
+		var host string
+		require.NoError(t, doc.Retrieve(&host, "/db/host"))
+		require.Equal(t, "db.example.com", host)
+
+		v, err := doc.Get("")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"db":       map[string]any{"host": "db.example.com"},
+			"features": []any{"a", "b"},
+		}, v)
+	})
+	t.Run("failed updates leave the document unchanged", func(t *testing.T) {
+		before := doc.Bytes()
+		require.Error(t, doc.Set("/missing/parent", 1))
+		require.Error(t, doc.ApplyPatch(jsptr.Patch{
+			{Op: "replace", Path: "/db/host", Value: "changed"},
+			{Op: "remove", Path: "/missing"},
+		}))
+		require.Error(t, doc.Replace([]byte(`{`)))
+		require.Equal(t, before, doc.Bytes())
+	})
+	t.Run("as a pointer target", func(t *testing.T) {
+		var feature string
+		require.NoError(t, jsptr.MustNew("/features/1").Retrieve(&feature, doc))
+		require.Equal(t, "b", feature)
+	})
+	t.Run("concurrent reads and writes", func(t *testing.T) {
+		require.NoError(t, doc.Replace([]byte(`{"name": "v0", "version": 0}`)))
+
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 50 {
+					var name string
+					if err := doc.Retrieve(&name, "/name"); err != nil {
+						t.Errorf("failed to retrieve: %s", err)
+						return
+					}
+				}
+			}()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := doc.Update(func(data []byte) ([]byte, error) {
+					return fmt.Appendf(nil, `{"name": "v%d", "version": %d}`, i+1, i+1), nil
+				})
+				if err != nil {
+					t.Errorf("failed to update: %s", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		var version int
+		require.NoError(t, doc.Retrieve(&version, "/version"))
+		require.NotZero(t, version)
+	})
+}
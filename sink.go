@@ -0,0 +1,31 @@
+package jsptr
+
+import "fmt"
+
+// Sink is the writable counterpart of Source. It is implemented by types
+// that accept values assigned to locations within them, such as documents
+// backed by a database or a configuration store.
+//
+// When Set or (*Pointer).Set is given a Sink as the document, or reaches
+// a Sink within the document before the end of the pointer, the Sink is
+// asked to assign the value at the location specified by the rest of the
+// pointer, relative to itself. A Sink at the location itself is replaced
+// like any other value.
+// Changes made by a Sink cannot be rolled back if a later change fails.
+type Sink interface {
+	AssignJSONPointer(value any, ptrspec string) error
+}
+
+// Set sets the value at the location specified by the pointer in doc,
+// and returns the resulting document, in the same way as the Set
+// function does. Pointers that contain extension tokens cannot be used.
+func (p *Pointer) Set(doc any, value any, options ...SetOption) (any, error) {
+	if p.segments != nil {
+		return nil, fmt.Errorf("failed to set '%s': pointers with extension tokens cannot be used to set values", p.pattern)
+	}
+	result, err := set(doc, p.tokens, value, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set '%s': %w", p.pattern, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package jsptr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink is a Sink that records the values assigned to it
+type recordingSink struct {
+	values map[string]any
+}
+
+func (s *recordingSink) AssignJSONPointer(value any, ptrspec string) error {
+	if ptrspec == "/readonly" {
+		return errors.New("read-only location")
+	}
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+	s.values[ptrspec] = value
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	t.Run("document", func(t *testing.T) {
+		sink := &recordingSink{}
+		result, err := jsptr.Set(sink, "/a/b~1c", 1)
+		require.NoError(t, err)
+		require.Same(t, sink, result)
+		require.Equal(t, map[string]any{"/a/b~1c": 1}, sink.values)
+	})
+	t.Run("nested", func(t *testing.T) {
+		sink := &recordingSink{}
+		doc := map[string]any{"store": []any{sink}}
+		_, err := jsptr.Set(doc, "/store/0/x", "v", jsptr.WithParents(true))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"/x": "v"}, sink.values)
+
+		// The Sink itself can be replaced
+		_, err = jsptr.Set(doc, "/store/0", "replaced")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"store": []any{"replaced"}}, doc)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := jsptr.Set(map[string]any{"s": &recordingSink{}}, "/s/readonly", 1)
+		require.ErrorContains(t, err, "failed to set '/s/readonly': read-only location")
+	})
+	t.Run("other operations", func(t *testing.T) {
+		_, err := jsptr.Delete(&recordingSink{}, "/a")
+		require.Error(t, err)
+	})
+	t.Run("Pointer.Set", func(t *testing.T) {
+		ptr := jsptr.MustNew("/a/b")
+		sink := &recordingSink{}
+		_, err := ptr.Set(map[string]any{"a": sink}, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"/b": 1}, sink.values)
+
+		result, err := ptr.Set(`{"a":{}}`, 2)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":{"b":2}}`, result)
+
+		_, err = ptr.Set(`{}`, 2)
+		require.ErrorContains(t, err, "failed to set '/a/b'")
+
+		result, err = ptr.Set(`{}`, 2, jsptr.WithParents(true))
+		require.NoError(t, err)
+		require.Equal(t, `{"a":{"b":2}}`, result)
+		require.Equal(t, []string{"a", "b"}, ptr.Tokens())
+
+		ext := jsptr.MustNew("/*/b", jsptr.WithExtensions(true))
+		_, err = ext.Set(map[string]any{}, 1)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,54 @@
+package jsptr
+
+import "slices"
+
+// TraceStep describes a single step of the traversal of a target, in
+// which a reference token is applied to a node
+type TraceStep struct {
+	// Pointer is the location of the node that the token was applied to
+	Pointer string
+	// Token is the unescaped reference token
+	Token string
+	// Kind is the JSON type of the node that the token was applied to
+	Kind Kind
+	// Err is the error that caused the step to fail, or nil if the
+	// token was successfully resolved
+	Err error
+}
+
+// TraceFunc is the type of the function that is called by the
+// traversal for each step taken. See WithTrace
+type TraceFunc func(step TraceStep)
+
+// traceStep reports the step that applied tokens[i] to a node of the
+// given kind. The caller must check that cfg.trace is set, so that the
+// kind is not computed needlessly
+func (cfg *retrieveConfig) traceStep(tokens []string, i int, kind Kind, err error) {
+	cfg.trace(TraceStep{
+		Pointer: cfg.location(tokens[:i]),
+		Token:   tokens[i],
+		Kind:    kind,
+		Err:     err,
+	})
+}
+
+// within returns the configuration used to traverse a value found at
+// the location specified by tokens, so that the locations that are
+// traced and reported in errors are relative to the original target
+func (cfg *retrieveConfig) within(tokens []string) *retrieveConfig {
+	if len(tokens) == 0 {
+		return cfg
+	}
+	c := *cfg
+	c.prefix = append(slices.Clone(cfg.prefix), tokens...)
+	return &c
+}
+
+// location returns the pointer to the location specified by tokens,
+// relative to the original target
+func (cfg *retrieveConfig) location(tokens []string) string {
+	if len(cfg.prefix) == 0 {
+		return joinTokens(tokens)
+	}
+	return joinTokens(append(slices.Clone(cfg.prefix), tokens...))
+}
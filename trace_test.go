@@ -0,0 +1,75 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTrace(t *testing.T) {
+	const src = `{"a": {"b": [1, {"c": "x"}]}}`
+
+	type step struct {
+		Pointer string
+		Token   string
+		Kind    jsptr.Kind
+		Failed  bool
+	}
+	succeeded := []step{
+		{Pointer: "", Token: "a", Kind: jsptr.KindObject},
+		{Pointer: "/a", Token: "b", Kind: jsptr.KindObject},
+		{Pointer: "/a/b", Token: "1", Kind: jsptr.KindArray},
+		{Pointer: "/a/b/1", Token: "c", Kind: jsptr.KindObject},
+	}
+	failed := []step{
+		{Pointer: "", Token: "a", Kind: jsptr.KindObject},
+		{Pointer: "/a", Token: "b", Kind: jsptr.KindObject},
+		{Pointer: "/a/b", Token: "0", Kind: jsptr.KindArray},
+		{Pointer: "/a/b/0", Token: "c", Kind: jsptr.KindNumber, Failed: true},
+	}
+
+	targets := map[string]func() any{
+		"JSON":   func() any { return []byte(src) },
+		"reader": func() any { return strings.NewReader(src) },
+		"map":    func() any { return decodeJSON(t, src) },
+		"raw message": func() any {
+			return map[string]any{"a": json.RawMessage(`{"b": [1, {"c": "x"}]}`)}
+		},
+	}
+
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			var steps []step
+			trace := jsptr.WithTrace(func(s jsptr.TraceStep) {
+				steps = append(steps, step{Pointer: s.Pointer, Token: s.Token, Kind: s.Kind, Failed: s.Err != nil})
+			})
+
+			ptr, err := jsptr.New("/a/b/1/c")
+			require.NoError(t, err)
+			var v string
+			require.NoError(t, ptr.Retrieve(&v, target(), trace))
+			require.Equal(t, succeeded, steps)
+
+			steps = nil
+			ptr, err = jsptr.New("/a/b/0/c")
+			require.NoError(t, err)
+			require.Error(t, ptr.Retrieve(&v, target(), trace))
+			require.Equal(t, failed, steps)
+		})
+	}
+
+	t.Run("decode targets", func(t *testing.T) {
+		var steps []jsptr.TraceStep
+		ptr, err := jsptr.New("/a/missing")
+		require.NoError(t, err)
+
+		var v struct{ C string }
+		err = ptr.Retrieve(&v, src, jsptr.WithTrace(func(s jsptr.TraceStep) { steps = append(steps, s) }))
+		require.ErrorIs(t, err, jsptr.ErrNotFound)
+		require.Len(t, steps, 2)
+		require.ErrorIs(t, steps[1].Err, jsptr.ErrNotFound)
+	})
+}
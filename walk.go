@@ -0,0 +1,330 @@
+package jsptr
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+// WalkFunc is the type of the function called by Walk for each value
+// visited. ptr is the canonical, escaped JSON pointer to the value
+type WalkFunc func(ptr string, v any) error
+
+// Walk traverses target and calls fn for every leaf value (strings,
+// numbers, booleans, nulls, and any other value that cannot be descended
+// into) along with the JSON pointer that refers to it. Containers
+// (objects and arrays) are also reported if the WithContainers option is
+// specified, in which case they are visited before their members.
+//
+// Objects in JSON targets are visited in document order, Go maps are
+// visited in sorted key order, and struct fields are visited in the
+// order they are declared.
+//
+// Values of JSON targets are converted the same way as retrieved values,
+// and conversion options such as WithNumberMode can be specified.
+//
+// If fn returns an error, the walk is stopped and the error is returned.
+func Walk(target any, fn WalkFunc, options ...WalkOption) error {
+	mode, cfg := walkOptions(options)
+	return walkTarget(target, fn, mode, &cfg)
+}
+
+// containerMode controls which containers are reported by walk
+type containerMode int
+
+const (
+	skipContainers containerMode = iota
+	visitContainers
+	// visitEmptyContainers reports only containers without members, which
+	// are the only containers that Flatten records
+	visitEmptyContainers
+)
+
+func walkOptions(options []WalkOption) (containerMode, retrieveConfig) {
+	mode := skipContainers
+	var cfg retrieveConfig
+	for _, option := range options {
+		switch option.Ident() {
+		case identContainers{}:
+			if option.Value().(bool) {
+				mode = visitContainers
+			} else {
+				mode = skipContainers
+			}
+		case identNumberMode{}:
+			cfg.numberMode = option.Value().(NumberMode)
+		case identOrderedObjects{}:
+			cfg.orderedObjects = option.Value().(bool)
+		}
+	}
+	return mode, cfg
+}
+
+func walkTarget(target any, fn WalkFunc, mode containerMode, cfg *retrieveConfig) error {
+	// JSON targets are traversed in their parsed form, and values are
+	// only converted to Go values when they are reported
+	switch v := target.(type) {
+	case []byte:
+		return walkJSON(v, fn, mode, cfg)
+	case string:
+		return walkJSON([]byte(v), fn, mode, cfg)
+	case *Document:
+		return walk(jsonNode{v.src.parsed}, "", fn, mode, cfg)
+	}
+	return walk(target, "", fn, mode, cfg)
+}
+
+func walkJSON(data []byte, fn WalkFunc, mode containerMode, cfg *retrieveConfig) error {
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+
+	parsed, err := parseJSON(p, data)
+	if err != nil {
+		return err
+	}
+	return walk(jsonNode{parsed}, "", fn, mode, cfg)
+}
+
+func walk(node any, ptr string, fn WalkFunc, mode containerMode, cfg *retrieveConfig) error {
+	seq, ok := members(node)
+	if !ok || mode == visitContainers || (mode == visitEmptyContainers && isEmptyNode(node)) {
+		v, err := exportNode(node, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to convert value at '%s': %w", ptr, err)
+		}
+		if err := fn(ptr, v); err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	for token, value := range seq {
+		if err := walk(value, ptr+"/"+escapeToken(token), fn, mode, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonNode wraps a parsed JSON value, so that it can be traversed
+// without first converting the entire document into Go values
+type jsonNode struct {
+	v *fastjson.Value
+}
+
+// exportNode converts node into a value that can be handed to users
+func exportNode(node any, cfg *retrieveConfig) (any, error) {
+	n, ok := node.(jsonNode)
+	if !ok {
+		return node, nil
+	}
+	var v any
+	if err := (jsonSource{}).assignFromValue(&v, n.v, cfg); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// members returns an iterator over the members of node, if node is a
+// container (an object-like or array-like value). The second return
+// value is false if node cannot be descended into
+func members(node any) (iter.Seq2[string, any], bool) {
+	switch v := node.(type) {
+	case map[string]any:
+		return func(yield func(string, any) bool) {
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				if !yield(key, v[key]) {
+					return
+				}
+			}
+		}, true
+	case []any:
+		return func(yield func(string, any) bool) {
+			for i, elem := range v {
+				if !yield(strconv.Itoa(i), elem) {
+					return
+				}
+			}
+		}, true
+	case *OrderedMap:
+		return func(yield func(string, any) bool) {
+			for _, key := range v.keys {
+				if !yield(key, v.values[key]) {
+					return
+				}
+			}
+		}, true
+	case []byte:
+		// encoding/json treats []byte as a (base64 encoded) string
+		return nil, false
+	case jsonNode:
+		return v.members()
+	}
+
+	if _, ok := asSource(node); ok {
+		return nil, false
+	}
+
+	// Values that marshal themselves are traversed through their JSON
+	// representation. Values that fail to marshal are treated as leaves
+	if v, ok, err := marshaledValue(node, defaultRetrieveConfig); err != nil {
+		return nil, false
+	} else if ok {
+		return members(v)
+	}
+
+	rv := reflect.ValueOf(node)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		return func(yield func(string, any) bool) {
+			keys := rv.MapKeys()
+			slices.SortFunc(keys, func(a, b reflect.Value) int {
+				return strings.Compare(a.String(), b.String())
+			})
+			for _, key := range keys {
+				if !yield(key.String(), rv.MapIndex(key).Interface()) {
+					return
+				}
+			}
+		}, true
+	case reflect.Slice, reflect.Array:
+		return func(yield func(string, any) bool) {
+			for i := range rv.Len() {
+				if !yield(strconv.Itoa(i), rv.Index(i).Interface()) {
+					return
+				}
+			}
+		}, true
+	case reflect.Struct:
+		return func(yield func(string, any) bool) {
+			info := getStructInfo(rv.Type(), defaultRetrieveConfig)
+			for _, name := range info.names {
+				// Fields that cannot be accessed, such as those promoted
+				// through a nil embedded pointer, are skipped
+				v, err := getField(rv, name, defaultRetrieveConfig)
+				if err != nil {
+					continue
+				}
+				if !yield(name, v) {
+					return
+				}
+			}
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// memberCount returns the number of members of node, if node is a
+// container as defined by members. Lengths are taken directly from maps,
+// slices and parsed JSON containers, without iterating over their members
+func memberCount(node any) (int, bool) {
+	switch v := node.(type) {
+	case map[string]any:
+		return len(v), true
+	case []any:
+		return len(v), true
+	case *OrderedMap:
+		return len(v.keys), true
+	case []byte:
+		return 0, false
+	case jsonNode:
+		switch v.v.Type() {
+		case fastjson.TypeObject:
+			obj, _ := v.v.Object()
+			return obj.Len(), true
+		case fastjson.TypeArray:
+			arr, _ := v.v.Array()
+			return len(arr), true
+		default:
+			return 0, false
+		}
+	}
+
+	if _, ok := asSource(node); ok {
+		return 0, false
+	}
+	if v, ok, err := marshaledValue(node, defaultRetrieveConfig); err != nil {
+		return 0, false
+	} else if ok {
+		return memberCount(v)
+	}
+
+	rv := reflect.ValueOf(node)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return 0, false
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return 0, false
+		}
+		return rv.Len(), true
+	case reflect.Slice, reflect.Array:
+		return rv.Len(), true
+	case reflect.Struct:
+		// Inaccessible fields are skipped by members, so they have to be
+		// counted one by one
+		seq, _ := members(node)
+		var count int
+		for range seq {
+			count++
+		}
+		return count, true
+	default:
+		return 0, false
+	}
+}
+
+func (n jsonNode) members() (iter.Seq2[string, any], bool) {
+	switch n.v.Type() {
+	case fastjson.TypeObject:
+		return func(yield func(string, any) bool) {
+			obj, _ := n.v.Object()
+			var keys []string
+			var values []*fastjson.Value
+			obj.Visit(func(key []byte, val *fastjson.Value) {
+				keys = append(keys, string(key))
+				values = append(values, val)
+			})
+			for i, key := range keys {
+				if !yield(key, jsonNode{values[i]}) {
+					return
+				}
+			}
+		}, true
+	case fastjson.TypeArray:
+		return func(yield func(string, any) bool) {
+			arr, _ := n.v.Array()
+			for i, elem := range arr {
+				if !yield(strconv.Itoa(i), jsonNode{elem}) {
+					return
+				}
+			}
+		}, true
+	default:
+		return nil, false
+	}
+}
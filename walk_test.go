@@ -0,0 +1,91 @@
+package jsptr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+type walkEntry struct {
+	Ptr   string
+	Value any
+}
+
+func collectWalk(t *testing.T, target any, options ...jsptr.WalkOption) []walkEntry {
+	t.Helper()
+	var entries []walkEntry
+	require.NoError(t, jsptr.Walk(target, func(ptr string, v any) error {
+		entries = append(entries, walkEntry{Ptr: ptr, Value: v})
+		return nil
+	}, options...))
+	return entries
+}
+
+func TestWalk(t *testing.T) {
+	const src = `{"b": [1, {"x~y": null}], "a/c": "s", "empty": {}}`
+
+	t.Run("JSON leaves in document order", func(t *testing.T) {
+		require.Equal(t, []walkEntry{
+			{Ptr: "/b/0", Value: 1.0},
+			{Ptr: "/b/1/x~0y", Value: nil},
+			{Ptr: "/a~1c", Value: "s"},
+		}, collectWalk(t, []byte(src)))
+	})
+	t.Run("with containers", func(t *testing.T) {
+		var ptrs []string
+		for _, entry := range collectWalk(t, src, jsptr.WithContainers(true)) {
+			ptrs = append(ptrs, entry.Ptr)
+		}
+		require.Equal(t, []string{"", "/b", "/b/0", "/b/1", "/b/1/x~0y", "/a~1c", "/empty"}, ptrs)
+	})
+	t.Run("Go values", func(t *testing.T) {
+		type Inner struct {
+			Z int `json:"z"`
+			A int `json:"a"`
+		}
+		type Outer struct {
+			Map   map[string]int `json:"map"`
+			Inner *Inner         `json:"inner"`
+			Skip  string         `json:"-"`
+			Nil   *Inner         `json:"nil"`
+		}
+
+		target := Outer{
+			Map:   map[string]int{"b": 2, "a": 1},
+			Inner: &Inner{Z: 26, A: 1},
+		}
+		require.Equal(t, []walkEntry{
+			{Ptr: "/map/a", Value: 1},
+			{Ptr: "/map/b", Value: 2},
+			{Ptr: "/inner/z", Value: 26},
+			{Ptr: "/inner/a", Value: 1},
+			{Ptr: "/nil", Value: (*Inner)(nil)},
+		}, collectWalk(t, target))
+	})
+	t.Run("scalar root", func(t *testing.T) {
+		require.Equal(t, []walkEntry{{Ptr: "", Value: 42}}, collectWalk(t, 42))
+	})
+	t.Run("stop on error", func(t *testing.T) {
+		stop := errors.New("stop")
+		var count int
+		err := jsptr.Walk([]byte(src), func(string, any) error {
+			count++
+			return stop
+		})
+		require.ErrorIs(t, err, stop)
+		require.Equal(t, 1, count)
+	})
+	t.Run("with number mode", func(t *testing.T) {
+		require.Equal(t, []walkEntry{
+			{Ptr: "/b/0", Value: json.Number("1")},
+			{Ptr: "/b/1/x~0y", Value: nil},
+			{Ptr: "/a~1c", Value: "s"},
+		}, collectWalk(t, []byte(src), jsptr.WithNumberMode(jsptr.NumberJSONNumber)))
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		require.Error(t, jsptr.Walk([]byte(`{`), func(string, any) error { return nil }))
+	})
+}
@@ -0,0 +1,43 @@
+package jsptr
+
+import (
+	"bytes"
+	"unsafe"
+
+	"github.com/valyala/fastjson"
+)
+
+// assignAliasedString assigns the string at the location specified by
+// tokens to dst without copying it, if dst is a *string and the string
+// contains no escape sequences. The resulting string shares its memory
+// with data. It reports whether the assignment was handled.
+//
+// Values that cannot be aliased, including missing ones, are left to the
+// regular retrieval, which reports errors. So are traced retrievals, so
+// that each step is only reported once
+func assignAliasedString(dst any, data []byte, tokens []string, cfg *retrieveConfig) bool {
+	sp, ok := dst.(*string)
+	if !ok || cfg.trace != nil {
+		return false
+	}
+	raw, err := locateRaw(data, tokens, cfg)
+	if err != nil || len(raw) < 2 || raw[0] != '"' || bytes.IndexByte(raw, '\\') >= 0 {
+		return false
+	}
+	*sp = unsafe.String(unsafe.SliceData(raw[1:]), len(raw)-2)
+	return true
+}
+
+// assignParsedString assigns the string value v to dst without copying
+// it, if dst is a *string. The resulting string shares its memory with the
+// parser that created v, which must not be reused. It reports whether the
+// assignment was handled
+func assignParsedString(dst any, v *fastjson.Value) bool {
+	sp, ok := dst.(*string)
+	if !ok || v.Type() != fastjson.TypeString {
+		return false
+	}
+	b := v.GetStringBytes()
+	*sp = unsafe.String(unsafe.SliceData(b), len(b))
+	return true
+}
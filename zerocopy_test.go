@@ -0,0 +1,76 @@
+package jsptr_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jsptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroCopyStrings(t *testing.T) {
+	zeroCopy := jsptr.WithZeroCopyStrings(true)
+
+	t.Run("Strings alias the document", func(t *testing.T) {
+		data := []byte(`{"name": "alice"}`)
+		var name string
+		require.NoError(t, jsptr.MustNew("/name").Retrieve(&name, data, zeroCopy))
+		require.Equal(t, "alice", name)
+
+		// Modifying the document changes the retrieved string
+		copy(data[10:], "bob  ")
+		require.Equal(t, "bob  ", name)
+	})
+	t.Run("Escaped strings are copied", func(t *testing.T) {
+		data := []byte(`{"name": "a\"b"}`)
+		var name string
+		require.NoError(t, jsptr.MustNew("/name").Retrieve(&name, data, zeroCopy))
+		require.Equal(t, `a"b`, name)
+
+		copy(data[10:], "xxxx")
+		require.Equal(t, `a"b`, name)
+	})
+	t.Run("Documents", func(t *testing.T) {
+		doc, err := jsptr.ParseJSON([]byte(`{"tags": ["", "x"]}`))
+		require.NoError(t, err)
+		var tag string
+		require.NoError(t, doc.Retrieve(&tag, "/tags/0", zeroCopy))
+		require.Equal(t, "", tag)
+		require.NoError(t, doc.Retrieve(&tag, "/tags/1", zeroCopy))
+		require.Equal(t, "x", tag)
+
+		// Strings are taken from the parsed document, which does not
+		// share memory with the bytes it was parsed from
+		data := []byte(`{"name": "alice", "quoted": "a\"b"}`)
+		doc, err = jsptr.ParseJSON(data)
+		require.NoError(t, err)
+		var name, quoted string
+		require.NoError(t, doc.Retrieve(&name, "/name", zeroCopy))
+		require.NoError(t, doc.Retrieve(&quoted, "/quoted", zeroCopy))
+		copy(data[10:], "bob  ")
+		require.Equal(t, "alice", name)
+		require.Equal(t, `a"b`, quoted)
+
+		var s string
+		copied := testing.AllocsPerRun(100, func() { _ = doc.Retrieve(&s, "/name") })
+		aliased := testing.AllocsPerRun(100, func() { _ = doc.Retrieve(&s, "/name", zeroCopy) })
+		require.Less(t, aliased, copied)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		var s string
+		err := jsptr.MustNew("/missing").Retrieve(&s, []byte(`{"name": "alice"}`), zeroCopy)
+		require.ErrorIs(t, err, jsptr.ErrNotFound)
+		require.Error(t, jsptr.MustNew("/id").Retrieve(&s, []byte(`{"id": 1}`), zeroCopy))
+		require.Error(t, jsptr.MustNew("/name").Retrieve(&s, []byte(`{"name": "alice"`), zeroCopy))
+	})
+	t.Run("Allocations", func(t *testing.T) {
+		ptr := jsptr.MustNew("/name")
+		data := []byte(`{"name": "a string that is long enough to be allocated"}`)
+		copyOptions := []jsptr.RetrieveOption{jsptr.WithZeroCopyStrings(false)}
+		aliasOptions := []jsptr.RetrieveOption{zeroCopy}
+
+		var s string
+		copied := testing.AllocsPerRun(100, func() { _ = ptr.Retrieve(&s, data, copyOptions...) })
+		aliased := testing.AllocsPerRun(100, func() { _ = ptr.Retrieve(&s, data, aliasOptions...) })
+		require.Less(t, aliased, copied)
+	})
+}